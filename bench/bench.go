@@ -0,0 +1,223 @@
+// Package bench generates reproducible synthetic embeddings and query workloads, and measures
+// a VectorStore's search latency and recall against them, to compare storage types,
+// transforms, sharding or quotas on the same data. Vectors are drawn around random cluster
+// centres, like real embeddings of a handful of topics, and the same Config always generates
+// the same Dataset.
+package bench
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"strconv"
+	"time"
+
+	serverlessVector "github.com/takara-ai/serverlessVector/v2"
+)
+
+// Config describes a Dataset. Zero values use defaults.
+type Config struct {
+	Vectors   int     // Number of vectors. Default 10000.
+	Dimension int     // Default 128.
+	Clusters  int     // Number of cluster centres. Default 16.
+	Spread    float64 // Expected distance of a vector from its unit-length centre. Default 0.5.
+	Seed      uint64
+}
+
+// Dataset is a generated set of vectors with their IDs and clusters.
+type Dataset struct {
+	IDs      []string
+	Vectors  [][]float32
+	Clusters []int       // Index into Centers of each vector's cluster.
+	Centers  [][]float32 // Unit-length cluster centres.
+	spread   float64
+}
+
+// Generate returns the Dataset cfg describes.
+func Generate(cfg Config) *Dataset {
+	if cfg.Vectors <= 0 {
+		cfg.Vectors = 10000
+	}
+	if cfg.Dimension <= 0 {
+		cfg.Dimension = 128
+	}
+	if cfg.Clusters <= 0 {
+		cfg.Clusters = 16
+	}
+	if cfg.Spread <= 0 {
+		cfg.Spread = 0.5
+	}
+	rng := rand.New(rand.NewPCG(cfg.Seed, 0))
+	d := &Dataset{
+		IDs:      make([]string, cfg.Vectors),
+		Vectors:  make([][]float32, cfg.Vectors),
+		Clusters: make([]int, cfg.Vectors),
+		Centers:  make([][]float32, cfg.Clusters),
+		spread:   cfg.Spread,
+	}
+	for c := range d.Centers {
+		d.Centers[c] = gaussian(rng, cfg.Dimension, 1)
+		var norm float64
+		for _, x := range d.Centers[c] {
+			norm += float64(x) * float64(x)
+		}
+		for i := range d.Centers[c] {
+			d.Centers[c][i] /= float32(math.Sqrt(norm))
+		}
+	}
+	for i := range d.Vectors {
+		c := rng.IntN(cfg.Clusters)
+		d.IDs[i] = "v" + strconv.Itoa(i)
+		d.Clusters[i] = c
+		d.Vectors[i] = d.near(rng, c)
+	}
+	return d
+}
+
+// Dimension returns the length of the vectors.
+func (d *Dataset) Dimension() int { return len(d.Centers[0]) }
+
+// Load adds every vector to store, tagged with its cluster under "cluster" for filtered
+// workloads.
+func (d *Dataset) Load(store serverlessVector.VectorStore) error {
+	for i, id := range d.IDs {
+		meta := serverlessVector.VectorMetadata{Tags: map[string]string{"cluster": strconv.Itoa(d.Clusters[i])}}
+		if err := store.Add(id, d.Vectors[i], meta); err != nil {
+			return fmt.Errorf("vector %s: %w", id, err)
+		}
+	}
+	return nil
+}
+
+// Queries returns n query vectors drawn like the dataset's own vectors but not among them,
+// the same for the same seed.
+func (d *Dataset) Queries(n int, seed uint64) [][]float32 {
+	rng := rand.New(rand.NewPCG(seed, 1))
+	queries := make([][]float32, n)
+	for i := range queries {
+		queries[i] = d.near(rng, rng.IntN(len(d.Centers)))
+	}
+	return queries
+}
+
+// GroundTruth returns the IDs of the exact k nearest vectors to each query by dist, best
+// first, to measure the recall of a store built with lossy options against.
+func (d *Dataset) GroundTruth(queries [][]float32, k int, dist serverlessVector.DistanceFunction) ([][]string, error) {
+	exact := serverlessVector.NewVectorDB(d.Dimension(), dist)
+	if err := d.Load(exact); err != nil {
+		return nil, err
+	}
+	truth := make([][]string, len(queries))
+	for i, q := range queries {
+		res, err := exact.Search(q, k)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range res.Results {
+			truth[i] = append(truth[i], r.ID)
+		}
+	}
+	return truth, nil
+}
+
+func (d *Dataset) near(rng *rand.Rand, c int) []float32 {
+	v := gaussian(rng, len(d.Centers[c]), d.spread/math.Sqrt(float64(len(d.Centers[c]))))
+	for i, x := range d.Centers[c] {
+		v[i] += x
+	}
+	return v
+}
+
+func gaussian(rng *rand.Rand, n int, stddev float64) []float32 {
+	v := make([]float32, n)
+	for i := range v {
+		v[i] = float32(rng.NormFloat64() * stddev)
+	}
+	return v
+}
+
+// Report is the outcome of Run.
+type Report struct {
+	Queries int
+	K       int
+	Total   time.Duration // Wall time of all searches, run one after another.
+	QPS     float64
+	P50     time.Duration
+	P95     time.Duration
+	P99     time.Duration
+	Max     time.Duration
+	Recall  float64 // Mean share of each query's true k nearest returned; 0 without ground truth.
+}
+
+// String formats r on one line.
+func (r *Report) String() string {
+	s := fmt.Sprintf("%d queries k=%d: %.0f QPS, p50 %v, p95 %v, p99 %v, max %v",
+		r.Queries, r.K, r.QPS, r.P50, r.P95, r.P99, r.Max)
+	if r.Recall > 0 {
+		s += fmt.Sprintf(", recall@%d %.4f", r.K, r.Recall)
+	}
+	return s
+}
+
+// Run searches store for each query's top k, one after another, and reports the latency
+// distribution. With truth from GroundTruth (nil to skip) it also reports the recall.
+func Run(store serverlessVector.VectorStore, queries [][]float32, k int, truth [][]string) (*Report, error) {
+	if len(queries) == 0 {
+		return nil, errors.New("no queries to run")
+	}
+	if truth != nil && len(truth) != len(queries) {
+		return nil, fmt.Errorf("%d ground truth lists for %d queries", len(truth), len(queries))
+	}
+	latencies := make([]time.Duration, len(queries))
+	var recall float64
+	start := time.Now()
+	for i, q := range queries {
+		t := time.Now()
+		res, err := store.Search(q, k)
+		latencies[i] = time.Since(t)
+		if err != nil {
+			return nil, fmt.Errorf("query %d: %w", i, err)
+		}
+		if truth != nil {
+			recall += Recall(res.Results, truth[i])
+		}
+	}
+	total := time.Since(start)
+	slices.Sort(latencies)
+	r := &Report{
+		Queries: len(queries),
+		K:       k,
+		Total:   total,
+		QPS:     float64(len(queries)) / total.Seconds(),
+		P50:     percentile(latencies, 0.50),
+		P95:     percentile(latencies, 0.95),
+		P99:     percentile(latencies, 0.99),
+		Max:     latencies[len(latencies)-1],
+	}
+	if truth != nil {
+		r.Recall = recall / float64(len(queries))
+	}
+	return r, nil
+}
+
+// Recall returns the share of want found in got, 1 when want is empty.
+func Recall(got []serverlessVector.SimilarityResult, want []string) float64 {
+	if len(want) == 0 {
+		return 1
+	}
+	found := 0
+	for _, r := range got {
+		if slices.Contains(want, r.ID) {
+			found++
+		}
+	}
+	return float64(found) / float64(len(want))
+}
+
+// percentile returns the p-quantile of the sorted latencies, by nearest rank.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	i := int(math.Ceil(p*float64(len(sorted)))) - 1
+	return sorted[max(i, 0)]
+}
@@ -0,0 +1,84 @@
+package bench
+
+import (
+	"slices"
+	"testing"
+
+	serverlessVector "github.com/takara-ai/serverlessVector/v2"
+)
+
+func TestGenerate(t *testing.T) {
+	cfg := Config{Vectors: 200, Dimension: 16, Clusters: 4, Seed: 7}
+	a, b := Generate(cfg), Generate(cfg)
+	if len(a.Vectors) != 200 || a.Dimension() != 16 || len(a.Centers) != 4 {
+		t.Fatalf("unexpected dataset shape: %d vectors of %d in %d clusters", len(a.Vectors), a.Dimension(), len(a.Centers))
+	}
+	for i := range a.Vectors {
+		if a.IDs[i] != b.IDs[i] || a.Clusters[i] != b.Clusters[i] || !slices.Equal(a.Vectors[i], b.Vectors[i]) {
+			t.Fatalf("the same config must generate the same dataset; vector %d differs", i)
+		}
+	}
+	if c := Generate(Config{Vectors: 200, Dimension: 16, Clusters: 4, Seed: 8}); slices.Equal(a.Vectors[0], c.Vectors[0]) {
+		t.Error("a different seed must generate different vectors")
+	}
+
+	// Vectors cluster: the nearest centre is nearly always their own.
+	wrong := 0
+	for i, v := range a.Vectors {
+		best, bestDist := -1, 0.0
+		for c, centre := range a.Centers {
+			var d float64
+			for j := range v {
+				d += float64(v[j]-centre[j]) * float64(v[j]-centre[j])
+			}
+			if best < 0 || d < bestDist {
+				best, bestDist = c, d
+			}
+		}
+		if best != a.Clusters[i] {
+			wrong++
+		}
+	}
+	if wrong > 5 {
+		t.Errorf("%d of 200 vectors are nearer another cluster's centre", wrong)
+	}
+}
+
+func TestRun(t *testing.T) {
+	d := Generate(Config{Vectors: 300, Dimension: 32, Clusters: 5, Seed: 1})
+	queries := d.Queries(20, 2)
+	if !slices.Equal(queries[3], d.Queries(20, 2)[3]) {
+		t.Error("the same seed must draw the same queries")
+	}
+	truth, err := d.GroundTruth(queries, 10, serverlessVector.CosineSimilarity)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exact := serverlessVector.NewVectorDB(32, serverlessVector.CosineSimilarity)
+	if err := d.Load(exact); err != nil {
+		t.Fatal(err)
+	}
+	r, err := Run(exact, queries, 10, truth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Recall != 1 || r.Queries != 20 || r.P50 > r.P99 || r.P99 > r.Max || r.QPS <= 0 {
+		t.Errorf("unexpected report %s", r)
+	}
+	if v, _ := exact.Get(d.IDs[0]); v.Metadata.Tags["cluster"] == "" {
+		t.Error("Load must tag vectors with their cluster")
+	}
+
+	// A store that misses a neighbour loses recall.
+	small := serverlessVector.NewVectorDB(32, serverlessVector.CosineSimilarity)
+	_ = d.Load(small)
+	_ = small.Delete(truth[0][0])
+	r, _ = Run(small, queries, 10, truth)
+	if r.Recall >= 1 || r.Recall < 0.9 {
+		t.Errorf("expected recall just under 1, got %v", r.Recall)
+	}
+	if _, err := Run(exact, nil, 10, nil); err == nil {
+		t.Error("expected an error without queries")
+	}
+}
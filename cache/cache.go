@@ -0,0 +1,170 @@
+// Package cache provides a semantic cache: responses (typically LLM completions) keyed by
+// the embedding of the request, returned for any later request whose embedding is similar enough.
+package cache
+
+import (
+	"container/list"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	serverlessVector "github.com/takara-ai/serverlessVector/v2"
+)
+
+// DefaultThreshold is the cosine similarity a lookup must reach when Options.Threshold is zero.
+const DefaultThreshold = 0.95
+
+// Options configures a SemanticCache. Nil or zero values use defaults.
+type Options struct {
+	Threshold  float64       // Minimum cosine similarity for a hit. Default DefaultThreshold.
+	TTL        time.Duration // Entry lifetime; 0 never expires.
+	MaxEntries int           // Evict the oldest entry beyond this size; 0 is unbounded.
+}
+
+// SemanticCache maps query embeddings to responses of type V. It is safe for concurrent use.
+type SemanticCache[V any] struct {
+	db        *serverlessVector.VectorDB
+	threshold float64
+	ttl       time.Duration
+	max       int
+	now       func() time.Time
+
+	mu      sync.RWMutex
+	entries map[string]*list.Element // id -> element holding *entry[V]
+	order   *list.List               // insertion order, oldest at front
+	seq     uint64
+}
+
+type entry[V any] struct {
+	id       string
+	response V
+	expires  time.Time // zero when TTL is 0
+}
+
+// Hit is a successful lookup.
+type Hit[V any] struct {
+	Response   V
+	Similarity float64 // Cosine similarity between the lookup and the cached embedding.
+}
+
+// New returns an empty cache for embeddings of the given dimension (0 to take it from the first Set).
+func New[V any](dimension int, opts *Options) *SemanticCache[V] {
+	c := &SemanticCache[V]{
+		db:        serverlessVector.NewVectorDB(dimension, serverlessVector.CosineSimilarity),
+		threshold: DefaultThreshold,
+		now:       time.Now,
+		entries:   make(map[string]*list.Element),
+		order:     list.New(),
+	}
+	if opts != nil {
+		if opts.Threshold > 0 {
+			c.threshold = opts.Threshold
+		}
+		c.ttl = opts.TTL
+		c.max = opts.MaxEntries
+	}
+	return c
+}
+
+// Set caches response under embedding and returns the entry ID.
+func (c *SemanticCache[V]) Set(embedding []float32, response V) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.seq++
+	id := strconv.FormatUint(c.seq, 10)
+	if err := c.db.Add(id, embedding); err != nil {
+		return "", err
+	}
+	e := &entry[V]{id: id, response: response}
+	if c.ttl > 0 {
+		e.expires = c.now().Add(c.ttl)
+	}
+	c.entries[id] = c.order.PushBack(e)
+	if c.max > 0 && len(c.entries) > c.max {
+		c.purgeLocked()
+		for len(c.entries) > c.max {
+			c.removeLocked(c.order.Front())
+		}
+	}
+	return id, nil
+}
+
+// Get returns the most similar unexpired response whose similarity reaches the threshold.
+func (c *SemanticCache[V]) Get(embedding []float32) (Hit[V], bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if len(c.entries) == 0 {
+		return Hit[V]{}, false
+	}
+	now := c.now()
+	res, err := c.db.SearchWithFilter(embedding, 1, func(v *serverlessVector.Vector) bool {
+		el, ok := c.entries[v.ID]
+		return ok && !el.Value.(*entry[V]).expired(now)
+	})
+	if err != nil || len(res.Results) == 0 || res.Results[0].Score < c.threshold {
+		return Hit[V]{}, false
+	}
+	e := c.entries[res.Results[0].ID].Value.(*entry[V])
+	return Hit[V]{Response: e.response, Similarity: res.Results[0].Score}, true
+}
+
+// Delete removes the entry with the given ID.
+func (c *SemanticCache[V]) Delete(id string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.entries[id]
+	if !ok {
+		return errors.New("cache entry not found")
+	}
+	c.removeLocked(el)
+	return nil
+}
+
+// Purge removes expired entries and returns how many were removed.
+// Expired entries are never returned by Get, so calling Purge only reclaims memory.
+func (c *SemanticCache[V]) Purge() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.purgeLocked()
+}
+
+// Len returns the number of entries, including expired ones not yet purged.
+func (c *SemanticCache[V]) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.entries)
+}
+
+// Clear removes all entries.
+func (c *SemanticCache[V]) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.db.Clear()
+	clear(c.entries)
+	c.order.Init()
+}
+
+func (c *SemanticCache[V]) purgeLocked() int {
+	if c.ttl <= 0 {
+		return 0
+	}
+	now := c.now()
+	n := 0
+	// Entries share one TTL, so insertion order is expiry order.
+	for el := c.order.Front(); el != nil && el.Value.(*entry[V]).expired(now); el = c.order.Front() {
+		c.removeLocked(el)
+		n++
+	}
+	return n
+}
+
+func (c *SemanticCache[V]) removeLocked(el *list.Element) {
+	e := c.order.Remove(el).(*entry[V])
+	delete(c.entries, e.id)
+	_ = c.db.Delete(e.id)
+}
+
+func (e *entry[V]) expired(now time.Time) bool {
+	return !e.expires.IsZero() && !now.Before(e.expires)
+}
@@ -0,0 +1,69 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSemanticCache_Threshold(t *testing.T) {
+	c := New[string](3, &Options{Threshold: 0.9})
+	if _, err := c.Set([]float32{1, 0, 0}, "x-axis"); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	hit, ok := c.Get([]float32{0.99, 0.1, 0})
+	if !ok || hit.Response != "x-axis" || hit.Similarity < 0.9 {
+		t.Errorf("expected hit for similar query, got %+v %v", hit, ok)
+	}
+	if _, ok := c.Get([]float32{0, 1, 0}); ok {
+		t.Error("expected miss for dissimilar query")
+	}
+	if _, err := c.Set([]float32{1, 0}, "bad"); err == nil {
+		t.Error("expected dimension mismatch to fail")
+	}
+}
+
+func TestSemanticCache_TTL(t *testing.T) {
+	now := time.Unix(1000, 0)
+	c := New[int](2, &Options{TTL: time.Minute})
+	c.now = func() time.Time { return now }
+	_, _ = c.Set([]float32{1, 0}, 1)
+	now = now.Add(30 * time.Second)
+	_, _ = c.Set([]float32{0, 1}, 2)
+
+	now = now.Add(45 * time.Second) // first entry expired, second still live
+	if _, ok := c.Get([]float32{1, 0}); ok {
+		t.Error("expired entry must not be returned")
+	}
+	if hit, ok := c.Get([]float32{0, 1}); !ok || hit.Response != 2 {
+		t.Errorf("expected live entry, got %+v %v", hit, ok)
+	}
+	if n := c.Purge(); n != 1 || c.Len() != 1 {
+		t.Errorf("Purge removed %d, Len %d; want 1, 1", n, c.Len())
+	}
+}
+
+func TestSemanticCache_MaxEntriesAndDelete(t *testing.T) {
+	c := New[string](2, &Options{MaxEntries: 2})
+	_, _ = c.Set([]float32{1, 0}, "a")
+	id, _ := c.Set([]float32{0, 1}, "b")
+	_, _ = c.Set([]float32{-1, 0}, "c")
+	if c.Len() != 2 {
+		t.Fatalf("Len = %d, want 2", c.Len())
+	}
+	if _, ok := c.Get([]float32{1, 0}); ok {
+		t.Error("oldest entry should have been evicted")
+	}
+	if err := c.Delete(id); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, ok := c.Get([]float32{0, 1}); ok {
+		t.Error("deleted entry must not be returned")
+	}
+	if err := c.Delete(id); err == nil {
+		t.Error("expected deleting a missing entry to fail")
+	}
+	c.Clear()
+	if c.Len() != 0 {
+		t.Errorf("Len after Clear = %d", c.Len())
+	}
+}
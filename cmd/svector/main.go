@@ -0,0 +1,262 @@
+// Command svector inspects and converts serverlessVector snapshots, e.g. dumps pulled from
+// production:
+//
+//	svector convert [-distance name] in out      convert between formats (by file extension)
+//	svector search [-k n] [-tags k=v,...] [-id id] snapshot [query]
+//	svector stats snapshot
+//
+// Snapshots are Parquet files as written by SaveSnapshot and ExportParquet; importing a dump
+// is converting it to .parquet and exporting one is converting from it. Files ending in
+// .parquet, .jsonl and .csv (the BulkLoad formats) can be read and written; flat FAISS
+// indexes (.faiss, labels become IDs) can only be read.
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	serverlessVector "github.com/takara-ai/serverlessVector/v2"
+)
+
+const usage = `usage:
+  svector convert [-distance name] in out
+  svector search [-distance name] [-k n] [-tags k=v,...] [-id id] snapshot [query]
+  svector stats [-distance name] snapshot
+
+Formats follow the file extension: .parquet, .jsonl and .csv are read and written;
+.faiss is read only. A query is comma-separated numbers; -id searches with
+a stored vector instead.
+`
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run executes the command in args and returns the process exit code.
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+	fs := flag.NewFlagSet("svector "+args[0], flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	distance := fs.String("distance", "cosine_similarity", "distance function, as printed by stats")
+	k := fs.Int("k", 10, "number of results (search)")
+	tags := fs.String("tags", "", "exact tag matches k=v,... (search)")
+	id := fs.String("id", "", "search with this stored vector, excluding it from the results (search)")
+	if err := fs.Parse(args[1:]); err != nil {
+		return 2
+	}
+	df, err := parseDistance(*distance)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+
+	searchArgs := 2 // snapshot and query
+	if *id != "" {
+		searchArgs = 1
+	}
+	switch cmd, rest := args[0], fs.Args(); {
+	case cmd == "convert" && len(rest) == 2:
+		err = convert(rest[0], rest[1], df)
+	case cmd == "search" && len(rest) == searchArgs:
+		err = search(stdout, rest, df, *k, *tags, *id)
+	case cmd == "stats" && len(rest) == 1:
+		err = stats(stdout, rest[0], df)
+	default:
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, "svector:", err)
+		return 1
+	}
+	return 0
+}
+
+// parseDistance returns the DistanceFunction whose String is name.
+func parseDistance(name string) (serverlessVector.DistanceFunction, error) {
+	for df := serverlessVector.CosineSimilarity; df <= serverlessVector.MahalanobisDistance; df++ {
+		if df.String() == name {
+			return df, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown distance %q", name)
+}
+
+func convert(in, out string, df serverlessVector.DistanceFunction) error {
+	db, err := load(in, df)
+	if err != nil {
+		return err
+	}
+	return save(db, out)
+}
+
+func search(w io.Writer, args []string, df serverlessVector.DistanceFunction, k int, tags, id string) error {
+	db, err := load(args[0], df)
+	if err != nil {
+		return err
+	}
+	opts := &serverlessVector.SearchOptions{}
+	if tags != "" {
+		opts.Tags = map[string]string{}
+		for _, kv := range strings.Split(tags, ",") {
+			key, value, ok := strings.Cut(kv, "=")
+			if !ok {
+				return fmt.Errorf("tag %q: expected key=value", kv)
+			}
+			opts.Tags[key] = value
+		}
+	}
+	var query []float32
+	if id != "" {
+		v, err := db.Get(id)
+		if err != nil {
+			return err
+		}
+		query, opts.ExcludeIDs = v.Data, []string{id}
+	} else if query, err = parseFloats(strings.Split(args[1], ",")); err != nil {
+		return fmt.Errorf("query: %w", err)
+	}
+	res, err := db.SearchWithOptions(query, k, opts)
+	if err != nil {
+		return err
+	}
+	bw := bufio.NewWriter(w)
+	for _, r := range res.Results {
+		fmt.Fprintf(bw, "%g\t%s\n", r.Score, r.ID)
+	}
+	return bw.Flush()
+}
+
+func stats(w io.Writer, path string, df serverlessVector.DistanceFunction) error {
+	db, err := load(path, df)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(db.GetStats())
+}
+
+// load reads the file at path into a new DB scored with df.
+func load(path string, df serverlessVector.DistanceFunction) (*serverlessVector.VectorDB, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	db := serverlessVector.NewVectorDB(0, df)
+	switch ext := filepath.Ext(path); ext {
+	case ".parquet":
+		var info os.FileInfo
+		if info, err = f.Stat(); err == nil {
+			err = db.ImportParquet(f, info.Size(), nil)
+		}
+	case ".jsonl":
+		err = db.BulkLoad(context.Background(), f, serverlessVector.BulkJSONL, nil)
+	case ".csv":
+		err = db.BulkLoad(context.Background(), f, serverlessVector.BulkCSV, nil)
+	case ".faiss":
+		err = db.ImportFAISS(bufio.NewReader(f), nil)
+	default:
+		return nil, fmt.Errorf("%s: unknown format %q", path, ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return db, nil
+}
+
+// save writes db to path in the format of its extension, replacing the file only once the
+// whole output has been written.
+func save(db *serverlessVector.VectorDB, path string) error {
+	var buf bytes.Buffer
+	var err error
+	switch ext := filepath.Ext(path); ext {
+	case ".parquet":
+		err = db.ExportParquet(&buf, nil)
+	case ".jsonl":
+		err = writeJSONL(&buf, db)
+	case ".csv":
+		err = writeCSV(&buf, db)
+	default:
+		return fmt.Errorf("%s: cannot write format %q", path, ext)
+	}
+	if err != nil {
+		return err
+	}
+	return serverlessVector.FileStorage(path).WriteSnapshot(context.Background(), buf.Bytes())
+}
+
+// writeJSONL writes every vector as a BulkJSONL line.
+func writeJSONL(w io.Writer, db *serverlessVector.VectorDB) error {
+	enc := json.NewEncoder(w)
+	for _, id := range db.IDs() {
+		v, err := db.Get(id)
+		if err != nil {
+			continue // deleted meanwhile
+		}
+		err = enc.Encode(struct {
+			ID         string            `json:"id"`
+			Vector     []float32         `json:"vector"`
+			Tags       map[string]string `json:"tags,omitempty"`
+			Attributes map[string]any    `json:"attributes,omitempty"`
+		}{v.ID, v.Data, v.Metadata.Tags, v.Metadata.Attributes})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeCSV writes every vector as a BulkCSV row after a header row. Metadata is dropped.
+func writeCSV(w io.Writer, db *serverlessVector.VectorDB) error {
+	cw := csv.NewWriter(w)
+	header := []string{"id"}
+	for i := range db.Dimension() {
+		header = append(header, "v"+strconv.Itoa(i))
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for _, id := range db.IDs() {
+		v, err := db.Get(id)
+		if err != nil {
+			continue
+		}
+		row := []string{v.ID}
+		for _, x := range v.Data {
+			row = append(row, strconv.FormatFloat(float64(x), 'g', -1, 32))
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func parseFloats(fields []string) ([]float32, error) {
+	out := make([]float32, len(fields))
+	for i, f := range fields {
+		x, err := strconv.ParseFloat(strings.TrimSpace(f), 32)
+		if err != nil {
+			return nil, errors.New("expected comma-separated numbers")
+		}
+		out[i] = float32(x)
+	}
+	return out, nil
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	jsonl := filepath.Join(dir, "dump.jsonl")
+	err := os.WriteFile(jsonl, []byte(`{"id": "a", "vector": [1, 0], "tags": {"lang": "en"}}
+{"id": "b", "vector": [0.9, 0.1], "tags": {"lang": "fr"}}
+{"id": "c", "vector": [0, 1], "tags": {"lang": "en"}}
+`), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	svector := func(args ...string) (string, int) {
+		var stdout, stderr bytes.Buffer
+		code := run(args, &stdout, &stderr)
+		return stdout.String() + stderr.String(), code
+	}
+
+	snapshot := filepath.Join(dir, "db.parquet")
+	if out, code := svector("convert", jsonl, snapshot); code != 0 {
+		t.Fatalf("convert to parquet: %d %s", code, out)
+	}
+	csvPath := filepath.Join(dir, "db.csv")
+	if out, code := svector("convert", snapshot, csvPath); code != 0 {
+		t.Fatalf("convert to csv: %d %s", code, out)
+	}
+	if out, code := svector("stats", csvPath); code != 0 || !strings.Contains(out, `"total_vectors": 3`) {
+		t.Errorf("stats of the csv: %d %s", code, out)
+	}
+
+	out, code := svector("search", "-k", "2", snapshot, "1,0")
+	if code != 0 || !strings.HasPrefix(out, "1\ta\n") || !strings.Contains(out, "\tb\n") {
+		t.Errorf("search: %d %q", code, out)
+	}
+	out, code = svector("search", "-id", "a", "-tags", "lang=en", snapshot)
+	if code != 0 || strings.TrimSpace(out) != "0\tc" {
+		t.Errorf("search by ID with tags: %d %q", code, out)
+	}
+
+	if _, code := svector("search", snapshot); code != 2 {
+		t.Errorf("a search without a query must print usage, got %d", code)
+	}
+	if out, code := svector("stats", filepath.Join(dir, "missing.parquet")); code != 1 || !strings.Contains(out, "svector:") {
+		t.Errorf("a missing file must fail: %d %s", code, out)
+	}
+	if _, code := svector("stats", "-distance", "nope", snapshot); code != 2 {
+		t.Errorf("an unknown distance must print usage, got %d", code)
+	}
+}
@@ -0,0 +1,121 @@
+// Package cohere implements serverlessVector.QueryEmbedder with the Cohere v2 embed API.
+// Documents are embedded with input_type "search_document" and queries with "search_query".
+package cohere
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/takara-ai/serverlessVector/v2/embedders/internal/httpjson"
+)
+
+// DefaultModel is used when Client.Model is empty.
+const DefaultModel = "embed-english-v3.0"
+
+// DefaultBaseURL is used when Client.BaseURL is empty.
+const DefaultBaseURL = "https://api.cohere.com/v2"
+
+// Input types understood by the API.
+const (
+	InputSearchDocument = "search_document"
+	InputSearchQuery    = "search_query"
+	InputClassification = "classification"
+	InputClustering     = "clustering"
+)
+
+// maxBatch is the API's limit on texts per request.
+const maxBatch = 96
+
+// Client calls POST /embed. The zero value needs only APIKey.
+type Client struct {
+	APIKey          string
+	Model           string        // Default DefaultModel.
+	InputType       string        // Input type for Embed; default InputSearchDocument. EmbedQuery always uses InputSearchQuery.
+	OutputDimension int           // embed-v4.0 and later only; 0 keeps the model default.
+	BaseURL         string        // Default DefaultBaseURL.
+	BatchSize       int           // Texts per request, at most 96 (default).
+	MaxRetries      int           // Retries on 429/5xx; default 3, negative disables.
+	MinBackoff      time.Duration // First retry delay, doubled per attempt; default 500ms.
+	HTTPClient      *http.Client  // Default http.DefaultClient.
+}
+
+// New returns a Client for apiKey using DefaultModel.
+func New(apiKey string) *Client {
+	return &Client{APIKey: apiKey}
+}
+
+type embedRequest struct {
+	Model           string   `json:"model"`
+	Texts           []string `json:"texts"`
+	InputType       string   `json:"input_type"`
+	EmbeddingTypes  []string `json:"embedding_types"`
+	OutputDimension int      `json:"output_dimension,omitempty"`
+}
+
+type embedResponse struct {
+	Embeddings struct {
+		Float [][]float32 `json:"float"`
+	} `json:"embeddings"`
+}
+
+// Embed embeds documents (InputType, default search_document).
+func (c *Client) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	inputType := c.InputType
+	if inputType == "" {
+		inputType = InputSearchDocument
+	}
+	return c.embed(ctx, texts, inputType)
+}
+
+// EmbedQuery embeds search queries with input_type search_query.
+func (c *Client) EmbedQuery(ctx context.Context, queries []string) ([][]float32, error) {
+	return c.embed(ctx, queries, InputSearchQuery)
+}
+
+func (c *Client) embed(ctx context.Context, texts []string, inputType string) ([][]float32, error) {
+	if c.APIKey == "" {
+		return nil, errors.New("cohere: API key is required")
+	}
+	model, baseURL := c.Model, c.BaseURL
+	if model == "" {
+		model = DefaultModel
+	}
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	batch := c.BatchSize
+	if batch <= 0 || batch > maxBatch {
+		batch = maxBatch
+	}
+	retries, backoff := httpjson.RetryPolicy(c.MaxRetries, c.MinBackoff)
+
+	out := make([][]float32, 0, len(texts))
+	for start := 0; start < len(texts); start += batch {
+		end := min(start+batch, len(texts))
+		var resp embedResponse
+		err := httpjson.Post(ctx, c.HTTPClient, httpjson.Request{
+			URL:     baseURL + "/embed",
+			Headers: map[string]string{"Authorization": "Bearer " + c.APIKey},
+			Body: embedRequest{
+				Model:           model,
+				Texts:           texts[start:end],
+				InputType:       inputType,
+				EmbeddingTypes:  []string{"float"},
+				OutputDimension: c.OutputDimension,
+			},
+			MaxRetries: retries,
+			MinBackoff: backoff,
+		}, &resp)
+		if err != nil {
+			return nil, fmt.Errorf("cohere: %w", err)
+		}
+		if len(resp.Embeddings.Float) != end-start {
+			return nil, fmt.Errorf("cohere: got %d embeddings for %d texts", len(resp.Embeddings.Float), end-start)
+		}
+		out = append(out, resp.Embeddings.Float...)
+	}
+	return out, nil
+}
@@ -0,0 +1,71 @@
+package cohere
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	serverlessVector "github.com/takara-ai/serverlessVector/v2"
+)
+
+var _ serverlessVector.QueryEmbedder = (*Client)(nil)
+
+// fakeAPI records requests and returns a 2-D embedding per text.
+func fakeAPI(t *testing.T, reqs *[]embedRequest) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/embed" || r.Header.Get("Authorization") != "Bearer key" {
+			t.Errorf("unexpected request %s", r.URL.Path)
+		}
+		var req embedRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatal(err)
+		}
+		*reqs = append(*reqs, req)
+		var resp embedResponse
+		for _, text := range req.Texts {
+			resp.Embeddings.Float = append(resp.Embeddings.Float, []float32{float32(len(text)), 1})
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+}
+
+func TestEmbed_InputTypes(t *testing.T) {
+	var reqs []embedRequest
+	srv := fakeAPI(t, &reqs)
+	defer srv.Close()
+	c := &Client{APIKey: "key", BaseURL: srv.URL, MinBackoff: time.Millisecond}
+
+	docs, err := c.Embed(context.Background(), []string{"a", "bb"})
+	if err != nil {
+		t.Fatalf("Embed failed: %v", err)
+	}
+	if _, err := c.EmbedQuery(context.Background(), []string{"q"}); err != nil {
+		t.Fatalf("EmbedQuery failed: %v", err)
+	}
+	if reqs[0].InputType != InputSearchDocument || reqs[1].InputType != InputSearchQuery {
+		t.Errorf("unexpected input types: %q, %q", reqs[0].InputType, reqs[1].InputType)
+	}
+	if reqs[0].Model != DefaultModel || len(reqs[0].EmbeddingTypes) != 1 || reqs[0].EmbeddingTypes[0] != "float" {
+		t.Errorf("unexpected request: %+v", reqs[0])
+	}
+	if docs[1][0] != 2 {
+		t.Errorf("unexpected embeddings: %v", docs)
+	}
+}
+
+func TestEmbed_Batches(t *testing.T) {
+	var reqs []embedRequest
+	srv := fakeAPI(t, &reqs)
+	defer srv.Close()
+	c := &Client{APIKey: "key", BaseURL: srv.URL, BatchSize: 2, InputType: InputClustering}
+	out, err := c.Embed(context.Background(), []string{"a", "b", "c"})
+	if err != nil {
+		t.Fatalf("Embed failed: %v", err)
+	}
+	if len(reqs) != 2 || len(out) != 3 || reqs[1].InputType != InputClustering {
+		t.Errorf("expected 2 clustering requests and 3 embeddings, got %d/%d", len(reqs), len(out))
+	}
+}
@@ -0,0 +1,96 @@
+// Package httpjson posts JSON to embedding APIs with retry and exponential backoff.
+package httpjson
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Request describes one JSON POST.
+type Request struct {
+	URL        string
+	Headers    map[string]string
+	Body       any
+	MaxRetries int           // Retries after the first attempt for 429 and 5xx responses.
+	MinBackoff time.Duration // First retry delay; doubles per attempt. Retry-After wins when set.
+}
+
+// StatusError is returned for a non-2xx response that was not (or no longer) retried.
+type StatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("HTTP %d: %s", e.StatusCode, e.Body)
+}
+
+// RetryPolicy applies the shared client defaults: 3 retries (negative disables)
+// starting at 500ms.
+func RetryPolicy(maxRetries int, minBackoff time.Duration) (int, time.Duration) {
+	if maxRetries == 0 {
+		maxRetries = 3
+	} else if maxRetries < 0 {
+		maxRetries = 0
+	}
+	if minBackoff <= 0 {
+		minBackoff = 500 * time.Millisecond
+	}
+	return maxRetries, minBackoff
+}
+
+// Post sends req and decodes a 2xx JSON response into out.
+func Post(ctx context.Context, client *http.Client, req Request, out any) error {
+	payload, err := json.Marshal(req.Body)
+	if err != nil {
+		return err
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+	backoff := req.MinBackoff
+	for attempt := 0; ; attempt++ {
+		hr, err := http.NewRequestWithContext(ctx, http.MethodPost, req.URL, bytes.NewReader(payload))
+		if err != nil {
+			return err
+		}
+		hr.Header.Set("Content-Type", "application/json")
+		for k, v := range req.Headers {
+			hr.Header.Set(k, v)
+		}
+		resp, err := client.Do(hr)
+		if err != nil {
+			if ctx.Err() != nil || attempt >= req.MaxRetries {
+				return err
+			}
+		} else {
+			body, readErr := io.ReadAll(io.LimitReader(resp.Body, 64<<20))
+			resp.Body.Close()
+			if readErr != nil {
+				return readErr
+			}
+			if resp.StatusCode/100 == 2 {
+				return json.Unmarshal(body, out)
+			}
+			retryable := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+			if !retryable || attempt >= req.MaxRetries {
+				return &StatusError{StatusCode: resp.StatusCode, Body: string(bytes.TrimSpace(body))}
+			}
+			if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && secs >= 0 {
+				backoff = time.Duration(secs) * time.Second
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
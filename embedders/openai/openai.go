@@ -0,0 +1,96 @@
+// Package openai implements serverlessVector.Embedder with the OpenAI embeddings API.
+package openai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/takara-ai/serverlessVector/v2/embedders/internal/httpjson"
+)
+
+// DefaultModel is used when Client.Model is empty.
+const DefaultModel = "text-embedding-3-small"
+
+// DefaultBaseURL is used when Client.BaseURL is empty.
+const DefaultBaseURL = "https://api.openai.com/v1"
+
+// maxBatch is the API's limit on inputs per request.
+const maxBatch = 2048
+
+// Client calls POST /embeddings. The zero value needs only APIKey.
+type Client struct {
+	APIKey     string
+	Model      string        // Default DefaultModel.
+	Dimensions int           // Truncate output (text-embedding-3 models only); 0 keeps the model default.
+	BaseURL    string        // Default DefaultBaseURL; set for Azure or a proxy.
+	BatchSize  int           // Inputs per request, at most 2048 (default).
+	MaxRetries int           // Retries on 429/5xx; default 3, negative disables.
+	MinBackoff time.Duration // First retry delay, doubled per attempt; default 500ms.
+	HTTPClient *http.Client  // Default http.DefaultClient.
+}
+
+// New returns a Client for apiKey using DefaultModel.
+func New(apiKey string) *Client {
+	return &Client{APIKey: apiKey}
+}
+
+type embeddingRequest struct {
+	Model      string   `json:"model"`
+	Input      []string `json:"input"`
+	Dimensions int      `json:"dimensions,omitempty"`
+}
+
+type embeddingResponse struct {
+	Data []struct {
+		Index     int       `json:"index"`
+		Embedding []float32 `json:"embedding"`
+	} `json:"data"`
+}
+
+// Embed returns one embedding per text, batching requests as needed.
+func (c *Client) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	if c.APIKey == "" {
+		return nil, errors.New("openai: API key is required")
+	}
+	model, baseURL := c.Model, c.BaseURL
+	if model == "" {
+		model = DefaultModel
+	}
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	batch := c.BatchSize
+	if batch <= 0 || batch > maxBatch {
+		batch = maxBatch
+	}
+	retries, backoff := httpjson.RetryPolicy(c.MaxRetries, c.MinBackoff)
+
+	out := make([][]float32, len(texts))
+	for start := 0; start < len(texts); start += batch {
+		end := min(start+batch, len(texts))
+		var resp embeddingResponse
+		err := httpjson.Post(ctx, c.HTTPClient, httpjson.Request{
+			URL:        baseURL + "/embeddings",
+			Headers:    map[string]string{"Authorization": "Bearer " + c.APIKey},
+			Body:       embeddingRequest{Model: model, Input: texts[start:end], Dimensions: c.Dimensions},
+			MaxRetries: retries,
+			MinBackoff: backoff,
+		}, &resp)
+		if err != nil {
+			return nil, fmt.Errorf("openai: %w", err)
+		}
+		if len(resp.Data) != end-start {
+			return nil, fmt.Errorf("openai: got %d embeddings for %d inputs", len(resp.Data), end-start)
+		}
+		for _, d := range resp.Data {
+			if d.Index < 0 || d.Index >= end-start || out[start+d.Index] != nil {
+				return nil, fmt.Errorf("openai: invalid embedding index %d", d.Index)
+			}
+			out[start+d.Index] = d.Embedding
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,102 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	serverlessVector "github.com/takara-ai/serverlessVector/v2"
+)
+
+var _ serverlessVector.Embedder = (*Client)(nil)
+
+// fakeAPI echoes each input's length as a 1-D embedding, returned in reverse order.
+func fakeAPI(t *testing.T, failures int32, requests *[]embeddingRequest) *httptest.Server {
+	var calls atomic.Int32
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/embeddings" || r.Header.Get("Authorization") != "Bearer sk-test" {
+			t.Errorf("unexpected request %s auth=%q", r.URL.Path, r.Header.Get("Authorization"))
+		}
+		if calls.Add(1) <= failures {
+			w.Header().Set("Retry-After", "0")
+			http.Error(w, `{"error":{"message":"rate limited"}}`, http.StatusTooManyRequests)
+			return
+		}
+		var req embeddingRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatal(err)
+		}
+		*requests = append(*requests, req)
+		var resp embeddingResponse
+		resp.Data = make([]struct {
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		}, len(req.Input))
+		for i, in := range req.Input {
+			j := len(req.Input) - 1 - i
+			resp.Data[j].Index = i
+			resp.Data[j].Embedding = []float32{float32(len(in))}
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+}
+
+func TestEmbed_BatchesAndOrders(t *testing.T) {
+	var reqs []embeddingRequest
+	srv := fakeAPI(t, 0, &reqs)
+	defer srv.Close()
+
+	c := &Client{APIKey: "sk-test", BaseURL: srv.URL, BatchSize: 2, Dimensions: 256, Model: "text-embedding-3-large"}
+	out, err := c.Embed(context.Background(), []string{"a", "bb", "ccc"})
+	if err != nil {
+		t.Fatalf("Embed failed: %v", err)
+	}
+	if len(reqs) != 2 {
+		t.Fatalf("expected 2 batched requests, got %d", len(reqs))
+	}
+	if reqs[0].Dimensions != 256 || reqs[0].Model != "text-embedding-3-large" {
+		t.Errorf("model/dimensions not sent: %+v", reqs[0])
+	}
+	for i, want := range []float32{1, 2, 3} {
+		if out[i][0] != want {
+			t.Errorf("embedding %d: want %v, got %v", i, want, out[i])
+		}
+	}
+}
+
+func TestEmbed_RetriesRateLimit(t *testing.T) {
+	var reqs []embeddingRequest
+	srv := fakeAPI(t, 2, &reqs)
+	defer srv.Close()
+
+	c := &Client{APIKey: "sk-test", BaseURL: srv.URL, MinBackoff: time.Millisecond}
+	if _, err := c.Embed(context.Background(), []string{"x"}); err != nil {
+		t.Fatalf("Embed should succeed after retries: %v", err)
+	}
+	c.MaxRetries = -1
+	reqs = nil
+	srv2 := fakeAPI(t, 1, &reqs)
+	defer srv2.Close()
+	c.BaseURL = srv2.URL
+	if _, err := c.Embed(context.Background(), []string{"x"}); err == nil {
+		t.Error("Embed must fail when retries are disabled")
+	}
+}
+
+func TestEmbed_ClientError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error":{"message":"bad model"}}`, http.StatusBadRequest)
+	}))
+	defer srv.Close()
+	c := &Client{APIKey: "sk-test", BaseURL: srv.URL, MinBackoff: time.Millisecond}
+	if _, err := c.Embed(context.Background(), []string{"x"}); err == nil {
+		t.Error("expected 400 to fail without retry")
+	}
+	if _, err := New("").Embed(context.Background(), []string{"x"}); err == nil {
+		t.Error("expected missing API key to fail")
+	}
+}
@@ -0,0 +1,122 @@
+// Package voyage implements serverlessVector.QueryEmbedder with the Voyage AI embeddings API.
+// Documents are embedded with input_type "document" and queries with "query".
+package voyage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/takara-ai/serverlessVector/v2/embedders/internal/httpjson"
+)
+
+// DefaultModel is used when Client.Model is empty.
+const DefaultModel = "voyage-3.5"
+
+// DefaultBaseURL is used when Client.BaseURL is empty.
+const DefaultBaseURL = "https://api.voyageai.com/v1"
+
+// Input types understood by the API.
+const (
+	InputDocument = "document"
+	InputQuery    = "query"
+)
+
+// defaultBatch keeps requests under the API's per-request token limits for typical chunks.
+const defaultBatch = 128
+
+// maxBatch is the API's limit on inputs per request.
+const maxBatch = 1000
+
+// Client calls POST /embeddings. The zero value needs only APIKey.
+type Client struct {
+	APIKey          string
+	Model           string        // Default DefaultModel.
+	OutputDimension int           // Models with flexible dimensions only; 0 keeps the model default.
+	BaseURL         string        // Default DefaultBaseURL.
+	BatchSize       int           // Inputs per request; default 128, at most 1000.
+	MaxRetries      int           // Retries on 429/5xx; default 3, negative disables.
+	MinBackoff      time.Duration // First retry delay, doubled per attempt; default 500ms.
+	HTTPClient      *http.Client  // Default http.DefaultClient.
+}
+
+// New returns a Client for apiKey using DefaultModel.
+func New(apiKey string) *Client {
+	return &Client{APIKey: apiKey}
+}
+
+type embeddingRequest struct {
+	Model           string   `json:"model"`
+	Input           []string `json:"input"`
+	InputType       string   `json:"input_type"`
+	OutputDimension int      `json:"output_dimension,omitempty"`
+}
+
+type embeddingResponse struct {
+	Data []struct {
+		Index     int       `json:"index"`
+		Embedding []float32 `json:"embedding"`
+	} `json:"data"`
+}
+
+// Embed embeds documents with input_type document.
+func (c *Client) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	return c.embed(ctx, texts, InputDocument)
+}
+
+// EmbedQuery embeds search queries with input_type query.
+func (c *Client) EmbedQuery(ctx context.Context, queries []string) ([][]float32, error) {
+	return c.embed(ctx, queries, InputQuery)
+}
+
+func (c *Client) embed(ctx context.Context, texts []string, inputType string) ([][]float32, error) {
+	if c.APIKey == "" {
+		return nil, errors.New("voyage: API key is required")
+	}
+	model, baseURL := c.Model, c.BaseURL
+	if model == "" {
+		model = DefaultModel
+	}
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	batch := c.BatchSize
+	if batch <= 0 {
+		batch = defaultBatch
+	}
+	batch = min(batch, maxBatch)
+	retries, backoff := httpjson.RetryPolicy(c.MaxRetries, c.MinBackoff)
+
+	out := make([][]float32, len(texts))
+	for start := 0; start < len(texts); start += batch {
+		end := min(start+batch, len(texts))
+		var resp embeddingResponse
+		err := httpjson.Post(ctx, c.HTTPClient, httpjson.Request{
+			URL:     baseURL + "/embeddings",
+			Headers: map[string]string{"Authorization": "Bearer " + c.APIKey},
+			Body: embeddingRequest{
+				Model:           model,
+				Input:           texts[start:end],
+				InputType:       inputType,
+				OutputDimension: c.OutputDimension,
+			},
+			MaxRetries: retries,
+			MinBackoff: backoff,
+		}, &resp)
+		if err != nil {
+			return nil, fmt.Errorf("voyage: %w", err)
+		}
+		if len(resp.Data) != end-start {
+			return nil, fmt.Errorf("voyage: got %d embeddings for %d inputs", len(resp.Data), end-start)
+		}
+		for _, d := range resp.Data {
+			if d.Index < 0 || d.Index >= end-start || out[start+d.Index] != nil {
+				return nil, fmt.Errorf("voyage: invalid embedding index %d", d.Index)
+			}
+			out[start+d.Index] = d.Embedding
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,63 @@
+package voyage
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	serverlessVector "github.com/takara-ai/serverlessVector/v2"
+)
+
+var _ serverlessVector.QueryEmbedder = (*Client)(nil)
+
+func TestEmbed_InputTypesAndOrder(t *testing.T) {
+	var reqs []embeddingRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/embeddings" || r.Header.Get("Authorization") != "Bearer key" {
+			t.Errorf("unexpected request %s", r.URL.Path)
+		}
+		var req embeddingRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatal(err)
+		}
+		reqs = append(reqs, req)
+		var resp embeddingResponse
+		resp.Data = make([]struct {
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		}, len(req.Input))
+		for i, in := range req.Input {
+			j := len(req.Input) - 1 - i // out of order on purpose
+			resp.Data[j].Index = i
+			resp.Data[j].Embedding = []float32{float32(len(in))}
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	defer srv.Close()
+
+	c := &Client{APIKey: "key", BaseURL: srv.URL, OutputDimension: 512}
+	docs, err := c.Embed(context.Background(), []string{"a", "bb", "ccc"})
+	if err != nil {
+		t.Fatalf("Embed failed: %v", err)
+	}
+	if docs[0][0] != 1 || docs[2][0] != 3 {
+		t.Errorf("embeddings must follow input order: %v", docs)
+	}
+	if _, err := c.EmbedQuery(context.Background(), []string{"q"}); err != nil {
+		t.Fatalf("EmbedQuery failed: %v", err)
+	}
+	if reqs[0].InputType != InputDocument || reqs[1].InputType != InputQuery {
+		t.Errorf("unexpected input types: %q, %q", reqs[0].InputType, reqs[1].InputType)
+	}
+	if reqs[0].OutputDimension != 512 || reqs[0].Model != DefaultModel {
+		t.Errorf("unexpected request: %+v", reqs[0])
+	}
+}
+
+func TestEmbed_RequiresKey(t *testing.T) {
+	if _, err := New("").Embed(context.Background(), []string{"x"}); err == nil {
+		t.Error("expected missing API key to fail")
+	}
+}
@@ -0,0 +1,68 @@
+// Package health serves a VectorDB's Health over HTTP as JSON, for load balancer, container
+// and Lambda Web Adapter probes. It uses the standard library only.
+package health
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	serverlessVector "github.com/takara-ai/serverlessVector/v2"
+)
+
+// Options adds readiness conditions to Health's own. Zero values add none.
+type Options struct {
+	MaxMemoryPressure float64 // Not ready above this HealthStatus.MemoryPressure, e.g. 0.95.
+	MaxPending        uint64  // Not ready while an auto snapshot has more unsaved changes.
+	SnapshotErrors    bool    // Not ready while an auto snapshot's last save failed.
+}
+
+// Check returns db's Health with opts applied: each condition that fails is added to Problems
+// and clears Ready.
+func Check(db *serverlessVector.VectorDB, opts *Options) serverlessVector.HealthStatus {
+	h := db.Health()
+	if opts == nil {
+		return h
+	}
+	if opts.MaxMemoryPressure > 0 && h.MemoryPressure > opts.MaxMemoryPressure {
+		h.Problems = append(h.Problems, fmt.Sprintf("memory pressure %.2f is above %.2f", h.MemoryPressure, opts.MaxMemoryPressure))
+	}
+	for _, s := range h.AutoSnapshots {
+		if opts.MaxPending > 0 && s.Pending > opts.MaxPending {
+			h.Problems = append(h.Problems, fmt.Sprintf("auto snapshot has %d unsaved changes, more than %d", s.Pending, opts.MaxPending))
+		}
+		if opts.SnapshotErrors && s.LastError != "" {
+			h.Problems = append(h.Problems, "auto snapshot failing: "+s.LastError)
+		}
+	}
+	h.Ready = len(h.Problems) == 0
+	return h
+}
+
+// ReadyHandler answers with Check(db, opts) as JSON: 200 OK when ready, 503 Service
+// Unavailable otherwise, so probes take the instance out of rotation until it recovers.
+func ReadyHandler(db *serverlessVector.VectorDB, opts *Options) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		h := Check(db, opts)
+		code := http.StatusOK
+		if !h.Ready {
+			code = http.StatusServiceUnavailable
+		}
+		write(w, code, h)
+	})
+}
+
+// LiveHandler answers 200 OK with db's Health as JSON whatever it reports: the process is up
+// and the DB answers, which is all a liveness probe should restart on.
+func LiveHandler(db *serverlessVector.VectorDB) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		write(w, http.StatusOK, db.Health())
+	})
+}
+
+func write(w http.ResponseWriter, code int, h serverlessVector.HealthStatus) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(h)
+}
@@ -0,0 +1,36 @@
+package health
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	serverlessVector "github.com/takara-ai/serverlessVector/v2"
+)
+
+func TestHandlers(t *testing.T) {
+	db := serverlessVector.NewVectorDBWithOptions(&serverlessVector.Options{Dimension: 2, MaxMemoryBytes: 4096})
+	_ = db.Add("a", []float32{1, 0})
+	get := func(h http.Handler) (int, serverlessVector.HealthStatus) {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("GET", "/healthz", nil))
+		var status serverlessVector.HealthStatus
+		if err := json.Unmarshal(rec.Body.Bytes(), &status); err != nil {
+			t.Fatal(err)
+		}
+		return rec.Code, status
+	}
+
+	if code, h := get(ReadyHandler(db, nil)); code != http.StatusOK || !h.Ready || h.Vectors != 1 {
+		t.Errorf("expected ready, got %d %+v", code, h)
+	}
+	pressure := db.Health().MemoryPressure
+	strict := &Options{MaxMemoryPressure: pressure / 2}
+	if code, h := get(ReadyHandler(db, strict)); code != http.StatusServiceUnavailable || h.Ready || len(h.Problems) != 1 {
+		t.Errorf("expected not ready past the memory pressure, got %d %+v", code, h)
+	}
+	if code, h := get(LiveHandler(db)); code != http.StatusOK || h.MemoryPressure != pressure {
+		t.Errorf("liveness must not apply readiness options, got %d %+v", code, h)
+	}
+}
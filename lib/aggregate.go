@@ -0,0 +1,134 @@
+package lib
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Centroid returns the mean of the stored vectors with ids, e.g. a user's taste profile from
+// the items they liked, ready to pass to Search as the query. With CosineSimilarity each
+// vector is scaled to unit length first, so long vectors do not dominate the direction.
+func (db *VectorDB) Centroid(ids ...string) ([]float32, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	members, err := db.membersLocked(ids, nil)
+	if err != nil {
+		return nil, err
+	}
+	return db.centroidLocked(members), nil
+}
+
+// CentroidWhere is Centroid over the vectors filter accepts. filter gets the stored vector,
+// as for SearchOptions.Filter, and must not call db.
+func (db *VectorDB) CentroidWhere(filter func(*Vector) bool) ([]float32, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	members, err := db.membersLocked(nil, filter)
+	if err != nil {
+		return nil, err
+	}
+	return db.centroidLocked(members), nil
+}
+
+// Medoid returns the ID of the vector among ids that is closest, by the DB's distance, to all
+// the others: the most typical member, which unlike a Centroid is a real stored vector. It
+// compares every pair, so keep the set to thousands of vectors at most.
+func (db *VectorDB) Medoid(ids ...string) (string, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	members, err := db.membersLocked(ids, nil)
+	if err != nil {
+		return "", err
+	}
+	return db.medoidLocked(members)
+}
+
+// MedoidWhere is Medoid over the vectors filter accepts, as for CentroidWhere.
+func (db *VectorDB) MedoidWhere(filter func(*Vector) bool) (string, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	members, err := db.membersLocked(nil, filter)
+	if err != nil {
+		return "", err
+	}
+	return db.medoidLocked(members)
+}
+
+// membersLocked returns the vectors with ids, or those filter accepts when ids is nil, which
+// must all have one dimension. Callers must hold the read lock.
+func (db *VectorDB) membersLocked(ids []string, filter func(*Vector) bool) ([]*Vector, error) {
+	var members []*Vector
+	if filter == nil {
+		for _, id := range ids {
+			v, ok := db.vectors[id]
+			if !ok {
+				return nil, fmt.Errorf("vector with ID %s not found", id)
+			}
+			members = append(members, v)
+		}
+	} else {
+		for _, v := range db.byOrd {
+			if v != nil && !v.deleted && filter(v) {
+				members = append(members, v)
+			}
+		}
+	}
+	if len(members) == 0 {
+		return nil, errors.New("no vectors to aggregate")
+	}
+	for _, v := range members[1:] {
+		if v.Dimension != members[0].Dimension {
+			return nil, fmt.Errorf("vector %s dimension %d does not match %s dimension %d",
+				v.ID, v.Dimension, members[0].ID, members[0].Dimension)
+		}
+	}
+	return members, nil
+}
+
+func (db *VectorDB) centroidLocked(members []*Vector) []float32 {
+	sum := make([]float64, members[0].Dimension)
+	var buf []float32
+	for _, v := range members {
+		data := db.floats(v, &buf)
+		scale := 1.0
+		if db.distFunc == CosineSimilarity {
+			if n := norm32(data); n > 0 {
+				scale = 1 / n
+			}
+		}
+		for i, x := range data {
+			sum[i] += float64(x) * scale
+		}
+	}
+	out := make([]float32, len(sum))
+	for i, s := range sum {
+		out[i] = float32(s / float64(len(members)))
+	}
+	return out
+}
+
+func (db *VectorDB) medoidLocked(members []*Vector) (string, error) {
+	lowerIsBetter := db.distFunc.lowerIsBetter()
+	best, bestTotal := "", math.Inf(1)
+	var buf []float32
+	for _, m := range members {
+		query := db.floatsCopy(m)
+		score, err := db.distanceTo(query, nil, len(query))
+		if err != nil {
+			return "", err
+		}
+		total := 0.0
+		for _, v := range members {
+			s := score(db.floats(v, &buf))
+			if !lowerIsBetter {
+				s = -s
+			}
+			total += s
+		}
+		if best == "" || total < bestTotal || total == bestTotal && m.ID < best {
+			best, bestTotal = m.ID, total
+		}
+	}
+	return best, nil
+}
@@ -0,0 +1,50 @@
+package lib
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCentroid(t *testing.T) {
+	db := NewVectorDB(2, EuclideanDistance)
+	_ = db.Add("a", []float32{0, 0}, VectorMetadata{Tags: map[string]string{"liked": "yes"}})
+	_ = db.Add("b", []float32{2, 0}, VectorMetadata{Tags: map[string]string{"liked": "yes"}})
+	_ = db.Add("c", []float32{1, 3}, VectorMetadata{Tags: map[string]string{"liked": "yes"}})
+	_ = db.Add("d", []float32{1, 1})
+
+	c, err := db.Centroid("a", "b", "c")
+	if err != nil || c[0] != 1 || c[1] != 1 {
+		t.Fatalf("expected [1 1], got %v, %v", c, err)
+	}
+	res, _ := db.SearchWithOptions(c, 1, &SearchOptions{ExcludeIDs: []string{"a", "b", "c"}})
+	if len(res.Results) != 1 || res.Results[0].ID != "d" {
+		t.Errorf("the centroid must work as a query, got %+v", res.Results)
+	}
+	liked := func(v *Vector) bool { return v.Metadata.Tags["liked"] == "yes" }
+	if w, _ := db.CentroidWhere(liked); w[0] != c[0] || w[1] != c[1] {
+		t.Errorf("CentroidWhere must match Centroid, got %v", w)
+	}
+	if _, err := db.Centroid("a", "missing"); err == nil {
+		t.Error("expected an error for an unknown ID")
+	}
+	if _, err := db.CentroidWhere(func(*Vector) bool { return false }); err == nil {
+		t.Error("expected an error for an empty set")
+	}
+
+	if m, err := db.Medoid("a", "b", "d"); err != nil || m != "d" {
+		t.Errorf("expected d to be the medoid, got %q, %v", m, err)
+	}
+	if m, _ := db.MedoidWhere(liked); m != "a" {
+		t.Errorf("expected a to win its tie with b, got %q", m)
+	}
+}
+
+func TestCentroid_CosineNormalizes(t *testing.T) {
+	db := NewVectorDB(2)
+	_ = db.Add("short", []float32{1, 0})
+	_ = db.Add("long", []float32{0, 100})
+	c, _ := db.Centroid("short", "long")
+	if math.Abs(float64(c[0]-c[1])) > 1e-6 {
+		t.Errorf("unit vectors must weigh equally, got %v", c)
+	}
+}
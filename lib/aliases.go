@@ -0,0 +1,68 @@
+package lib
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Aliases maps stable names to VectorDBs so that callers keep using one name, e.g.
+// "latest-index", while a rebuilt collection is swapped in behind it (a blue/green index
+// swap). The zero value is ready to use and safe for concurrent use.
+type Aliases struct {
+	mu      sync.RWMutex
+	targets map[string]*VectorDB
+}
+
+// Alias points name at target, replacing its previous target in one step: every Lookup that
+// starts after Alias returns gets target, while searches already running on the previous
+// target finish on it.
+func (a *Aliases) Alias(name string, target *VectorDB) {
+	a.Swap(name, target)
+}
+
+// Swap points name at target and returns its previous target (nil if name was not an alias),
+// so the caller can Close the retired collection once its searches are done.
+func (a *Aliases) Swap(name string, target *VectorDB) *VectorDB {
+	if target == nil {
+		panic("alias target cannot be nil (use Unalias)")
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.targets == nil {
+		a.targets = make(map[string]*VectorDB)
+	}
+	prev := a.targets[name]
+	a.targets[name] = target
+	return prev
+}
+
+// Lookup returns the DB name points at.
+func (a *Aliases) Lookup(name string) (*VectorDB, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	db, ok := a.targets[name]
+	if !ok {
+		return nil, fmt.Errorf("alias %s not found", name)
+	}
+	return db, nil
+}
+
+// Unalias removes name.
+func (a *Aliases) Unalias(name string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	delete(a.targets, name)
+}
+
+// Names returns the alias names in ascending order.
+func (a *Aliases) Names() []string {
+	a.mu.RLock()
+	names := make([]string, 0, len(a.targets))
+	for name := range a.targets {
+		names = append(names, name)
+	}
+	a.mu.RUnlock()
+	sort.Strings(names)
+	return names
+}
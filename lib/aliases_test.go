@@ -0,0 +1,54 @@
+package lib
+
+import (
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestAliases(t *testing.T) {
+	var a Aliases
+	if _, err := a.Lookup("latest"); err == nil {
+		t.Fatal("an unknown alias must fail")
+	}
+	blue, green := NewVectorDB(2), NewVectorDB(2)
+	_ = blue.Add("old", []float32{1, 0})
+	_ = green.Add("new", []float32{1, 0})
+
+	a.Alias("latest", blue)
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 100 {
+				db, err := a.Lookup("latest")
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				if res, err := db.Search([]float32{1, 0}, 1); err != nil || len(res.Results) != 1 {
+					t.Errorf("every lookup must see a complete collection: %v, %v", res, err)
+					return
+				}
+			}
+		}()
+	}
+	if prev := a.Swap("latest", green); prev != blue {
+		t.Error("Swap must return the previous target")
+	}
+	wg.Wait()
+	db, _ := a.Lookup("latest")
+	if res, _ := db.Search([]float32{1, 0}, 1); res.Results[0].ID != "new" {
+		t.Errorf("the alias must point at the new collection, got %+v", res.Results)
+	}
+
+	a.Alias("archive", blue)
+	if names := a.Names(); !slices.Equal(names, []string{"archive", "latest"}) {
+		t.Errorf("Names: got %v", names)
+	}
+	a.Unalias("archive")
+	if prev := a.Swap("other", blue); prev != nil || len(a.Names()) != 2 {
+		t.Error("Swap on a new name must return nil")
+	}
+}
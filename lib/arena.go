@@ -0,0 +1,50 @@
+package lib
+
+// arenaChunkBytes is the size of each block of vector data an arena allocates.
+const arenaChunkBytes = 1 << 20
+
+// arena keeps fixed-width vector data in large contiguous chunks, slot i holding the vector
+// with ordinal i, so a collection is a few hundred allocations instead of one per vector and
+// full scans in ordinal order read memory sequentially. Slots are reused with their ordinals.
+type arena[T float32 | byte] struct {
+	width    int // elements per slot, fixed by the first put
+	perChunk int // slots per chunk
+	chunks   [][]T
+}
+
+// put copies data into the slot for ord and returns the slot, or returns data itself when its
+// width differs from the arena's. size is the byte size of T.
+func (a *arena[T]) put(ord uint32, data []T, size int) []T {
+	if a.width == 0 {
+		a.width = len(data)
+		a.perChunk = max(1, arenaChunkBytes/(size*len(data)))
+	}
+	if len(data) != a.width {
+		return data
+	}
+	c, i := int(ord)/a.perChunk, int(ord)%a.perChunk
+	for len(a.chunks) <= c {
+		a.chunks = append(a.chunks, nil)
+	}
+	if a.chunks[c] == nil {
+		a.chunks[c] = make([]T, a.perChunk*a.width)
+	}
+	slot := a.chunks[c][i*a.width : (i+1)*a.width : (i+1)*a.width]
+	copy(slot, data)
+	return slot
+}
+
+// placeLocked packs v into the storage type and, once the DB has a fixed dimension, moves its
+// data into the arena slot of its ordinal. Callers must hold the write lock and have assigned
+// v.ord.
+func (db *VectorDB) placeLocked(v *Vector) {
+	db.packLocked(v)
+	if db.dimension == 0 || v.Dimension != db.dimension {
+		return
+	}
+	if v.packed != nil {
+		v.packed = db.packedArena.put(v.ord, v.packed, 1)
+	} else {
+		v.Data = db.dataArena.put(v.ord, v.Data, 4)
+	}
+}
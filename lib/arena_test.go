@@ -0,0 +1,49 @@
+package lib
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestArena_Storage(t *testing.T) {
+	db := NewVectorDB(3)
+	for i := range 5 {
+		_ = db.Add(fmt.Sprint(i), []float32{float32(i), 1, 1})
+	}
+	if len(db.dataArena.chunks) != 1 {
+		t.Fatalf("expected one chunk, got %d", len(db.dataArena.chunks))
+	}
+	a, b := db.vectors["0"].Data, db.vectors["1"].Data
+	if chunk := db.dataArena.chunks[0]; &chunk[0] != &a[0] || &chunk[3] != &b[0] || cap(a) != 3 {
+		t.Error("consecutive vectors must be adjacent slots capped at the dimension")
+	}
+
+	_ = db.Delete("1")
+	_ = db.Add("x", []float32{9, 9, 9})
+	if x := db.vectors["x"]; x.ord != 1 || &x.Data[0] != &b[0] {
+		t.Errorf("a freed slot must be reused, got ordinal %d", x.ord)
+	}
+	_ = db.Update("x", []float32{7, 7, 7})
+	_ = db.BatchAdd(map[string]any{"0": []float32{5, 5, 5}}, nil)
+	for id, want := range map[string]float32{"x": 7, "0": 5, "2": 2, "4": 4} {
+		if v, _ := db.Get(id); v.Data[0] != want {
+			t.Errorf("%s: got %v, want first component %v", id, v.Data, want)
+		}
+	}
+
+	db.Clear()
+	if db.dataArena.chunks != nil {
+		t.Error("Clear must release the arena")
+	}
+
+	flex := NewVectorDBWithOptions(&Options{FlexibleDimensions: true})
+	_ = flex.Add("a", []float32{1, 2})
+	if flex.dataArena.chunks != nil {
+		t.Error("flexible-dimension DBs keep per-vector slices")
+	}
+	packed := NewVectorDBWithOptions(&Options{Storage: Int8})
+	_ = packed.Add("a", []int8{1, 2})
+	if len(packed.packedArena.chunks) != 1 || packed.dataArena.chunks != nil {
+		t.Error("packed vectors must go to the byte arena")
+	}
+}
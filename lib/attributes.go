@@ -0,0 +1,159 @@
+package lib
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+// normalizeAttributes validates typed metadata and returns a copy in canonical form
+// (see normalizeValue).
+func normalizeAttributes(attrs map[string]any) (map[string]any, error) {
+	if attrs == nil {
+		return nil, nil
+	}
+	out := make(map[string]any, len(attrs))
+	for k, v := range attrs {
+		if k == "" {
+			return nil, errors.New("attribute key cannot be empty")
+		}
+		nv, err := normalizeValue(v)
+		if err != nil {
+			return nil, fmt.Errorf("attribute %q: %w", k, err)
+		}
+		out[k] = nv
+	}
+	return out, nil
+}
+
+// normalizeValue returns v in canonical form: every numeric type becomes float64,
+// time.Time is converted to UTC, []string is copied, and string and bool are kept.
+// NaN and any other type are rejected.
+func normalizeValue(v any) (any, error) {
+	switch x := v.(type) {
+	case string, bool:
+		return x, nil
+	case time.Time:
+		return x.UTC(), nil
+	case float64:
+		if math.IsNaN(x) {
+			return nil, errors.New("NaN is not a valid value")
+		}
+		return x, nil
+	case float32:
+		if math.IsNaN(float64(x)) {
+			return nil, errors.New("NaN is not a valid value")
+		}
+		return float64(x), nil
+	case int:
+		return float64(x), nil
+	case int8:
+		return float64(x), nil
+	case int16:
+		return float64(x), nil
+	case int32:
+		return float64(x), nil
+	case int64:
+		return float64(x), nil
+	case uint:
+		return float64(x), nil
+	case uint8:
+		return float64(x), nil
+	case uint16:
+		return float64(x), nil
+	case uint32:
+		return float64(x), nil
+	case uint64:
+		return float64(x), nil
+	case []string:
+		return append([]string(nil), x...), nil
+	}
+	return nil, fmt.Errorf("unsupported type %T (use string, bool, number, time.Time or []string)", v)
+}
+
+// project returns m with only the given tag and attribute keys.
+func (m VectorMetadata) project(keys []string) VectorMetadata {
+	out := VectorMetadata{CreatedAt: m.CreatedAt, UpdatedAt: m.UpdatedAt, Score: m.Score}
+	for _, k := range keys {
+		if v, ok := m.Tags[k]; ok {
+			if out.Tags == nil {
+				out.Tags = make(map[string]string, len(keys))
+			}
+			out.Tags[k] = v
+		}
+		if v, ok := m.Attributes[k]; ok {
+			if out.Attributes == nil {
+				out.Attributes = make(map[string]any, len(keys))
+			}
+			out.Attributes[k] = v
+		}
+	}
+	return out
+}
+
+// marshalAttributes encodes normalized attributes as JSON that unmarshalAttributes reads back
+// with their types: strings, bools, finite numbers and []string as themselves, times as
+// {"time": RFC 3339} and infinities as {"float": "+Inf"} or {"float": "-Inf"}.
+func marshalAttributes(attrs map[string]any) ([]byte, error) {
+	out := make(map[string]any, len(attrs))
+	for k, v := range attrs {
+		switch x := v.(type) {
+		case time.Time:
+			out[k] = map[string]string{"time": x.Format(time.RFC3339Nano)}
+		case float64:
+			if math.IsInf(x, 0) {
+				out[k] = map[string]string{"float": strconv.FormatFloat(x, 'g', -1, 64)}
+			} else {
+				out[k] = x
+			}
+		default:
+			out[k] = v
+		}
+	}
+	return json.Marshal(out)
+}
+
+// unmarshalAttributes decodes the output of marshalAttributes.
+func unmarshalAttributes(data []byte) (map[string]any, error) {
+	var raw map[string]any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("attributes: %w", err)
+	}
+	out := make(map[string]any, len(raw))
+	for k, v := range raw {
+		switch x := v.(type) {
+		case []any:
+			strs := make([]string, len(x))
+			for i, e := range x {
+				s, ok := e.(string)
+				if !ok {
+					return nil, fmt.Errorf("attribute %q: list element %v is not a string", k, e)
+				}
+				strs[i] = s
+			}
+			out[k] = strs
+		case map[string]any:
+			if s, ok := x["time"].(string); ok {
+				t, err := time.Parse(time.RFC3339Nano, s)
+				if err != nil {
+					return nil, fmt.Errorf("attribute %q: %w", k, err)
+				}
+				out[k] = t.UTC()
+			} else if s, ok := x["float"].(string); ok {
+				f, err := strconv.ParseFloat(s, 64)
+				if err != nil {
+					return nil, fmt.Errorf("attribute %q: %w", k, err)
+				}
+				out[k] = f
+			} else {
+				return nil, fmt.Errorf("attribute %q: unsupported value %v", k, x)
+			}
+		default:
+			out[k] = v
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,55 @@
+package lib
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAttributes_Normalized(t *testing.T) {
+	db := NewVectorDB(2)
+	when := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	labels := []string{"a", "b"}
+	err := db.Add("v", []float32{1, 0}, VectorMetadata{Attributes: map[string]any{
+		"price":  99,
+		"rating": float32(4.5),
+		"year":   uint16(2021),
+		"active": true,
+		"brand":  "acme",
+		"seen":   when,
+		"labels": labels,
+	}})
+	if err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	labels[0] = "mutated"
+
+	v, _ := db.Get("v")
+	a := v.Metadata.Attributes
+	if a["price"] != float64(99) || a["rating"] != 4.5 || a["year"] != float64(2021) {
+		t.Errorf("numbers must be stored as float64: %#v", a)
+	}
+	if a["active"] != true || a["brand"] != "acme" || !a["seen"].(time.Time).Equal(when) {
+		t.Errorf("unexpected attributes: %#v", a)
+	}
+	if got := a["labels"].([]string); got[0] != "a" {
+		t.Errorf("[]string must be copied, got %v", got)
+	}
+}
+
+func TestAttributes_RejectUnsupported(t *testing.T) {
+	db := NewVectorDB(2)
+	bad := VectorMetadata{Attributes: map[string]any{"nested": map[string]int{"x": 1}}}
+	if err := db.Add("v", []float32{1, 0}, bad); err == nil {
+		t.Error("Add must reject unsupported attribute types")
+	}
+	_ = db.Add("v", []float32{1, 0})
+	if err := db.Update("v", []float32{1, 0}, bad); err == nil {
+		t.Error("Update must reject unsupported attribute types")
+	}
+	if err := db.BatchAdd(map[string]any{"w": []float32{1, 0}}, map[string]VectorMetadata{"w": bad}); err == nil {
+		t.Error("BatchAdd must reject unsupported attribute types")
+	}
+	if err := db.Add("v", []float32{1, 0}, VectorMetadata{Attributes: map[string]any{"": 1}}); err == nil {
+		t.Error("empty attribute key must be rejected")
+	}
+}
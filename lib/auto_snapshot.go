@@ -0,0 +1,167 @@
+package lib
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// SnapshotStorage holds the latest persisted copy of a DB, e.g. an S3 object, a DynamoDB item
+// or a file on EFS. Snapshots are Parquet files as written by ExportParquet.
+type SnapshotStorage interface {
+	// WriteSnapshot replaces the stored snapshot with data.
+	WriteSnapshot(ctx context.Context, data []byte) error
+	// ReadSnapshot returns the stored snapshot, or an error wrapping fs.ErrNotExist if there
+	// is none yet.
+	ReadSnapshot(ctx context.Context) ([]byte, error)
+}
+
+// FileStorage is a SnapshotStorage kept in the file at its path (under /tmp or an EFS mount).
+// Writes go to a temporary file that is renamed over it, so a crash never leaves a partial
+// snapshot.
+type FileStorage string
+
+// WriteSnapshot implements SnapshotStorage.
+func (f FileStorage) WriteSnapshot(_ context.Context, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(string(f)), filepath.Base(string(f))+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // no-op once renamed
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), string(f))
+}
+
+// ReadSnapshot implements SnapshotStorage.
+func (f FileStorage) ReadSnapshot(context.Context) ([]byte, error) {
+	return os.ReadFile(string(f))
+}
+
+// SaveSnapshot writes all vectors to storage as a Parquet file (see ExportParquet), with
+// their tags, attributes and timestamps.
+func (db *VectorDB) SaveSnapshot(ctx context.Context, storage SnapshotStorage) (err error) {
+	defer db.logPersistence("snapshot save", time.Now(), &err)
+	var buf bytes.Buffer
+	if err := db.exportParquet(&buf, nil); err != nil {
+		return err
+	}
+	return storage.WriteSnapshot(ctx, buf.Bytes())
+}
+
+// AutoSnapshot saves a DB periodically; see StartAutoSnapshot.
+type AutoSnapshot struct {
+	db       *VectorDB
+	storage  SnapshotStorage
+	interval time.Duration
+
+	mu      sync.Mutex             // serializes saves
+	saved   atomic.Uint64          // Seq() at the last successful save
+	lastErr atomic.Pointer[string] // the last save's error, nil once one succeeds; for Health
+
+	stop     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// StartAutoSnapshot saves the DB to storage every interval, give or take 10% so that many
+// functions started together do not write at the same moment. Saves are skipped while
+// nothing has changed since the last one. Failed saves are logged to Options.Logger and
+// retried at the next interval. Call Stop before the process exits to save the last changes.
+func (db *VectorDB) StartAutoSnapshot(interval time.Duration, storage SnapshotStorage) *AutoSnapshot {
+	if interval <= 0 {
+		panic("auto snapshot interval must be > 0")
+	}
+	a := &AutoSnapshot{
+		db:       db,
+		storage:  storage,
+		interval: interval,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	a.saved.Store(db.Seq())
+	db.bgMu.Lock()
+	if db.autoSnapshots == nil {
+		db.autoSnapshots = make(map[*AutoSnapshot]struct{})
+	}
+	db.autoSnapshots[a] = struct{}{}
+	db.bgMu.Unlock()
+	go a.run()
+	return a
+}
+
+func (a *AutoSnapshot) run() {
+	defer close(a.done)
+	t := time.NewTimer(a.jittered())
+	defer t.Stop()
+	for {
+		select {
+		case <-a.stop:
+			return
+		case <-t.C:
+			if err := a.Flush(context.Background()); err != nil {
+				a.db.log(slog.LevelError, "auto snapshot failed", "error", err)
+			}
+			t.Reset(a.jittered())
+		}
+	}
+}
+
+// jittered returns the interval shifted by a random amount within ±10%.
+func (a *AutoSnapshot) jittered() time.Duration {
+	spread := int64(a.interval / 5)
+	if spread == 0 {
+		return a.interval
+	}
+	return a.interval - time.Duration(spread/2) + time.Duration(rand.Int64N(spread))
+}
+
+// Flush saves the DB now if it changed since the last save.
+func (a *AutoSnapshot) Flush(ctx context.Context) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	seq := a.db.Seq() // read first: a change racing the export is saved next time
+	if seq == a.saved.Load() {
+		return nil
+	}
+	if err := a.db.SaveSnapshot(ctx, a.storage); err != nil {
+		msg := err.Error()
+		a.lastErr.Store(&msg)
+		return err
+	}
+	a.saved.Store(seq)
+	a.lastErr.Store(nil)
+	return nil
+}
+
+// Stop ends periodic saving, waiting for a save in progress, then flushes the last changes.
+// Calling it again only flushes.
+func (a *AutoSnapshot) Stop(ctx context.Context) error {
+	a.stopOnce.Do(func() {
+		close(a.stop)
+		a.db.bgMu.Lock()
+		delete(a.db.autoSnapshots, a)
+		a.db.bgMu.Unlock()
+	})
+	select {
+	case <-a.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return a.Flush(ctx)
+}
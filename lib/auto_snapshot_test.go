@@ -0,0 +1,146 @@
+package lib
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memStorage is a SnapshotStorage in memory that counts writes.
+type memStorage struct {
+	mu     sync.Mutex
+	data   []byte
+	writes int
+}
+
+func (m *memStorage) WriteSnapshot(_ context.Context, data []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.data = bytes.Clone(data)
+	m.writes++
+	return nil
+}
+
+func (m *memStorage) ReadSnapshot(context.Context) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.data == nil {
+		return nil, fs.ErrNotExist
+	}
+	return m.data, nil
+}
+
+func (m *memStorage) count() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.writes
+}
+
+func TestFileStorage_RoundTrip(t *testing.T) {
+	f := FileStorage(filepath.Join(t.TempDir(), "db.parquet"))
+	if _, err := f.ReadSnapshot(context.Background()); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("expected fs.ErrNotExist before the first save, got %v", err)
+	}
+	db := NewVectorDB(2)
+	_ = db.Add("a", []float32{1, 2}, VectorMetadata{Tags: map[string]string{"lang": "en"}})
+	_ = db.Add("b", []float32{3, 4})
+	if err := db.SaveSnapshot(context.Background(), f); err != nil {
+		t.Fatal(err)
+	}
+	data, err := f.ReadSnapshot(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded := NewVectorDB(2)
+	if err := loaded.ImportParquet(bytes.NewReader(data), int64(len(data)), nil); err != nil {
+		t.Fatal(err)
+	}
+	if v, err := loaded.Get("a"); err != nil || v.Data[1] != 2 || v.Metadata.Tags["lang"] != "en" {
+		t.Errorf("unexpected restored vector %+v, %v", v, err)
+	}
+	if matches, _ := filepath.Glob(string(f) + ".tmp*"); len(matches) != 0 {
+		t.Errorf("temporary files left behind: %v", matches)
+	}
+}
+
+func TestAutoSnapshot(t *testing.T) {
+	db := NewVectorDB(2)
+	storage := &memStorage{}
+	a := db.StartAutoSnapshot(5*time.Millisecond, storage)
+	time.Sleep(30 * time.Millisecond)
+	if storage.count() != 0 {
+		t.Fatal("an unchanged DB must not be saved")
+	}
+
+	_ = db.Add("a", []float32{1, 2})
+	deadline := time.Now().Add(time.Second)
+	for storage.count() == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if storage.count() != 1 {
+		t.Fatalf("expected one periodic save, got %d", storage.count())
+	}
+
+	_ = db.Add("b", []float32{3, 4})
+	if err := a.Stop(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	n := storage.count()
+	_ = db.Add("c", []float32{5, 6})
+	time.Sleep(20 * time.Millisecond)
+	if storage.count() != n {
+		t.Error("no saves may happen after Stop")
+	}
+
+	loaded := NewVectorDB(2)
+	data, _ := storage.ReadSnapshot(context.Background())
+	if err := loaded.ImportParquet(bytes.NewReader(data), int64(len(data)), nil); err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Size() != 2 {
+		t.Errorf("Stop must flush the changes made before it, got %d vectors", loaded.Size())
+	}
+
+	if err := a.Stop(context.Background()); err != nil || storage.count() != n+1 {
+		t.Errorf("a second Stop must flush later changes, got %v after %d writes", err, storage.count())
+	}
+}
+
+func TestAutoSnapshot_Jitter(t *testing.T) {
+	a := &AutoSnapshot{interval: time.Second}
+	for range 100 {
+		if d := a.jittered(); d < 900*time.Millisecond || d >= 1100*time.Millisecond {
+			t.Fatalf("jittered interval %v is outside ±10%%", d)
+		}
+	}
+}
+
+func TestClose_FlushesAutoSnapshots(t *testing.T) {
+	db := NewVectorDB(2)
+	first, second := &memStorage{}, &memStorage{}
+	db.StartAutoSnapshot(time.Hour, first)
+	a := db.StartAutoSnapshot(time.Hour, second)
+	_ = db.Add("a", []float32{1, 2})
+	if err := a.Stop(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	_ = db.Add("b", []float32{3, 4})
+
+	if err := db.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if first.count() != 1 || second.count() != 1 {
+		t.Errorf("Close must flush running loops only: got %d and %d writes", first.count(), second.count())
+	}
+	if len(db.autoSnapshots) != 0 {
+		t.Error("Close must stop every loop")
+	}
+	if err := db.Close(context.Background()); err != nil {
+		t.Errorf("a second Close must be a no-op, got %v", err)
+	}
+}
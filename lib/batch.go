@@ -0,0 +1,179 @@
+package lib
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// batchMinQueries is the batch size from which BatchSearch scores queries together rather than
+// one scan each.
+const batchMinQueries = 4
+
+// batchBlockBytes is how much vector data a batched scan scores every query against before
+// moving on: small enough to stay in L2 cache while each query passes over it, so the vectors
+// are read from memory once per batch instead of once per query.
+const batchBlockBytes = 128 << 10
+
+// batchQuery is one query of a batched scan.
+type batchQuery struct {
+	id      string
+	query   []float32
+	score   func(v *Vector, data []float32, norm float64) float64
+	results resultHeap
+	stats   SearchStats
+	err     error
+}
+
+// searchBatch runs queries like searchCore with no options, in one blocked pass over the
+// vectors: each block of vectors is decoded once and scored against every query while it is
+// in cache, like a matrix multiply. Hooks and the slow log see each query as usual, and every
+// result's Took is the whole batch's. errs holds the error of each query that failed.
+func (db *VectorDB) searchBatch(queries map[string]any, topK int) (results map[string]*SearchResult, errs map[string]error) {
+	if topK <= 0 {
+		topK = 10
+	}
+	fail := func(id string, err error) {
+		if errs == nil {
+			errs = make(map[string]error)
+		}
+		errs[id] = err
+	}
+	h := db.hooks.Load()
+	batch := make([]*batchQuery, 0, len(queries))
+	for id, query := range queries {
+		query32, err := db.queryVector(query)
+		if err == nil && h != nil && h.OnSearch != nil {
+			err = h.OnSearch(query32, topK, nil)
+		}
+		if err != nil {
+			fail(id, err)
+			continue
+		}
+		batch = append(batch, &batchQuery{id: id, query: query32})
+	}
+
+	start := time.Now()
+	db.mu.RLock()
+	results = make(map[string]*SearchResult, len(batch))
+	for _, q := range db.scanBatchLocked(batch, topK) {
+		if q.err != nil {
+			continue
+		}
+		res := &SearchResult{QueryID: q.id, Results: q.results.results, stats: q.stats}
+		slices.SortFunc(res.Results, func(a, b SimilarityResult) int {
+			if ranksBefore(a, b, q.results.lowerIsBetter) {
+				return -1
+			}
+			return 1
+		})
+		var more bool
+		if res.Results, more = page(res.Results, 0, topK); more {
+			res.NextCursor = encodeCursor(topK)
+		}
+		res.Total = len(res.Results)
+		db.touchResults(res.Results)
+		results[q.id] = res
+	}
+	db.mu.RUnlock()
+	took := time.Since(start)
+
+	for _, q := range batch {
+		res := results[q.id]
+		if res != nil {
+			res.Took = took
+		} else {
+			fail(q.id, q.err)
+		}
+		if db.slowSearch > 0 && took >= db.slowSearch {
+			db.recordSlow(start, took, topK, res, q.err)
+		}
+		if h != nil && h.OnSearchDone != nil {
+			h.OnSearchDone(res, q.err, took)
+		}
+	}
+	return results, errs
+}
+
+// scanBatchLocked scores every live vector against each query in batch, keeping topK+1
+// results per query, and returns batch. Callers must hold db.mu.
+func (db *VectorDB) scanBatchLocked(batch []*batchQuery, topK int) []*batchQuery {
+	lowerIsBetter := db.distFunc.lowerIsBetter()
+	needFloats := false
+	for _, q := range batch {
+		q.results = resultHeap{results: make([]SimilarityResult, 0, topK+1), lowerIsBetter: lowerIsBetter}
+		if db.batchScorer(q) {
+			needFloats = true
+		}
+	}
+	cosine := needFloats && db.distFunc == CosineSimilarity
+
+	blockLen := max(1, batchBlockBytes/(4*max(db.dimension, 1)))
+	block := make([]*Vector, 0, blockLen)
+	data := make([][]float32, blockLen)
+	bufs := make([][]float32, blockLen) // decoding scratch for packed storage
+	norms := make([]float64, blockLen)
+	flush := func() {
+		if needFloats {
+			for i, v := range block {
+				data[i] = db.floats(v, &bufs[i])
+				if cosine {
+					norms[i] = norm32(data[i])
+				}
+			}
+		}
+		for _, q := range batch {
+			if q.err != nil {
+				continue
+			}
+			for i, v := range block {
+				q.stats.Candidates++
+				q.stats.Matched++
+				if v.Dimension != len(q.query) {
+					q.err = fmt.Errorf("query vector dimension %d does not match stored vector dimension %d", len(q.query), v.Dimension)
+					break
+				}
+				r := SimilarityResult{ID: v.ID, Score: q.score(v, data[i], norms[i]), Metadata: v.Metadata}
+				q.results.offer(r, topK+1)
+			}
+		}
+		block = block[:0]
+	}
+	for _, v := range db.byOrd {
+		if v == nil || v.deleted {
+			continue
+		}
+		if block = append(block, v); len(block) == blockLen {
+			flush()
+		}
+	}
+	flush()
+	return batch
+}
+
+// batchScorer sets q.score, or q.err if q cannot be scored, and reports whether the scorer
+// reads decoded vector data. Cosine scorers take the vector's norm, computed once per vector
+// for the whole batch; scores match searchLocked's exactly.
+func (db *VectorDB) batchScorer(q *batchQuery) bool {
+	if kernel := db.intKernel(q.query, nil); kernel != nil {
+		q.score = func(v *Vector, _ []float32, _ float64) float64 { return kernel(v.packed) }
+		return false
+	}
+	if db.distFunc == CosineSimilarity {
+		qNorm := norm32(q.query)
+		q.score = func(_ *Vector, data []float32, norm float64) float64 {
+			if qNorm == 0 || norm == 0 {
+				return 0
+			}
+			return dotProduct32(q.query, data) / (qNorm * norm)
+		}
+		return true
+	}
+	distance, err := db.distanceTo(q.query, nil, len(q.query))
+	if err != nil {
+		q.err = err
+		return false
+	}
+	q.score = func(_ *Vector, data []float32, _ float64) float64 { return distance(data) }
+	return true
+}
@@ -0,0 +1,99 @@
+package lib
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestBatchSearch_MatchesSearch(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 2))
+	randVec := func() []float32 {
+		v := make([]float32, 24)
+		for i := range v {
+			v[i] = float32(math.Round(rng.NormFloat64() * 20)) // integers, for Int8
+		}
+		return v
+	}
+	queries := make(map[string]any)
+	for i := range 9 {
+		queries[fmt.Sprint("q", i)] = randVec()
+	}
+	for _, storage := range []VectorType{Float32, Float16, Int8} {
+		for _, dist := range []DistanceFunction{CosineSimilarity, EuclideanDistance, DotProduct} {
+			db := NewVectorDBWithOptions(&Options{Dimension: 24, Distance: dist, Storage: storage})
+			for i := range 3000 { // several blocks
+				_ = db.Add(fmt.Sprint("v", i), randVec())
+			}
+			_ = db.Delete("v7")
+			got, err := db.BatchSearch(queries, 5)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for id, q := range queries {
+				want, _ := db.Search(q, 5)
+				g := got[id]
+				if g.QueryID != id || g.Total != 5 || g.NextCursor != want.NextCursor || g.Took <= 0 {
+					t.Fatalf("%v %v %s: unexpected result %+v", storage, dist, id, g)
+				}
+				for i := range want.Results {
+					if g.Results[i].ID != want.Results[i].ID || g.Results[i].Score != want.Results[i].Score {
+						t.Errorf("%v %v %s: result %d is %+v, want %+v", storage, dist, id, i, g.Results[i], want.Results[i])
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestBatchSearch_HooksAndErrors(t *testing.T) {
+	db := NewVectorDBWithOptions(&Options{FlexibleDimensions: true, SlowSearch: time.Nanosecond})
+	_ = db.Add("a", []float32{1, 0})
+	_ = db.Add("b", []float32{0, 1})
+	var seen, done []string
+	db.SetHooks(&Hooks{
+		OnSearch: func(query []float32, topK int, _ *SearchOptions) error {
+			if topK != 10 {
+				t.Errorf("hooks must see the default topK, got %d", topK)
+			}
+			if query[0] < 0 {
+				return fmt.Errorf("rejected")
+			}
+			return nil
+		},
+		OnSearchDone: func(res *SearchResult, err error, _ time.Duration) {
+			if res != nil {
+				done = append(done, res.QueryID)
+			} else {
+				seen = append(seen, err.Error())
+			}
+		},
+	})
+	queries := map[string]any{
+		"x":     []float32{1, 0},
+		"y":     []float32{0, 1},
+		"neg":   []float32{-1, 0},
+		"long":  []float32{1, 0, 0},
+		"empty": []float32{},
+	}
+	results, errs := db.BatchSearchWithOptions(queries, 0, nil)
+	if len(results) != 2 || results["x"].Results[0].ID != "a" || results["y"].Results[0].ID != "b" {
+		t.Fatalf("unexpected results %+v", results)
+	}
+	if len(errs) != 3 || errs["neg"] == nil || errs["long"] == nil || errs["empty"] == nil {
+		t.Errorf("unexpected errors %v", errs)
+	}
+	slices.Sort(done)
+	if !slices.Equal(done, []string{"x", "y"}) || len(seen) != 1 {
+		t.Errorf("OnSearchDone must see every searched query, got %v and %v", done, seen)
+	}
+	if len(db.SlowQueries()) != 3 {
+		t.Errorf("expected 3 slow queries, got %d", len(db.SlowQueries()))
+	}
+	if _, err := db.BatchSearch(queries); err == nil {
+		t.Error("BatchSearch must fail when a query fails")
+	}
+}
@@ -0,0 +1,149 @@
+package lib
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+// Boost adjusts scores from metadata (SearchOptions.Boosts). Boosts apply in order to the
+// score from the distance function. Higher is better for CosineSimilarity and DotProduct;
+// for distance metrics a multiplier above 1 pushes a result down.
+type Boost struct {
+	Key    string     // Numeric attribute (or numeric tag) to use; empty uses Weight alone.
+	Weight float64    // Scales the metadata value, or is the factor/bonus itself when Key is empty. 0 means 1.
+	Add    bool       // Add to the score instead of multiplying it.
+	If     *Condition // Apply only to vectors matching this condition.
+}
+
+// Decay scales scores by a recency factor from Metadata.CreatedAt (SearchOptions.Decay): 1 for
+// new vectors, 0.5 at HalfLife. Similarity scores are multiplied by it and distances divided by
+// it, so older vectors rank lower either way.
+type Decay struct {
+	HalfLife time.Duration // Age at which the factor is 0.5. Required.
+	Linear   bool          // Fall linearly to 0 at twice HalfLife instead of exponentially.
+	Now      time.Time     // Reference time; zero uses the time of the search.
+}
+
+// factor returns the decay multiplier for a vector created at createdAt (unix seconds).
+func (d *Decay) factor(now time.Time, createdAt int64) float64 {
+	age := now.Sub(time.Unix(createdAt, 0))
+	if age <= 0 {
+		return 1
+	}
+	halves := float64(age) / float64(d.HalfLife)
+	if d.Linear {
+		return math.Max(0, 1-halves/2)
+	}
+	return math.Exp2(-halves)
+}
+
+type boost struct {
+	Boost
+	cond *condition
+}
+
+// scorer compiles Boosts, Decay and Rescore into one function adjusting a raw score, or nil when
+// there is nothing to apply. lowerIsBetter is the metric's direction, which Decay follows. When
+// trace is non-nil each applied step is described in it.
+func (o *SearchOptions) scorer(lowerIsBetter bool) (func(v *Vector, score float64, trace *[]string) float64, error) {
+	if o == nil || len(o.Boosts) == 0 && o.Decay == nil && o.Rescore == nil {
+		return nil, nil
+	}
+	var decay *Decay
+	var now time.Time
+	if o.Decay != nil {
+		if o.Decay.HalfLife <= 0 {
+			return nil, errors.New("decay half-life must be > 0")
+		}
+		decay, now = o.Decay, o.Decay.Now
+		if now.IsZero() {
+			now = time.Now()
+		}
+	}
+	boosts := make([]boost, len(o.Boosts))
+	for i, b := range o.Boosts {
+		if b.Key == "" && b.If == nil && !b.Add && (b.Weight == 0 || b.Weight == 1) {
+			return nil, errors.New("boost has no effect: set Key, If or Weight")
+		}
+		if b.Weight == 0 {
+			b.Weight = 1
+		}
+		boosts[i].Boost = b
+		if b.If != nil {
+			c, err := b.If.compile()
+			if err != nil {
+				return nil, fmt.Errorf("boost: %w", err)
+			}
+			boosts[i].cond = &c
+		}
+	}
+	rescore := o.Rescore
+	return func(v *Vector, score float64, trace *[]string) float64 {
+		for i := range boosts {
+			b := &boosts[i]
+			if b.cond != nil && !b.cond.match(v) {
+				continue
+			}
+			amount, label := b.Weight, "constant"
+			if b.Key != "" {
+				x, ok := numericValue(v, b.Key)
+				if !ok {
+					continue
+				}
+				amount *= x
+				label = b.Key
+			}
+			if b.cond != nil {
+				label += " if " + b.If.String()
+			}
+			if b.Add {
+				score += amount
+			} else {
+				score *= amount
+			}
+			if trace != nil {
+				op := "×"
+				if b.Add {
+					op = "+"
+				}
+				*trace = append(*trace, fmt.Sprintf("%s%g (%s) = %g", op, amount, label, score))
+			}
+		}
+		if decay != nil {
+			f, op := decay.factor(now, v.Metadata.CreatedAt), "×"
+			if lowerIsBetter {
+				score /= f // +Inf once a linear decay reaches 0
+				op = "÷"
+			} else {
+				score *= f
+			}
+			if trace != nil {
+				*trace = append(*trace, fmt.Sprintf("%s%g (decay, created %s) = %g", op, f, time.Unix(v.Metadata.CreatedAt, 0).UTC().Format(time.RFC3339), score))
+			}
+		}
+		if rescore != nil {
+			before := score
+			score = rescore(v, score)
+			if trace != nil {
+				*trace = append(*trace, fmt.Sprintf("Rescore %g -> %g", before, score))
+			}
+		}
+		return score
+	}, nil
+}
+
+// numericValue reads key as a number: a numeric attribute, else a tag parsed as a float.
+func numericValue(v *Vector, key string) (float64, bool) {
+	if a, ok := v.Metadata.Attributes[key]; ok {
+		x, ok := a.(float64)
+		return x, ok
+	}
+	if t, ok := v.Metadata.Tags[key]; ok {
+		x, err := strconv.ParseFloat(t, 64)
+		return x, err == nil
+	}
+	return 0, false
+}
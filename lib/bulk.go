@@ -0,0 +1,165 @@
+package lib
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// BulkFormat is the record format read by BulkLoad.
+type BulkFormat int
+
+const (
+	// BulkJSONL is one JSON object per line:
+	// {"id": "doc1", "vector": [0.1, 0.2], "tags": {"lang": "en"}, "attributes": {"year": 2024}}
+	// Only id and vector are required.
+	BulkJSONL BulkFormat = iota + 1
+	// BulkCSV is one vector per row: the ID, then one column per dimension. A first row whose
+	// vector columns are not numbers is skipped as a header.
+	BulkCSV
+)
+
+// ProgressFunc receives the number of items processed so far and the total, or -1 while the
+// total is unknown.
+type ProgressFunc func(processed, total int)
+
+// bulkProgressEvery is how many items bulk operations process between progress reports.
+const bulkProgressEvery = 1000
+
+// reportProgress calls progress, if not nil, every bulkProgressEvery items and at the end.
+func reportProgress(progress ProgressFunc, processed, total int) {
+	if progress != nil && (processed%bulkProgressEvery == 0 || processed == total) {
+		progress(processed, total)
+	}
+}
+
+// bulkRecord is one BulkJSONL line.
+type bulkRecord struct {
+	ID         string            `json:"id"`
+	Vector     []float32         `json:"vector"`
+	Tags       map[string]string `json:"tags"`
+	Attributes map[string]any    `json:"attributes"`
+}
+
+// BulkLoad streams records from r and stores them in one write, without holding a
+// map[string]any of the whole input as BatchAdd needs. Records are parsed and validated
+// outside the lock; the write lock is held only to merge them, as for BatchAdd. Nothing is
+// stored unless every record is valid. progress, if not nil, is called every 1000 records
+// with a total of -1, then once with the final count as both values. A cancelled ctx stops
+// the load before anything is stored.
+func (db *VectorDB) BulkLoad(ctx context.Context, r io.Reader, format BulkFormat, progress ProgressFunc) (err error) {
+	defer db.logPersistence("bulk load", time.Now(), &err)
+	var next func() (string, []float32, *VectorMetadata, error)
+	switch format {
+	case BulkJSONL:
+		next = jsonlRecords(r)
+	case BulkCSV:
+		next = csvRecords(r)
+	default:
+		return fmt.Errorf("unknown bulk format %d", format)
+	}
+
+	var ids []string
+	var rows [][]float32
+	var metas []VectorMetadata
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		id, row, meta, err := next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("record %d: %w", len(ids)+1, err)
+		}
+		ids = append(ids, id)
+		rows = append(rows, row)
+		if meta != nil && metas == nil {
+			metas = make([]VectorMetadata, len(ids)-1)
+		}
+		if metas != nil {
+			var m VectorMetadata
+			if meta != nil {
+				m = *meta
+			}
+			metas = append(metas, m)
+		}
+		if progress != nil && len(ids)%bulkProgressEvery == 0 {
+			progress(len(ids), -1)
+		}
+	}
+	if err := db.importRows(rows, ids, metas); err != nil {
+		return err
+	}
+	if progress != nil {
+		progress(len(ids), len(ids))
+	}
+	return nil
+}
+
+// jsonlRecords returns a reader of BulkJSONL records that returns io.EOF at the end.
+func jsonlRecords(r io.Reader) func() (string, []float32, *VectorMetadata, error) {
+	dec := json.NewDecoder(bufio.NewReader(r))
+	return func() (string, []float32, *VectorMetadata, error) {
+		var rec bulkRecord
+		if err := dec.Decode(&rec); err != nil {
+			return "", nil, nil, err
+		}
+		if rec.Tags == nil && rec.Attributes == nil {
+			return rec.ID, rec.Vector, nil, nil
+		}
+		for k, v := range rec.Attributes {
+			if list, ok := v.([]any); ok { // JSON string arrays are []string attributes
+				strs := make([]string, len(list))
+				for i, x := range list {
+					if strs[i], ok = x.(string); !ok {
+						return "", nil, nil, fmt.Errorf("attribute %q: only string arrays are supported", k)
+					}
+				}
+				rec.Attributes[k] = strs
+			}
+		}
+		return rec.ID, rec.Vector, &VectorMetadata{Tags: rec.Tags, Attributes: rec.Attributes}, nil
+	}
+}
+
+// csvRecords returns a reader of BulkCSV records that returns io.EOF at the end.
+func csvRecords(r io.Reader) func() (string, []float32, *VectorMetadata, error) {
+	cr := csv.NewReader(bufio.NewReader(r))
+	cr.FieldsPerRecord = -1 // checked against the dimension on import
+	cr.ReuseRecord = true
+	header := true // the first row may be a header
+	return func() (string, []float32, *VectorMetadata, error) {
+		for {
+			fields, err := cr.Read()
+			if err != nil {
+				return "", nil, nil, err
+			}
+			if len(fields) < 2 {
+				return "", nil, nil, errors.New("expected an ID and at least one vector column")
+			}
+			row := make([]float32, len(fields)-1)
+			for i, f := range fields[1:] {
+				x, perr := strconv.ParseFloat(f, 32)
+				if perr != nil {
+					err = fmt.Errorf("column %d: %w", i+2, perr)
+					break
+				}
+				row[i] = float32(x)
+			}
+			if err != nil && header {
+				header = false
+				continue
+			}
+			header = false
+			return fields[0], row, nil, err
+		}
+	}
+}
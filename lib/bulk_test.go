@@ -0,0 +1,125 @@
+package lib
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestBulkLoad_JSONL(t *testing.T) {
+	var in strings.Builder
+	for i := range 2500 {
+		fmt.Fprintf(&in, `{"id": "v%d", "vector": [%d, 1]}`+"\n", i, i)
+	}
+	in.WriteString(`{"id": "tagged", "vector": [0, 1], "tags": {"lang": "en"}, "attributes": {"year": 2024, "labels": ["a", "b"]}}` + "\n")
+
+	db := NewVectorDB(0)
+	var calls [][2]int
+	err := db.BulkLoad(context.Background(), strings.NewReader(in.String()), BulkJSONL, func(processed, total int) {
+		calls = append(calls, [2]int{processed, total})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if db.Size() != 2501 || db.Dimension() != 2 {
+		t.Fatalf("expected 2501 vectors of dimension 2, got %d of %d", db.Size(), db.Dimension())
+	}
+	if want := [][2]int{{1000, -1}, {2000, -1}, {2501, 2501}}; !slices.Equal(calls, want) {
+		t.Errorf("progress calls %v, want %v", calls, want)
+	}
+	meta, _ := db.GetMetadata("tagged")
+	if meta.Tags["lang"] != "en" || meta.Attributes["year"] != 2024.0 || !slices.Equal(meta.Attributes["labels"].([]string), []string{"a", "b"}) {
+		t.Errorf("unexpected metadata %+v", meta)
+	}
+}
+
+func TestBulkLoad_CSV(t *testing.T) {
+	db := NewVectorDB(3)
+	in := "id,x,y,z\na,1,2,3\nb,4,5,6\n"
+	if err := db.BulkLoad(context.Background(), strings.NewReader(in), BulkCSV, nil); err != nil {
+		t.Fatal(err)
+	}
+	if v, err := db.Get("b"); err != nil || !slices.Equal(v.Data, []float32{4, 5, 6}) {
+		t.Errorf("unexpected vector %+v, %v", v, err)
+	}
+
+	bad := "a,1,2,3\nb,4,x,6\n"
+	if err := db.BulkLoad(context.Background(), strings.NewReader(bad), BulkCSV, nil); err == nil || !strings.Contains(err.Error(), "record 2") {
+		t.Errorf("expected an error naming record 2, got %v", err)
+	}
+	short := "c,1,2,3\nd,4,5\n"
+	if err := db.BulkLoad(context.Background(), strings.NewReader(short), BulkCSV, nil); err == nil {
+		t.Error("a wrong dimension must fail the load")
+	}
+	if db.Size() != 2 {
+		t.Errorf("failed loads must store nothing, got %d vectors", db.Size())
+	}
+}
+
+func TestBulkLoad_Cancelled(t *testing.T) {
+	db := NewVectorDB(2)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := db.BulkLoad(ctx, strings.NewReader(`{"id": "a", "vector": [1, 2]}`), BulkJSONL, nil)
+	if !errors.Is(err, context.Canceled) || db.Size() != 0 {
+		t.Errorf("expected context.Canceled and nothing stored, got %v and %d vectors", err, db.Size())
+	}
+	if err := db.BulkLoad(context.Background(), strings.NewReader(""), 99, nil); err == nil {
+		t.Error("an unknown format must fail")
+	}
+}
+
+func TestBulkOperations_ProgressAndCancel(t *testing.T) {
+	vectors := make(map[string]any, 2500)
+	for i := range 2500 {
+		vectors[fmt.Sprint(i)] = []float32{float32(i), 1}
+	}
+	metadata := map[string]VectorMetadata{"7": {Tags: map[string]string{"k": "v"}}}
+	db := NewVectorDB(2)
+	var calls [][2]int
+	record := func(processed, total int) { calls = append(calls, [2]int{processed, total}) }
+	if err := db.BatchAddContext(context.Background(), vectors, metadata, record); err != nil {
+		t.Fatal(err)
+	}
+	if want := [][2]int{{1000, 2500}, {2000, 2500}, {2500, 2500}}; !slices.Equal(calls, want) {
+		t.Errorf("BatchAddContext progress %v, want %v", calls, want)
+	}
+
+	calls = nil
+	if err := db.CreateTagIndexContext(context.Background(), "k", record); err != nil {
+		t.Fatal(err)
+	}
+	if len(calls) != 3 || calls[2] != [2]int{2500, 2500} {
+		t.Errorf("CreateTagIndexContext progress %v", calls)
+	}
+	if res, _ := db.SearchWithOptions([]float32{1, 1}, 5, &SearchOptions{Tags: map[string]string{"k": "v"}}); len(res.Results) != 1 {
+		t.Errorf("the index must be usable, got %+v", res)
+	}
+
+	for i := range 1500 {
+		_ = db.SoftDelete(fmt.Sprint(i))
+	}
+	calls = nil
+	if n, err := db.VacuumContext(context.Background(), record); n != 1500 || err != nil {
+		t.Fatalf("VacuumContext removed %d, %v", n, err)
+	}
+	if want := [][2]int{{1000, 1500}, {1500, 1500}}; !slices.Equal(calls, want) {
+		t.Errorf("VacuumContext progress %v, want %v", calls, want)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := db.BatchAddContext(ctx, map[string]any{"new": []float32{1, 2}}, nil, nil); !errors.Is(err, context.Canceled) || db.Size() != 1000 {
+		t.Errorf("a cancelled BatchAddContext must store nothing, got %v", err)
+	}
+	if err := db.CreateTagIndexContext(ctx, "other", nil); !errors.Is(err, context.Canceled) || len(db.TagIndexes()) != 1 {
+		t.Errorf("a cancelled build must not create the index, got %v and %v", err, db.TagIndexes())
+	}
+	_ = db.SoftDelete("2000")
+	if n, err := db.VacuumContext(ctx, nil); n != 0 || !errors.Is(err, context.Canceled) || db.SoftDeleted() != 1 {
+		t.Errorf("a cancelled vacuum must leave the rest for later, got %d, %v", n, err)
+	}
+}
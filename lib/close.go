@@ -0,0 +1,28 @@
+package lib
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"slices"
+)
+
+// Close stops every StartAutoSnapshot loop and flushes its last changes, returning the
+// errors of the flushes that failed. Wire it into a Lambda extension's shutdown event or a
+// SIGTERM handler. The DB stays usable; only its background work ends. If ctx ends first,
+// Close returns without waiting for the remaining saves.
+func (db *VectorDB) Close(ctx context.Context) error {
+	db.bgMu.Lock()
+	autos := slices.Collect(maps.Keys(db.autoSnapshots))
+	db.bgMu.Unlock()
+	var errs []error
+	for _, a := range autos {
+		if err := a.Stop(ctx); err != nil {
+			errs = append(errs, err)
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return errors.Join(errs...)
+}
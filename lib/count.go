@@ -0,0 +1,70 @@
+package lib
+
+import "math"
+
+// Count returns how many vectors match every condition in where, or all of them without
+// conditions. Conditions on keys with a tag index (CreateTagIndex) count from the index rather
+// than checking every vector.
+func (db *VectorDB) Count(where ...Condition) (int, error) {
+	n := 0
+	err := db.eachWhere(where, func(*Vector) { n++ })
+	return n, err
+}
+
+// NumericSummary describes the values of a numeric metadata key over a set of vectors.
+type NumericSummary struct {
+	Count int     // Vectors with a numeric value under the key.
+	Min   float64 // Zero when Count is 0, as are Max, Sum and Mean.
+	Max   float64
+	Sum   float64
+	Mean  float64
+}
+
+// Summarize returns the count, min, max, sum and mean of key among the vectors matching every
+// condition in where, for dashboards that would otherwise export the metadata. A numeric
+// attribute counts, as does a tag that parses as a number; vectors with neither under key are
+// left out.
+func (db *VectorDB) Summarize(key string, where ...Condition) (NumericSummary, error) {
+	var s NumericSummary
+	err := db.eachWhere(where, func(v *Vector) {
+		x, ok := numericValue(v, key)
+		if !ok || math.IsNaN(x) { // a "NaN" tag
+			return
+		}
+		if s.Count == 0 || x < s.Min {
+			s.Min = x
+		}
+		if s.Count == 0 || x > s.Max {
+			s.Max = x
+		}
+		s.Sum += x
+		s.Count++
+	})
+	if s.Count > 0 {
+		s.Mean = s.Sum / float64(s.Count)
+	}
+	return s, err
+}
+
+// eachWhere calls fn, under the read lock, for each vector matching every condition in where.
+func (db *VectorDB) eachWhere(where []Condition, fn func(*Vector)) error {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	return db.eachWhereLocked(where, fn)
+}
+
+// eachWhereLocked is eachWhere for callers that hold the read lock.
+func (db *VectorDB) eachWhereLocked(where []Condition, fn func(*Vector)) error {
+	opts := &SearchOptions{Where: where}
+	conds, filter, err := opts.compile()
+	if err != nil {
+		return err
+	}
+	scope, _, _ := db.scopeLocked(opts, conds, PlanAuto)
+	for v := range scope {
+		if filter == nil || filter(v) {
+			fn(v)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package lib
+
+import "testing"
+
+func TestCountAndSummarize(t *testing.T) {
+	db := NewVectorDB(2)
+	add := func(id, lang string, attrs map[string]any) {
+		_ = db.Add(id, []float32{1, 0}, VectorMetadata{Tags: map[string]string{"lang": lang}, Attributes: attrs})
+	}
+	add("a", "en", map[string]any{"price": 10})
+	add("b", "en", map[string]any{"price": 30})
+	add("c", "fr", map[string]any{"price": 20})
+	add("d", "en", map[string]any{"price": "n/a"})
+	_ = db.Add("e", []float32{1, 0}, VectorMetadata{Tags: map[string]string{"lang": "en", "price": "50"}})
+
+	for _, indexed := range []bool{false, true} {
+		if indexed {
+			_ = db.CreateTagIndex("lang")
+		}
+		if n, err := db.Count(); err != nil || n != 5 {
+			t.Errorf("Count() = %d, %v; want 5", n, err)
+		}
+		if n, _ := db.Count(Eq("lang", "en")); n != 4 {
+			t.Errorf("Count(lang = en) = %d, want 4", n)
+		}
+		if n, _ := db.Count(Eq("lang", "en"), Gt("price", 15)); n != 1 {
+			t.Errorf("Count(lang = en, price > 15) = %d, want 1", n)
+		}
+	}
+
+	s, err := db.Summarize("price", Eq("lang", "en"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s != (NumericSummary{Count: 3, Min: 10, Max: 50, Sum: 90, Mean: 30}) {
+		t.Errorf("unexpected summary %+v", s)
+	}
+	if s, _ := db.Summarize("missing"); s != (NumericSummary{}) {
+		t.Errorf("a missing key must summarize to zero, got %+v", s)
+	}
+	if _, err := db.Count(Condition{Key: "", Op: OpEq}); err == nil {
+		t.Error("an invalid condition must return error")
+	}
+}
@@ -0,0 +1,140 @@
+package lib
+
+import (
+	"slices"
+	"strings"
+)
+
+// KeepPolicy chooses the vector Deduplicate keeps from each group of near-duplicates.
+type KeepPolicy int
+
+const (
+	KeepOldest  KeepPolicy = iota // Earliest CreatedAt; the smallest ID on a tie.
+	KeepNewest                    // Latest UpdatedAt; the smallest ID on a tie.
+	KeepFirstID                   // Smallest ID.
+)
+
+// FindDuplicates returns the groups of near-identical vectors, such as those left by repeated
+// ingestion runs: vectors whose score against each other is at least threshold for a
+// similarity (e.g. 0.99 for CosineSimilarity) or at most threshold for a distance (e.g. 0.01
+// for EuclideanDistance), joined transitively. Each group lists its IDs in order, and groups
+// are ordered by their first ID. Every pair of vectors is compared, so it is O(n²·d).
+func (db *VectorDB) FindDuplicates(threshold float64) ([][]string, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	return db.duplicatesLocked(threshold)
+}
+
+func (db *VectorDB) duplicatesLocked(threshold float64) ([][]string, error) {
+	var live []*Vector
+	for _, v := range db.byOrd {
+		if v != nil && !v.deleted {
+			live = append(live, v)
+		}
+	}
+	slices.SortFunc(live, func(a, b *Vector) int { return strings.Compare(a.ID, b.ID) })
+
+	// parent is a union-find forest over indexes into live.
+	parent := make([]int, len(live))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	lowerIsBetter := db.distFunc.lowerIsBetter()
+	var buf []float32
+	for i, a := range live {
+		query := db.floatsCopy(a)
+		score, err := db.distanceTo(query, nil, len(query))
+		if err != nil {
+			return nil, err
+		}
+		for j := i + 1; j < len(live); j++ {
+			b := live[j]
+			if b.Dimension != a.Dimension {
+				continue
+			}
+			s := score(db.floats(b, &buf))
+			if lowerIsBetter && s <= threshold || !lowerIsBetter && s >= threshold {
+				ri, rj := find(i), find(j)
+				parent[max(ri, rj)] = min(ri, rj) // the root is the group's first ID
+			}
+		}
+	}
+
+	groups := make(map[int][]string)
+	for i, v := range live {
+		r := find(i)
+		groups[r] = append(groups[r], v.ID)
+	}
+	var out [][]string
+	for i := range live {
+		if g := groups[i]; len(g) > 1 {
+			out = append(out, g)
+		}
+	}
+	return out, nil
+}
+
+// Deduplicate deletes all but one vector of each group FindDuplicates(threshold) returns,
+// choosing the one to keep by keep, and returns the deleted IDs in order. Hooks.OnDelete runs
+// for each ID first; if it fails for any, nothing is deleted.
+func (db *VectorDB) Deduplicate(threshold float64, keep KeepPolicy) ([]string, error) {
+	db.mu.RLock()
+	groups, err := db.duplicatesLocked(threshold)
+	var removed []string
+	if err == nil {
+		for _, g := range groups {
+			kept := g[0]
+			for _, id := range g[1:] {
+				if keep.prefers(db.vectors[id], db.vectors[kept]) {
+					kept = id
+				}
+			}
+			for _, id := range g {
+				if id != kept {
+					removed = append(removed, id)
+				}
+			}
+		}
+	}
+	db.mu.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+	slices.Sort(removed)
+
+	if h := db.hooks.Load(); h != nil && h.OnDelete != nil {
+		for _, id := range removed {
+			if err := h.OnDelete(id); err != nil {
+				return nil, err
+			}
+		}
+	}
+	db.mu.Lock()
+	defer db.unlock()
+	deleted := removed[:0]
+	for _, id := range removed {
+		if v, ok := db.vectors[id]; ok {
+			db.removeLocked(v)
+			deleted = append(deleted, id)
+		}
+	}
+	return deleted, nil
+}
+
+// prefers reports whether keep chooses a over b, which has the smaller ID.
+func (keep KeepPolicy) prefers(a, b *Vector) bool {
+	switch keep {
+	case KeepOldest:
+		return a.Metadata.CreatedAt < b.Metadata.CreatedAt
+	case KeepNewest:
+		return a.Metadata.UpdatedAt > b.Metadata.UpdatedAt
+	}
+	return false
+}
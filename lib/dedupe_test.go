@@ -0,0 +1,61 @@
+package lib
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestFindDuplicates(t *testing.T) {
+	db := NewVectorDB(2)
+	_ = db.Add("a", []float32{1, 0})
+	_ = db.Add("b", []float32{0, 1})
+	_ = db.Add("a-copy", []float32{2, 0.001}) // same direction, so a duplicate for cosine
+	_ = db.Add("b-copy", []float32{0.001, 1})
+	_ = db.Add("c", []float32{1, 1})
+
+	groups, err := db.FindDuplicates(0.999)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{{"a", "a-copy"}, {"b", "b-copy"}}
+	if !reflect.DeepEqual(groups, want) {
+		t.Errorf("expected %v, got %v", want, groups)
+	}
+
+	euclid := NewVectorDB(1, EuclideanDistance)
+	for id, x := range map[string]float32{"x": 0, "y": 0.5, "z": 1, "far": 10} {
+		_ = euclid.Add(id, []float32{x})
+	}
+	if groups, _ := euclid.FindDuplicates(0.6); !reflect.DeepEqual(groups, [][]string{{"x", "y", "z"}}) {
+		t.Errorf("duplicates must be joined transitively, got %v", groups)
+	}
+}
+
+func TestDeduplicate(t *testing.T) {
+	db := NewVectorDB(2)
+	for _, id := range []string{"a", "b", "c"} {
+		_ = db.Add(id, []float32{1, 0})
+	}
+	_ = db.Add("other", []float32{0, 1})
+	db.vectors["b"].Metadata.CreatedAt = 1 // the oldest
+	db.vectors["c"].Metadata.UpdatedAt += 10
+
+	db.SetHooks(&Hooks{OnDelete: func(id string) error { return errors.New("no") }})
+	if _, err := db.Deduplicate(0.99, KeepOldest); err == nil || db.Size() != 4 {
+		t.Fatalf("a failing OnDelete must delete nothing: %v, %d vectors", err, db.Size())
+	}
+	db.SetHooks(nil)
+
+	snap := db.Snapshot()
+	removed, err := db.Deduplicate(0.99, KeepOldest)
+	if err != nil || !reflect.DeepEqual(removed, []string{"a", "c"}) {
+		t.Errorf("KeepOldest must keep b: removed %v, %v", removed, err)
+	}
+	if removed, _ := snap.db.Deduplicate(0.99, KeepNewest); !reflect.DeepEqual(removed, []string{"a", "b"}) {
+		t.Errorf("KeepNewest must keep c: removed %v", removed)
+	}
+	if db.Size() != 2 {
+		t.Errorf("expected 2 vectors left, got %d", db.Size())
+	}
+}
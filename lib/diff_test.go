@@ -0,0 +1,36 @@
+package lib
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	from := NewVectorDB(2)
+	_ = from.Add("same", []float32{1, 2}, VectorMetadata{Tags: map[string]string{"a": "1", "b": "2"}, Attributes: map[string]any{"n": 1}})
+	_ = from.Add("data", []float32{1, 2})
+	_ = from.Add("tags", []float32{1, 2}, VectorMetadata{Tags: map[string]string{"a": "1"}})
+	_ = from.Add("attrs", []float32{1, 2}, VectorMetadata{Attributes: map[string]any{"n": 1}})
+	_ = from.Add("gone", []float32{1, 2})
+
+	to := NewVectorDB(2)
+	_ = to.Add("same", []float32{1, 2}, VectorMetadata{Tags: map[string]string{"b": "2", "a": "1"}, Attributes: map[string]any{"n": 1.0}})
+	_ = to.Add("data", []float32{1, 3})
+	_ = to.Add("tags", []float32{1, 2}, VectorMetadata{Tags: map[string]string{"a": "2"}})
+	_ = to.Add("attrs", []float32{1, 2}, VectorMetadata{Attributes: map[string]any{"n": "1"}})
+	_ = to.Add("new", []float32{1, 2})
+
+	d := Diff(from, to)
+	if !slices.Equal(d.Added, []string{"new"}) || !slices.Equal(d.Removed, []string{"gone"}) || !slices.Equal(d.Changed, []string{"attrs", "data", "tags"}) {
+		t.Errorf("unexpected diff %+v", d)
+	}
+
+	snap := to.Snapshot()
+	_ = to.Delete("new")
+	if d := DiffSnapshots(snap, to.Snapshot()); len(d.Added) != 0 || !slices.Equal(d.Removed, []string{"new"}) || len(d.Changed) != 0 {
+		t.Errorf("unexpected snapshot diff %+v", d)
+	}
+	if d := Diff(to, to); d.Added != nil || d.Removed != nil || d.Changed != nil {
+		t.Errorf("a DB must not differ from itself: %+v", d)
+	}
+}
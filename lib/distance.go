@@ -1,6 +1,9 @@
 package lib
 
-import "math"
+import (
+	"fmt"
+	"math"
+)
 
 func dotProduct32(a, b []float32) float64 {
 	if len(a) != len(b) || len(a) == 0 {
@@ -36,15 +39,84 @@ func DistanceFloat32(a, b []float32, distanceFunc DistanceFunction) float64 {
 		return dot / (na * nb)
 	case DotProduct:
 		return dotProduct32(a, b)
-	case EuclideanDistance:
+	case EuclideanDistance, MahalanobisDistance:
 		return euclidean32(a, b)
 	case ManhattanDistance:
 		return manhattan32(a, b)
+	case JaccardSimilarity:
+		return jaccard32(a, b)
+	case TanimotoSimilarity:
+		return tanimoto32(a, b)
+	case PearsonCorrelation:
+		return pearson32(a, b)
+	case KLDivergence:
+		return kl32(a, b, nil)
+	case JensenShannonDistance:
+		return js32(a, b, nil)
 	default:
 		return dotProduct32(a, b)
 	}
 }
 
+// weightedDistance32 is DistanceFloat32 with dimension i scaled by w[i]: each product in the dot
+// product and norms, and each squared or absolute difference, is multiplied by w[i].
+// a, b and w must have the same length.
+func weightedDistance32(a, b, w []float32, distanceFunc DistanceFunction) float64 {
+	switch distanceFunc {
+	case KLDivergence:
+		return kl32(a, b, w)
+	case JensenShannonDistance:
+		return js32(a, b, w)
+	}
+	var dot, na, nb, sq, abs, lo, hi, sw, sa, sb float64
+	for i := range a {
+		x, y, wi := float64(a[i]), float64(b[i]), float64(w[i])
+		d := x - y
+		dot += wi * x * y
+		na += wi * x * x
+		nb += wi * y * y
+		sq += wi * d * d
+		abs += wi * math.Abs(d)
+		lo += wi * math.Min(x, y)
+		hi += wi * math.Max(x, y)
+		sw += wi
+		sa += wi * x
+		sb += wi * y
+	}
+	switch distanceFunc {
+	case CosineSimilarity:
+		if na == 0 || nb == 0 {
+			return 0
+		}
+		return dot / math.Sqrt(na*nb)
+	case EuclideanDistance:
+		return math.Sqrt(sq)
+	case ManhattanDistance:
+		return abs
+	case JaccardSimilarity:
+		if hi == 0 {
+			return 0
+		}
+		return lo / hi
+	case TanimotoSimilarity:
+		if den := na + nb - dot; den != 0 {
+			return dot / den
+		}
+		return 0
+	case PearsonCorrelation:
+		if sw == 0 {
+			return 0
+		}
+		cov, va, vb := dot-sa*sb/sw, na-sa*sa/sw, nb-sb*sb/sw
+		if va <= 0 || vb <= 0 {
+			return 0
+		}
+		return cov / math.Sqrt(va*vb)
+	default:
+		return dot
+	}
+}
+
 func (db *VectorDB) distanceFloat32(a, b []float32, distanceFunc DistanceFunction) float64 {
 	return DistanceFloat32(a, b, distanceFunc)
 }
@@ -77,3 +149,126 @@ func manhattan32(a, b []float32) float64 {
 	}
 	return sum
 }
+
+// jaccard32 returns Σmin/Σmax over the components, 0 when both vectors are zero.
+func jaccard32(a, b []float32) float64 {
+	if !sameLen32(a, b) {
+		return 0
+	}
+	var lo, hi float64
+	for i := range a {
+		x, y := float64(a[i]), float64(b[i])
+		lo += math.Min(x, y)
+		hi += math.Max(x, y)
+	}
+	if hi == 0 {
+		return 0
+	}
+	return lo / hi
+}
+
+// tanimoto32 returns a·b / (|a|² + |b|² - a·b), 0 when both vectors are zero.
+func tanimoto32(a, b []float32) float64 {
+	if !sameLen32(a, b) {
+		return 0
+	}
+	dot := dotProduct32(a, b)
+	den := dotProduct32(a, a) + dotProduct32(b, b) - dot
+	if den == 0 {
+		return 0
+	}
+	return dot / den
+}
+
+// pearson32 returns the correlation of a and b, 0 when either is constant.
+func pearson32(a, b []float32) float64 {
+	if !sameLen32(a, b) || len(a) == 0 {
+		return 0
+	}
+	var ma, mb float64
+	for i := range a {
+		ma += float64(a[i])
+		mb += float64(b[i])
+	}
+	ma /= float64(len(a))
+	mb /= float64(len(b))
+	var cov, va, vb float64
+	for i := range a {
+		x, y := float64(a[i])-ma, float64(b[i])-mb
+		cov += x * y
+		va += x * x
+		vb += y * y
+	}
+	if va == 0 || vb == 0 {
+		return 0
+	}
+	return cov / math.Sqrt(va*vb)
+}
+
+// distributionTolerance is how far from 1 the sum of a probability vector may be.
+const distributionTolerance = 1e-3
+
+// checkVector rejects vectors df cannot score: KLDivergence and JensenShannonDistance need
+// probability distributions, with non-negative components summing to 1.
+func (df DistanceFunction) checkVector(v []float32) error {
+	if df != KLDivergence && df != JensenShannonDistance {
+		return nil
+	}
+	var sum float64
+	for i, x := range v {
+		if x < 0 || math.IsNaN(float64(x)) {
+			return fmt.Errorf("%v needs probability distributions: component %d is %v", df, i, x)
+		}
+		sum += float64(x)
+	}
+	if math.Abs(sum-1) > distributionTolerance {
+		return fmt.Errorf("%v needs probability distributions: components sum to %g, not 1", df, sum)
+	}
+	return nil
+}
+
+// kl32 returns Σ w·p·ln(p/q) (w nil means 1); terms with p = 0 contribute nothing.
+func kl32(p, q, w []float32) float64 {
+	if !sameLen32(p, q) {
+		return math.Inf(1)
+	}
+	var sum float64
+	for i := range p {
+		if p[i] <= 0 {
+			continue
+		}
+		if q[i] <= 0 {
+			return math.Inf(1)
+		}
+		t := float64(p[i]) * math.Log(float64(p[i])/float64(q[i]))
+		if w != nil {
+			t *= float64(w[i])
+		}
+		sum += t
+	}
+	return sum
+}
+
+// js32 returns sqrt(JSD(p, q)) with base-2 logarithms (w nil means 1).
+func js32(p, q, w []float32) float64 {
+	if !sameLen32(p, q) {
+		return math.Inf(1)
+	}
+	var sum float64
+	for i := range p {
+		x, y := float64(p[i]), float64(q[i])
+		m := (x + y) / 2
+		var t float64
+		if x > 0 {
+			t += x * math.Log2(x/m)
+		}
+		if y > 0 {
+			t += y * math.Log2(y/m)
+		}
+		if w != nil {
+			t *= float64(w[i])
+		}
+		sum += t / 2
+	}
+	return math.Sqrt(math.Max(0, sum))
+}
@@ -0,0 +1,199 @@
+package lib
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// driftZ is how many of the baseline's standard deviations a batch's average norm or
+// alignment may move before DriftReport.Drifted flags it.
+const driftZ = 3
+
+// Baseline summarizes the embeddings of a corpus, to tell whether later vectors came from the
+// same model. Its fields are exported so a Baseline can be saved (e.g. as JSON) next to a
+// snapshot and compared against new ingests after the next cold start.
+type Baseline struct {
+	Count     int       // Vectors summarized.
+	Mean      []float32 // Mean vector.
+	NormMean  float64   // Mean of the vectors' norms.
+	NormStd   float64   // Standard deviation of the vectors' norms.
+	AlignMean float64   // Mean cosine similarity of each vector to Mean.
+	AlignStd  float64   // Standard deviation of those cosine similarities.
+}
+
+// DriftReport compares a batch of vectors with a Baseline. Shifts are measured in the
+// baseline's standard deviations of single vectors, so a batch drawn from the same model
+// stays near 0 whatever its size.
+type DriftReport struct {
+	Count      int     // Vectors compared.
+	MeanCosine float64 // Cosine similarity between the batch's mean vector and Baseline.Mean.
+	NormShift  float64 // Batch mean norm minus NormMean, over NormStd.
+	AlignShift float64 // Batch mean cosine to Baseline.Mean minus AlignMean, over AlignStd.
+	// Drifted reports a shift of more than 3 in either: the norms moved, or the vectors no
+	// longer point the way the corpus does, as when another embedding model produced them.
+	Drifted bool
+}
+
+// Baseline summarizes the vectors matching every condition in where, or all of them without
+// conditions, which must share one dimension. Take it while the corpus comes from a single
+// model, then pass new batches to Baseline.Compare before adding them, or check vectors
+// already added with Drift. It reads the vectors twice under the read lock, O(n·d).
+func (db *VectorDB) Baseline(where ...Condition) (*Baseline, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	var sum []float64
+	var norms []float64
+	var dimErr error
+	var buf []float32
+	err := db.eachWhereLocked(where, func(v *Vector) {
+		switch {
+		case dimErr != nil:
+			return
+		case sum == nil:
+			sum = make([]float64, v.Dimension)
+		case v.Dimension != len(sum):
+			dimErr = fmt.Errorf("cannot summarize mixed dimensions %d and %d", len(sum), v.Dimension)
+			return
+		}
+		data := db.floats(v, &buf)
+		for i, x := range data {
+			sum[i] += float64(x)
+		}
+		norms = append(norms, norm32(data))
+	})
+	if err == nil {
+		err = dimErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(norms) < 2 {
+		return nil, errors.New("need at least 2 vectors for a baseline")
+	}
+
+	b := &Baseline{Count: len(norms), Mean: make([]float32, len(sum))}
+	for i, s := range sum {
+		b.Mean[i] = float32(s / float64(len(norms)))
+	}
+	b.NormMean, b.NormStd = meanStd(norms)
+	meanNorm := norm32(b.Mean)
+	aligns := make([]float64, 0, len(norms))
+	_ = db.eachWhereLocked(where, func(v *Vector) {
+		data := db.floats(v, &buf)
+		aligns = append(aligns, cosineTo(data, norm32(data), b.Mean, meanNorm))
+	})
+	b.AlignMean, b.AlignStd = meanStd(aligns)
+	return b, nil
+}
+
+// Compare reports how far vectors, e.g. a batch about to be added, drift from the baseline.
+// It fails on an empty batch or a vector whose dimension differs from the baseline's, the
+// plainest sign of a model change.
+func (b *Baseline) Compare(vectors [][]float32) (*DriftReport, error) {
+	var d driftAccumulator
+	for i, v := range vectors {
+		if err := d.add(b, v); err != nil {
+			return nil, fmt.Errorf("vector %d: %w", i, err)
+		}
+	}
+	return d.report(b)
+}
+
+// Drift compares the stored vectors matching every condition in where, e.g. those tagged
+// with the latest ingestion batch, with b.
+func (db *VectorDB) Drift(b *Baseline, where ...Condition) (*DriftReport, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	var d driftAccumulator
+	var addErr error
+	var buf []float32
+	err := db.eachWhereLocked(where, func(v *Vector) {
+		if addErr == nil {
+			if err := d.add(b, db.floats(v, &buf)); err != nil {
+				addErr = fmt.Errorf("vector %s: %w", v.ID, err)
+			}
+		}
+	})
+	if err == nil {
+		err = addErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	return d.report(b)
+}
+
+// driftAccumulator sums what a DriftReport needs over a batch in one pass.
+type driftAccumulator struct {
+	n     int
+	sum   []float64
+	norms float64
+	align float64
+}
+
+func (d *driftAccumulator) add(b *Baseline, v []float32) error {
+	if len(v) != len(b.Mean) {
+		return fmt.Errorf("dimension %d does not match the baseline dimension %d", len(v), len(b.Mean))
+	}
+	if d.sum == nil {
+		d.sum = make([]float64, len(v))
+	}
+	for i, x := range v {
+		d.sum[i] += float64(x)
+	}
+	norm := norm32(v)
+	d.norms += norm
+	d.align += cosineTo(v, norm, b.Mean, norm32(b.Mean))
+	d.n++
+	return nil
+}
+
+func (d *driftAccumulator) report(b *Baseline) (*DriftReport, error) {
+	if d.n == 0 {
+		return nil, errors.New("no vectors to compare")
+	}
+	mean := make([]float32, len(d.sum))
+	for i, s := range d.sum {
+		mean[i] = float32(s / float64(d.n))
+	}
+	r := &DriftReport{
+		Count:      d.n,
+		MeanCosine: cosineTo(mean, norm32(mean), b.Mean, norm32(b.Mean)),
+		NormShift:  shift(d.norms/float64(d.n), b.NormMean, b.NormStd),
+		AlignShift: shift(d.align/float64(d.n), b.AlignMean, b.AlignStd),
+	}
+	r.Drifted = math.Abs(r.NormShift) > driftZ || r.AlignShift < -driftZ
+	return r, nil
+}
+
+// cosineTo returns the cosine similarity of a and b given their norms, 0 if either is zero.
+func cosineTo(a []float32, normA float64, b []float32, normB float64) float64 {
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dotProduct32(a, b) / (normA * normB)
+}
+
+// shift returns x - mean in units of std, or ±Inf when std is 0 and x differs from mean.
+func shift(x, mean, std float64) float64 {
+	diff := x - mean
+	if std > 0 {
+		return diff / std
+	}
+	if math.Abs(diff) <= 1e-9*max(1, math.Abs(mean)) {
+		return 0
+	}
+	return math.Copysign(math.Inf(1), diff)
+}
+
+func meanStd(values []float64) (mean, std float64) {
+	for _, x := range values {
+		mean += x
+	}
+	mean /= float64(len(values))
+	for _, x := range values {
+		std += (x - mean) * (x - mean)
+	}
+	return mean, math.Sqrt(std / float64(len(values)))
+}
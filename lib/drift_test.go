@@ -0,0 +1,66 @@
+package lib
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"testing"
+)
+
+func TestBaselineDrift(t *testing.T) {
+	rng := rand.New(rand.NewPCG(7, 7))
+	// Model "a" embeddings share a common direction, as real text embeddings do; model "b"
+	// spreads the same kind of vectors along another one.
+	embed := func(model string) []float32 {
+		v := make([]float32, 16)
+		for i := range v {
+			v[i] = float32(rng.NormFloat64() * 0.3)
+		}
+		if model == "a" {
+			v[0] += 1
+		} else {
+			v[5] += 1
+		}
+		return v
+	}
+	db := NewVectorDB(16)
+	for i := range 200 {
+		_ = db.Add(fmt.Sprint("a", i), embed("a"), VectorMetadata{Tags: map[string]string{"batch": "1"}})
+	}
+	b, err := db.Baseline()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.Count != 200 || len(b.Mean) != 16 || b.Mean[0] < 0.8 || b.NormStd <= 0 || b.AlignStd <= 0 {
+		t.Fatalf("unexpected baseline %+v", b)
+	}
+
+	var same, other [][]float32
+	for range 50 {
+		same, other = append(same, embed("a")), append(other, embed("b"))
+	}
+	if r, err := b.Compare(same); err != nil || r.Drifted || r.Count != 50 || r.MeanCosine < 0.9 {
+		t.Errorf("vectors from the same model must not drift, got %+v, %v", r, err)
+	}
+	if r, _ := b.Compare(other); !r.Drifted || r.AlignShift > -driftZ {
+		t.Errorf("vectors from another model must drift, got %+v", r)
+	}
+	if _, err := b.Compare([][]float32{make([]float32, 8)}); err == nil {
+		t.Error("expected a dimension mismatch error")
+	}
+	if _, err := b.Compare(nil); err == nil {
+		t.Error("expected an error comparing no vectors")
+	}
+
+	for i, v := range other {
+		_ = db.Add(fmt.Sprint("b", i), v, VectorMetadata{Tags: map[string]string{"batch": "2"}})
+	}
+	if r, err := db.Drift(b, Eq("batch", "1")); err != nil || r.Drifted {
+		t.Errorf("the baseline batch must not drift, got %+v, %v", r, err)
+	}
+	if r, err := db.Drift(b, Eq("batch", "2")); err != nil || !r.Drifted || r.Count != 50 {
+		t.Errorf("the mixed-in batch must drift, got %+v, %v", r, err)
+	}
+	if _, err := db.Baseline(Eq("batch", "3")); err == nil {
+		t.Error("expected an error for a baseline over no vectors")
+	}
+}
@@ -0,0 +1,99 @@
+package lib
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Embedder turns texts into vectors, one per input, in order.
+type Embedder interface {
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// QueryEmbedder is implemented by asymmetric embedders that encode search queries
+// differently from documents. SearchText uses EmbedQuery when it is available.
+type QueryEmbedder interface {
+	Embedder
+	EmbedQuery(ctx context.Context, queries []string) ([][]float32, error)
+}
+
+// EmbedderFunc adapts a function to the Embedder interface.
+type EmbedderFunc func(ctx context.Context, texts []string) ([][]float32, error)
+
+// Embed calls f(ctx, texts).
+func (f EmbedderFunc) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	return f(ctx, texts)
+}
+
+var errNoEmbedder = errors.New("no embedder configured (use SetEmbedder)")
+
+// SetEmbedder sets the Embedder used by AddText, BatchAddText and SearchText.
+func (db *VectorDB) SetEmbedder(e Embedder) {
+	db.mu.Lock()
+	db.embedder = e
+	db.mu.Unlock()
+}
+
+// AddText embeds text and adds the result under id.
+func (db *VectorDB) AddText(ctx context.Context, id, text string, metadata ...VectorMetadata) error {
+	vecs, err := db.embed(ctx, []string{text}, false)
+	if err != nil {
+		return err
+	}
+	return db.Add(id, vecs[0], metadata...)
+}
+
+// BatchAddText embeds all texts in one Embed call and adds them like BatchAdd.
+func (db *VectorDB) BatchAddText(ctx context.Context, texts map[string]string, metadata map[string]VectorMetadata) error {
+	if len(texts) == 0 {
+		return errors.New("no texts provided")
+	}
+	ids := make([]string, 0, len(texts))
+	inputs := make([]string, 0, len(texts))
+	for id, text := range texts {
+		ids = append(ids, id)
+		inputs = append(inputs, text)
+	}
+	vecs, err := db.embed(ctx, inputs, false)
+	if err != nil {
+		return err
+	}
+	vectors := make(map[string]any, len(ids))
+	for i, id := range ids {
+		vectors[id] = vecs[i]
+	}
+	return db.BatchAdd(vectors, metadata)
+}
+
+// SearchText embeds query and runs Search. topK is optional (default 10).
+func (db *VectorDB) SearchText(ctx context.Context, query string, topK ...int) (*SearchResult, error) {
+	vecs, err := db.embed(ctx, []string{query}, true)
+	if err != nil {
+		return nil, err
+	}
+	return db.Search(vecs[0], topK...)
+}
+
+// embed calls the configured Embedder and checks it returned one vector per text.
+// For queries it prefers QueryEmbedder.EmbedQuery.
+func (db *VectorDB) embed(ctx context.Context, texts []string, query bool) ([][]float32, error) {
+	db.mu.RLock()
+	e := db.embedder
+	db.mu.RUnlock()
+	if e == nil {
+		return nil, errNoEmbedder
+	}
+	embed := e.Embed
+	if qe, ok := e.(QueryEmbedder); ok && query {
+		embed = qe.EmbedQuery
+	}
+	vecs, err := embed(ctx, texts)
+	if err != nil {
+		return nil, fmt.Errorf("embed: %w", err)
+	}
+	if len(vecs) != len(texts) {
+		return nil, fmt.Errorf("embed: got %d vectors for %d texts", len(vecs), len(texts))
+	}
+	return vecs, nil
+}
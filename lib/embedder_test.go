@@ -0,0 +1,92 @@
+package lib
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// letterEmbedder maps a text to a 3-D vector by its first letter (a, b or other).
+var letterEmbedder = EmbedderFunc(func(_ context.Context, texts []string) ([][]float32, error) {
+	out := make([][]float32, len(texts))
+	for i, t := range texts {
+		switch {
+		case len(t) > 0 && t[0] == 'a':
+			out[i] = []float32{1, 0, 0}
+		case len(t) > 0 && t[0] == 'b':
+			out[i] = []float32{0, 1, 0}
+		default:
+			out[i] = []float32{0, 0, 1}
+		}
+	}
+	return out, nil
+})
+
+func TestAddTextSearchText(t *testing.T) {
+	ctx := context.Background()
+	db := NewVectorDB(3)
+	if err := db.AddText(ctx, "x", "apple"); err == nil {
+		t.Fatal("AddText without embedder must fail")
+	}
+	db.SetEmbedder(letterEmbedder)
+	if err := db.AddText(ctx, "x", "apple", VectorMetadata{Tags: map[string]string{"k": "v"}}); err != nil {
+		t.Fatalf("AddText failed: %v", err)
+	}
+	if err := db.BatchAddText(ctx, map[string]string{"y": "banana", "z": "cherry"}, nil); err != nil {
+		t.Fatalf("BatchAddText failed: %v", err)
+	}
+	if db.Size() != 3 {
+		t.Fatalf("expected 3 vectors, got %d", db.Size())
+	}
+	res, err := db.SearchText(ctx, "blueberry", 1)
+	if err != nil {
+		t.Fatalf("SearchText failed: %v", err)
+	}
+	if len(res.Results) != 1 || res.Results[0].ID != "y" {
+		t.Errorf("expected y, got %v", res.Results)
+	}
+}
+
+func TestEmbedder_Errors(t *testing.T) {
+	ctx := context.Background()
+	db := NewVectorDB(3)
+	db.SetEmbedder(EmbedderFunc(func(context.Context, []string) ([][]float32, error) {
+		return nil, errors.New("quota exceeded")
+	}))
+	if _, err := db.SearchText(ctx, "q"); err == nil {
+		t.Error("embedder error must be returned")
+	}
+	db.SetEmbedder(EmbedderFunc(func(context.Context, []string) ([][]float32, error) {
+		return [][]float32{}, nil
+	}))
+	if err := db.AddText(ctx, "x", "q"); err == nil {
+		t.Error("wrong vector count must be rejected")
+	}
+}
+
+// asymmetricEmbedder records which method was called.
+type asymmetricEmbedder struct{ docs, queries int }
+
+func (e *asymmetricEmbedder) Embed(_ context.Context, texts []string) ([][]float32, error) {
+	e.docs++
+	return letterEmbedder(nil, texts)
+}
+
+func (e *asymmetricEmbedder) EmbedQuery(_ context.Context, texts []string) ([][]float32, error) {
+	e.queries++
+	return letterEmbedder(nil, texts)
+}
+
+func TestSearchText_UsesQueryEmbedder(t *testing.T) {
+	ctx := context.Background()
+	e := &asymmetricEmbedder{}
+	db := NewVectorDB(3)
+	db.SetEmbedder(e)
+	_ = db.AddText(ctx, "a", "apple")
+	if _, err := db.SearchText(ctx, "avocado"); err != nil {
+		t.Fatalf("SearchText failed: %v", err)
+	}
+	if e.docs != 1 || e.queries != 1 {
+		t.Errorf("expected 1 document and 1 query embed, got %d/%d", e.docs, e.queries)
+	}
+}
@@ -0,0 +1,145 @@
+package lib
+
+import (
+	"slices"
+	"sync"
+)
+
+// EventOp is the kind of change an Event records.
+type EventOp int
+
+const (
+	EventAdd    EventOp = iota + 1 // A new ID was stored, or a soft-deleted vector restored.
+	EventUpdate                    // An existing ID got new data or metadata (including Add over it).
+	EventDelete                    // An ID was deleted or soft-deleted.
+	EventClear                     // Every vector was removed; ID and Vector are empty.
+)
+
+// String returns the op name ("add", "update", "delete", "clear").
+func (op EventOp) String() string {
+	switch op {
+	case EventAdd:
+		return "add"
+	case EventUpdate:
+		return "update"
+	case EventDelete:
+		return "delete"
+	case EventClear:
+		return "clear"
+	}
+	return "unknown"
+}
+
+// Event is one committed change to a VectorDB.
+type Event struct {
+	Seq    uint64 // 1 for the DB's first change, then increasing by 1 per change
+	Op     EventOp
+	ID     string
+	Vector *Vector // copy of the vector after the change; nil for EventDelete and EventClear
+}
+
+// eventLog numbers changes and delivers them to subscribers in order, outside the DB lock.
+type eventLog struct {
+	mu      sync.Mutex // guards the fields below
+	deliver sync.Mutex // held while calling subscribers
+	seq     uint64
+	nextID  int
+	subs    []subscriber
+	pending []Event
+}
+
+type subscriber struct {
+	id int
+	fn func(Event)
+}
+
+// Subscribe calls fn with every later Add, Update, Delete and Clear (including batches,
+// imports, SoftDelete, Restore and RevertTo), once the change is committed and the DB lock is
+// released, in Seq order and one event at a time. fn may call the DB, but a slow fn delays the
+// writers that deliver its events. The returned cancel stops delivery.
+func (db *VectorDB) Subscribe(fn func(Event)) (cancel func()) {
+	l := &db.events
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.nextID++
+	id := l.nextID
+	l.subs = append(l.subs, subscriber{id: id, fn: fn})
+	return func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		l.subs = slices.DeleteFunc(slices.Clone(l.subs), func(s subscriber) bool { return s.id == id })
+	}
+}
+
+// Seq returns the number of changes committed so far: the Seq of the latest Event, or 0.
+func (db *VectorDB) Seq() uint64 {
+	db.events.mu.Lock()
+	defer db.events.mu.Unlock()
+	return db.events.seq
+}
+
+// emitLocked records a change to v (nil for EventClear). Callers must hold the write lock.
+func (db *VectorDB) emitLocked(op EventOp, v *Vector) {
+	l := &db.events
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.seq++
+	if len(l.subs) == 0 {
+		return
+	}
+	e := Event{Seq: l.seq, Op: op}
+	if v != nil {
+		e.ID = v.ID
+	}
+	if v != nil && op != EventDelete {
+		e.Vector = &Vector{
+			ID:        v.ID,
+			Data:      slices.Clone(db.floats(v, nil)),
+			Metadata:  v.Metadata,
+			Dimension: v.Dimension,
+		}
+	}
+	l.pending = append(l.pending, e)
+}
+
+// unlock enforces the memory budget and releases the write lock, then delivers the events
+// and evicted vectors queued under it.
+func (db *VectorDB) unlock() {
+	db.evictLocked()
+	spilled := db.mem.spilled
+	db.mem.spilled = nil
+	db.mu.Unlock()
+	db.events.flush()
+	db.spill(spilled)
+}
+
+// flush delivers pending events unless another goroutine (or a subscriber calling back into
+// the DB) is already delivering; that one picks up whatever was queued in the meantime.
+func (l *eventLog) flush() {
+	for {
+		if !l.deliver.TryLock() {
+			return
+		}
+		for {
+			l.mu.Lock()
+			pending, subs := l.pending, l.subs
+			l.pending = nil
+			l.mu.Unlock()
+			if len(pending) == 0 {
+				break
+			}
+			for _, e := range pending {
+				for _, s := range subs {
+					s.fn(e)
+				}
+			}
+		}
+		l.deliver.Unlock()
+		l.mu.Lock()
+		done := len(l.pending) == 0
+		l.mu.Unlock()
+		if done {
+			return
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package lib
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// Explanation details how a result was scored. It is set on each result when
+// SearchOptions.Explain is true.
+type Explanation struct {
+	Distance   DistanceFunction // Metric the score was computed with.
+	Dot        float64          // Raw dot product of query and stored vector.
+	QueryNorm  float64          // L2 norm of the query.
+	VectorNorm float64          // L2 norm of the stored vector.
+	Similarity float64          // Score from the distance function alone (with SearchOptions.Weights).
+	Filters    []string         // Filters the vector passed, e.g. "price < 100".
+	Boosts     []string         // Score adjustments applied after Similarity, in order.
+}
+
+// SearchStats summarises the work a search did. It is set when SearchOptions.Explain is true.
+type SearchStats struct {
+	Candidates int  // Vectors visited after index and AllowIDs narrowing.
+	Matched    int  // Candidates that passed every filter and were scored.
+	Indexed    bool // Whether an index or AllowIDs narrowed the scan.
+	Rescored   int  // Candidates rescored at full dimension after a truncated scan (RescoreTop).
+	Stopped    bool // Whether SearchOptions.Epsilon ended the scan early.
+}
+
+// String renders c in a compact form such as `price < 100` or `lang in [en fr]`.
+func (c Condition) String() string {
+	switch c.Op {
+	case OpEq:
+		return fmt.Sprintf("%s = %v", c.Key, c.Value)
+	case OpGt:
+		return fmt.Sprintf("%s > %v", c.Key, c.Value)
+	case OpGte:
+		return fmt.Sprintf("%s >= %v", c.Key, c.Value)
+	case OpLt:
+		return fmt.Sprintf("%s < %v", c.Key, c.Value)
+	case OpLte:
+		return fmt.Sprintf("%s <= %v", c.Key, c.Value)
+	case OpIn:
+		return fmt.Sprintf("%s in %v", c.Key, c.Values)
+	case OpPrefix:
+		return fmt.Sprintf("%s prefix %q", c.Key, c.Value)
+	}
+	return fmt.Sprintf("%s op(%d) %v", c.Key, c.Op, c.Value)
+}
+
+// appliedFilters describes every filter in o, in the order they are checked.
+func (o *SearchOptions) appliedFilters() []string {
+	if o == nil {
+		return nil
+	}
+	var out []string
+	if o.AllowIDs != nil {
+		out = append(out, fmt.Sprintf("in AllowIDs (%d)", len(o.AllowIDs)))
+	}
+	if len(o.ExcludeIDs) > 0 {
+		out = append(out, fmt.Sprintf("not in ExcludeIDs (%d)", len(o.ExcludeIDs)))
+	}
+	for _, c := range o.Where {
+		out = append(out, c.String())
+	}
+	for _, k := range slices.Sorted(maps.Keys(o.Tags)) {
+		out = append(out, fmt.Sprintf("tag %s = %s", k, o.Tags[k]))
+	}
+	if o.Filter != nil {
+		out = append(out, "Filter func")
+	}
+	return out
+}
+
+// explain attaches an Explanation to each result. Callers must hold db.mu.
+func (db *VectorDB) explain(results []SimilarityResult, query32 []float32, opts *SearchOptions,
+	distance func(*Vector) float64, rescore func(*Vector, float64, *[]string) float64) {
+	filters := opts.appliedFilters()
+	qNorm := norm32(query32)
+	for i := range results {
+		v := db.vectors[results[i].ID]
+		data := db.floats(v, nil)
+		e := &Explanation{
+			Distance:   db.distFunc,
+			Dot:        dotProduct32(query32, data),
+			QueryNorm:  qNorm,
+			VectorNorm: norm32(data),
+			Similarity: distance(v),
+			Filters:    filters,
+		}
+		if rescore != nil {
+			rescore(v, e.Similarity, &e.Boosts)
+		}
+		results[i].Explanation = e
+	}
+}
@@ -106,6 +106,78 @@ func TestAPI_DistanceFloat32_ManhattanDistance(t *testing.T) {
 	}
 }
 
+func TestAPI_DistanceFloat32_JaccardAndTanimoto(t *testing.T) {
+	a := []float32{1, 1, 0, 1} // fingerprint bits {0,1,3}
+	b := []float32{1, 0, 1, 1} // fingerprint bits {0,2,3}
+	for _, df := range []DistanceFunction{JaccardSimilarity, TanimotoSimilarity} {
+		if got := DistanceFloat32(a, b, df); math.Abs(got-0.5) > 1e-9 {
+			t.Errorf("%v on bit sets: expected 2/4, got %f", df, got)
+		}
+		if got := DistanceFloat32(a, a, df); got != 1 {
+			t.Errorf("%v on identical vectors: expected 1, got %f", df, got)
+		}
+		if got := DistanceFloat32([]float32{0, 0}, []float32{0, 0}, df); got != 0 {
+			t.Errorf("%v on zero vectors: expected 0, got %f", df, got)
+		}
+	}
+	// Weighted Jaccard: Σmin / Σmax.
+	if got := DistanceFloat32([]float32{2, 1}, []float32{1, 3}, JaccardSimilarity); math.Abs(got-2.0/5) > 1e-9 {
+		t.Errorf("weighted jaccard: expected 0.4, got %f", got)
+	}
+}
+
+func TestAPI_DistanceFloat32_PearsonCorrelation(t *testing.T) {
+	a := []float32{1, 2, 3, 4}
+	if got := DistanceFloat32(a, []float32{12, 14, 16, 18}, PearsonCorrelation); math.Abs(got-1) > 1e-9 {
+		t.Errorf("pearson must ignore offset and scale: expected 1, got %f", got)
+	}
+	if got := DistanceFloat32(a, []float32{4, 3, 2, 1}, PearsonCorrelation); math.Abs(got+1) > 1e-9 {
+		t.Errorf("pearson reversed: expected -1, got %f", got)
+	}
+	if got := DistanceFloat32(a, []float32{5, 5, 5, 5}, PearsonCorrelation); got != 0 {
+		t.Errorf("pearson with constant vector: expected 0, got %f", got)
+	}
+}
+
+func TestAPI_DistanceFloat32_Divergences(t *testing.T) {
+	p := []float32{0.5, 0.5, 0}
+	q := []float32{0.25, 0.25, 0.5}
+	if got := DistanceFloat32(p, q, KLDivergence); math.Abs(got-math.Ln2) > 1e-6 {
+		t.Errorf("KL(p||q): expected ln 2, got %f", got)
+	}
+	if got := DistanceFloat32(q, p, KLDivergence); !math.IsInf(got, 1) {
+		t.Errorf("KL(q||p) with p = 0 where q > 0: expected +Inf, got %f", got)
+	}
+	if got := DistanceFloat32([]float32{1, 0}, []float32{0, 1}, JensenShannonDistance); math.Abs(got-1) > 1e-9 {
+		t.Errorf("JS of disjoint distributions: expected 1, got %f", got)
+	}
+	if got := DistanceFloat32(p, p, JensenShannonDistance); got != 0 {
+		t.Errorf("JS of identical distributions: expected 0, got %f", got)
+	}
+}
+
+func TestAPI_Divergence_ValidatesDistributions(t *testing.T) {
+	db := NewVectorDB(3, JensenShannonDistance)
+	if err := db.Add("neg", []float32{-0.5, 1, 0.5}); err == nil {
+		t.Error("negative component must be rejected")
+	}
+	if err := db.Add("sum", []float32{0.5, 0.5, 0.5}); err == nil {
+		t.Error("components not summing to 1 must be rejected")
+	}
+	_ = db.Add("a", []float32{0.7, 0.2, 0.1})
+	_ = db.Add("b", []float32{0.1, 0.2, 0.7})
+	if _, err := db.Search([]float32{1, 1, 1}, 1); err == nil {
+		t.Error("query that is not a distribution must be rejected")
+	}
+	res, err := db.Search([]float32{0.6, 0.3, 0.1}, 2)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if res.Results[0].ID != "a" || res.Results[0].Score >= res.Results[1].Score {
+		t.Errorf("lower divergence must rank first, got %+v", res.Results)
+	}
+}
+
 func TestAPI_DistanceFloat32_MismatchedLength(t *testing.T) {
 	a := []float32{1, 2}
 	b := []float32{1, 2, 3}
@@ -141,6 +213,11 @@ func TestAPI_DistanceFunction_String(t *testing.T) {
 		{DotProduct, "dot_product"},
 		{EuclideanDistance, "euclidean_distance"},
 		{ManhattanDistance, "manhattan_distance"},
+		{JaccardSimilarity, "jaccard_similarity"},
+		{TanimotoSimilarity, "tanimoto_similarity"},
+		{PearsonCorrelation, "pearson_correlation"},
+		{KLDivergence, "kl_divergence"},
+		{JensenShannonDistance, "jensen_shannon_distance"},
 	}
 	for _, tt := range tests {
 		got := tt.df.String()
@@ -0,0 +1,204 @@
+package lib
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"time"
+)
+
+// FAISSIndex is the content of a flat FAISS index read by ReadFAISS.
+type FAISSIndex struct {
+	Dimension int
+	Metric    DistanceFunction // DotProduct for IndexFlatIP, EuclideanDistance for IndexFlatL2.
+	IDs       []int64          // Labels from an IndexIDMap wrapper, or 0..n-1 for a bare index.
+	Vectors   [][]float32
+}
+
+// FAISS metric_type values.
+const (
+	faissMetricInnerProduct = 0
+	faissMetricL2           = 1
+)
+
+// maxFAISSElements bounds the element count read from a header. Values are read in blocks
+// of at most faissBlockValues, so a header claiming more than the file holds fails at the
+// end of the data instead of exhausting memory.
+const (
+	maxFAISSElements = 1 << 31
+	faissBlockValues = 1 << 20
+)
+
+// ReadFAISS reads a flat index written by faiss.write_index: IndexFlatIP, IndexFlatL2,
+// or either of them wrapped in IndexIDMap/IndexIDMap2.
+func ReadFAISS(r io.Reader) (*FAISSIndex, error) {
+	br := bufio.NewReader(r)
+	idx, err := readFAISSIndex(br)
+	if err != nil {
+		return nil, err
+	}
+	if idx.IDs == nil {
+		idx.IDs = make([]int64, len(idx.Vectors))
+		for i := range idx.IDs {
+			idx.IDs[i] = int64(i)
+		}
+	}
+	return idx, nil
+}
+
+// ImportFAISS bulk-loads a flat FAISS index. If ids is nil, each vector's FAISS label is
+// used as its ID in decimal form. The DB keeps its own distance function; create it with
+// DotProduct for IndexFlatIP or EuclideanDistance for IndexFlatL2 to keep the same ranking.
+func (db *VectorDB) ImportFAISS(r io.Reader, ids []string) (err error) {
+	defer db.logPersistence("faiss import", time.Now(), &err)
+	idx, err := ReadFAISS(r)
+	if err != nil {
+		return err
+	}
+	if ids == nil {
+		ids = make([]string, len(idx.IDs))
+		for i, label := range idx.IDs {
+			ids[i] = strconv.FormatInt(label, 10)
+		}
+	}
+	return db.importRows(idx.Vectors, ids, nil)
+}
+
+func readFAISSIndex(r io.Reader) (*FAISSIndex, error) {
+	var fourcc [4]byte
+	if _, err := io.ReadFull(r, fourcc[:]); err != nil {
+		return nil, fmt.Errorf("faiss: reading fourcc: %w", err)
+	}
+	switch h := string(fourcc[:]); h {
+	case "IxFI", "IxF2", "IxFl":
+		d, ntotal, metric, err := readFAISSHeader(r)
+		if err != nil {
+			return nil, err
+		}
+		n, err := readFAISSCount(r)
+		if err != nil {
+			return nil, err
+		}
+		if d <= 0 || n != uint64(d)*uint64(ntotal) {
+			return nil, fmt.Errorf("faiss: %d floats do not match %d vectors of dimension %d", n, ntotal, d)
+		}
+		vectors, err := readFAISSVectors(r, d, ntotal)
+		if err != nil {
+			return nil, err
+		}
+		idx := &FAISSIndex{Dimension: d, Vectors: vectors}
+		switch metric {
+		case faissMetricInnerProduct:
+			idx.Metric = DotProduct
+		case faissMetricL2:
+			idx.Metric = EuclideanDistance
+		default:
+			return nil, fmt.Errorf("faiss: unsupported metric type %d", metric)
+		}
+		return idx, nil
+	case "IxMp", "IxM2":
+		if _, _, _, err := readFAISSHeader(r); err != nil {
+			return nil, err
+		}
+		idx, err := readFAISSIndex(r)
+		if err != nil {
+			return nil, err
+		}
+		if idx.IDs != nil {
+			return nil, errors.New("faiss: nested IndexIDMap is not supported")
+		}
+		n, err := readFAISSCount(r)
+		if err != nil {
+			return nil, err
+		}
+		if n != uint64(len(idx.Vectors)) {
+			return nil, fmt.Errorf("faiss: id map has %d labels for %d vectors", n, len(idx.Vectors))
+		}
+		idx.IDs = make([]int64, 0, min(int(n), faissBlockValues))
+		buf := make([]byte, 8*min(int(n), 8192))
+		for i := 0; i < int(n); {
+			k := min(int(n)-i, len(buf)/8)
+			if _, err := io.ReadFull(r, buf[:k*8]); err != nil {
+				return nil, fmt.Errorf("faiss: reading id map: %w", err)
+			}
+			for j := range k {
+				idx.IDs = append(idx.IDs, int64(binary.LittleEndian.Uint64(buf[j*8:])))
+			}
+			i += k
+		}
+		return idx, nil
+	default:
+		return nil, fmt.Errorf("faiss: unsupported index type %q (only flat indexes can be imported)", h)
+	}
+}
+
+// readFAISSVectors reads ntotal little-endian float32 vectors of dimension d. Rows are carved
+// from blocks allocated as the data is read; a row longer than a block grows as it is read.
+func readFAISSVectors(r io.Reader, d, ntotal int) ([][]float32, error) {
+	out := make([][]float32, 0, min(ntotal, faissBlockValues/d+1))
+	buf := make([]byte, 4*min(d, 8192))
+	var block []float32
+	for i := range ntotal {
+		var row []float32
+		if d <= faissBlockValues {
+			if len(block) < d {
+				block = make([]float32, min(ntotal-i, faissBlockValues/d)*d)
+			}
+			row, block = block[:0:d], block[d:]
+		}
+		for j := 0; j < d; {
+			k := min(d-j, len(buf)/4)
+			if _, err := io.ReadFull(r, buf[:k*4]); err != nil {
+				return nil, fmt.Errorf("faiss: reading vector %d: %w", i, err)
+			}
+			for n := range k {
+				row = append(row, math.Float32frombits(binary.LittleEndian.Uint32(buf[n*4:])))
+			}
+			j += k
+		}
+		out = append(out, row)
+	}
+	return out, nil
+}
+
+// readFAISSHeader reads the common index header and returns (d, ntotal, metric).
+func readFAISSHeader(r io.Reader) (int, int, int32, error) {
+	var h struct {
+		D         int32
+		NTotal    int64
+		Dummy1    int64
+		Dummy2    int64
+		IsTrained uint8
+		Metric    int32
+	}
+	if err := binary.Read(r, binary.LittleEndian, &h); err != nil {
+		return 0, 0, 0, fmt.Errorf("faiss: reading header: %w", err)
+	}
+	if h.D < 0 || h.NTotal < 0 || h.NTotal > maxFAISSElements {
+		return 0, 0, 0, errors.New("faiss: invalid header")
+	}
+	if h.Metric > 1 {
+		// metric_arg follows for parametric metrics.
+		var arg float32
+		if err := binary.Read(r, binary.LittleEndian, &arg); err != nil {
+			return 0, 0, 0, fmt.Errorf("faiss: reading header: %w", err)
+		}
+	}
+	return int(h.D), int(h.NTotal), h.Metric, nil
+}
+
+// readFAISSCount reads the uint64 element count that prefixes FAISS vectors.
+func readFAISSCount(r io.Reader) (uint64, error) {
+	var n uint64
+	if err := binary.Read(r, binary.LittleEndian, &n); err != nil {
+		return 0, fmt.Errorf("faiss: reading vector size: %w", err)
+	}
+	if n > maxFAISSElements {
+		return 0, errors.New("faiss: vector size too large")
+	}
+	return n, nil
+}
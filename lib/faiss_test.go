@@ -0,0 +1,90 @@
+package lib
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"testing"
+)
+
+// encodeFAISSFlat builds an index the way faiss.write_index does for IndexFlatIP/L2.
+func encodeFAISSFlat(buf *bytes.Buffer, fourcc string, metric int32, d int, vecs ...[]float32) {
+	buf.WriteString(fourcc)
+	writeFAISSHeader(buf, d, len(vecs), metric)
+	_ = binary.Write(buf, binary.LittleEndian, uint64(d*len(vecs)))
+	for _, v := range vecs {
+		_ = binary.Write(buf, binary.LittleEndian, v)
+	}
+}
+
+func writeFAISSHeader(buf *bytes.Buffer, d, n int, metric int32) {
+	_ = binary.Write(buf, binary.LittleEndian, int32(d))
+	_ = binary.Write(buf, binary.LittleEndian, int64(n))
+	_ = binary.Write(buf, binary.LittleEndian, int64(1<<20))
+	_ = binary.Write(buf, binary.LittleEndian, int64(1<<20))
+	buf.WriteByte(1)
+	_ = binary.Write(buf, binary.LittleEndian, metric)
+}
+
+func TestReadFAISS_FlatL2(t *testing.T) {
+	var buf bytes.Buffer
+	encodeFAISSFlat(&buf, "IxF2", faissMetricL2, 2, []float32{1, 2}, []float32{3, 4})
+	idx, err := ReadFAISS(&buf)
+	if err != nil {
+		t.Fatalf("ReadFAISS failed: %v", err)
+	}
+	if idx.Dimension != 2 || idx.Metric != EuclideanDistance || len(idx.Vectors) != 2 {
+		t.Fatalf("unexpected index: %+v", idx)
+	}
+	if idx.Vectors[1][1] != 4 || idx.IDs[1] != 1 {
+		t.Errorf("unexpected contents: %+v", idx)
+	}
+}
+
+func TestImportFAISS_IDMap(t *testing.T) {
+	var buf bytes.Buffer
+	buf.WriteString("IxMp")
+	writeFAISSHeader(&buf, 2, 2, faissMetricInnerProduct)
+	encodeFAISSFlat(&buf, "IxFI", faissMetricInnerProduct, 2, []float32{1, 0}, []float32{0, 1})
+	_ = binary.Write(&buf, binary.LittleEndian, uint64(2))
+	_ = binary.Write(&buf, binary.LittleEndian, []int64{1001, 42})
+
+	db := NewVectorDB(2, DotProduct)
+	if err := db.ImportFAISS(bytes.NewReader(buf.Bytes()), nil); err != nil {
+		t.Fatalf("ImportFAISS failed: %v", err)
+	}
+	v, err := db.Get("42")
+	if err != nil || v.Data[1] != 1 {
+		t.Errorf("expected label 42 to map to [0 1]: %v %v", v, err)
+	}
+
+	named := NewVectorDB(2)
+	if err := named.ImportFAISS(bytes.NewReader(buf.Bytes()), []string{"a", "b"}); err != nil {
+		t.Fatalf("ImportFAISS with ids failed: %v", err)
+	}
+	if _, err := named.Get("b"); err != nil {
+		t.Errorf("expected caller IDs to be used: %v", err)
+	}
+}
+
+func TestReadFAISS_RejectsUnsupported(t *testing.T) {
+	if _, err := ReadFAISS(bytes.NewReader([]byte("IHNf...."))); err == nil {
+		t.Error("expected HNSW index to be rejected")
+	}
+	var buf bytes.Buffer
+	encodeFAISSFlat(&buf, "IxF2", faissMetricL2, 2, []float32{1, 2})
+	truncated := buf.Bytes()[:buf.Len()-2]
+	if _, err := ReadFAISS(bytes.NewReader(truncated)); err == nil {
+		t.Error("expected truncated index to be rejected")
+	}
+
+	// A header claiming 1<<31 vectors with no data behind it fails at the end of the data.
+	var huge bytes.Buffer
+	huge.WriteString("IxFI")
+	writeFAISSHeader(&huge, 1, 1<<31, faissMetricL2)
+	_ = binary.Write(&huge, binary.LittleEndian, uint64(1<<31))
+	if _, err := ReadFAISS(&huge); !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		t.Errorf("expected a count larger than the data to fail with EOF, got %v", err)
+	}
+}
@@ -0,0 +1,184 @@
+package lib
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// FilterOp is the comparison a Condition applies.
+type FilterOp int
+
+const (
+	OpEq     FilterOp = iota // value == Value
+	OpGt                     // value > Value
+	OpGte                    // value >= Value
+	OpLt                     // value < Value
+	OpLte                    // value <= Value
+	OpIn                     // value equals one of Values
+	OpPrefix                 // string value starts with Value
+)
+
+// Condition is a declarative metadata predicate for SearchOptions.Where. Key names an
+// attribute; when a vector has no attribute of that name its tag of the same name is used.
+// Numbers compare numerically, strings lexicographically, time.Time chronologically;
+// values of different kinds never match. A []string attribute matches if any element does.
+// Build conditions with Eq, Gt, Gte, Lt, Lte, In and Prefix.
+type Condition struct {
+	Key    string
+	Op     FilterOp
+	Value  any   // Operand for every Op except OpIn.
+	Values []any // Operands for OpIn.
+}
+
+// Eq matches metadata key equal to value.
+func Eq(key string, value any) Condition { return Condition{Key: key, Op: OpEq, Value: value} }
+
+// Gt matches metadata key greater than value.
+func Gt(key string, value any) Condition { return Condition{Key: key, Op: OpGt, Value: value} }
+
+// Gte matches metadata key greater than or equal to value.
+func Gte(key string, value any) Condition { return Condition{Key: key, Op: OpGte, Value: value} }
+
+// Lt matches metadata key less than value.
+func Lt(key string, value any) Condition { return Condition{Key: key, Op: OpLt, Value: value} }
+
+// Lte matches metadata key less than or equal to value.
+func Lte(key string, value any) Condition { return Condition{Key: key, Op: OpLte, Value: value} }
+
+// In matches metadata key equal to any of values.
+func In(key string, values ...any) Condition { return Condition{Key: key, Op: OpIn, Values: values} }
+
+// Prefix matches string metadata key starting with prefix.
+func Prefix(key, prefix string) Condition { return Condition{Key: key, Op: OpPrefix, Value: prefix} }
+
+// condition is a validated Condition with canonical operands.
+type condition struct {
+	key      string
+	op       FilterOp
+	operands []any
+	tagOnly  bool // from SearchOptions.Tags: compare the tag only
+}
+
+func (c Condition) compile() (condition, error) {
+	if c.Key == "" {
+		return condition{}, errors.New("filter key cannot be empty")
+	}
+	raw := []any{c.Value}
+	switch c.Op {
+	case OpEq, OpGt, OpGte, OpLt, OpLte:
+	case OpIn:
+		if len(c.Values) == 0 {
+			return condition{}, fmt.Errorf("filter %q: In needs at least one value", c.Key)
+		}
+		raw = c.Values
+	case OpPrefix:
+		if _, ok := c.Value.(string); !ok {
+			return condition{}, fmt.Errorf("filter %q: Prefix needs a string, got %T", c.Key, c.Value)
+		}
+	default:
+		return condition{}, fmt.Errorf("filter %q: unknown op %d", c.Key, c.Op)
+	}
+	operands := make([]any, len(raw))
+	for i, v := range raw {
+		nv, err := normalizeValue(v)
+		if err != nil {
+			return condition{}, fmt.Errorf("filter %q: %w", c.Key, err)
+		}
+		if _, ok := nv.([]string); ok {
+			return condition{}, fmt.Errorf("filter %q: operand cannot be []string", c.Key)
+		}
+		operands[i] = nv
+	}
+	return condition{key: c.Key, op: c.Op, operands: operands}, nil
+}
+
+// match reports whether v satisfies c.
+func (c *condition) match(v *Vector) bool {
+	if !c.tagOnly {
+		if a, ok := v.Metadata.Attributes[c.key]; ok {
+			if list, ok := a.([]string); ok {
+				for _, s := range list {
+					if c.matchValue(s) {
+						return true
+					}
+				}
+				return false
+			}
+			return c.matchValue(a)
+		}
+	}
+	if t, ok := v.Metadata.Tags[c.key]; ok {
+		return c.matchValue(t)
+	}
+	return false
+}
+
+// matchValue applies c to one canonical scalar value.
+func (c *condition) matchValue(v any) bool {
+	switch c.op {
+	case OpEq, OpIn:
+		for _, o := range c.operands {
+			if r, ok := compareValues(v, o); ok && r == 0 {
+				return true
+			}
+		}
+		return false
+	case OpPrefix:
+		s, ok := v.(string)
+		return ok && strings.HasPrefix(s, c.operands[0].(string))
+	}
+	r, ok := compareValues(v, c.operands[0])
+	if !ok {
+		return false
+	}
+	switch c.op {
+	case OpGt:
+		return r > 0
+	case OpGte:
+		return r >= 0
+	case OpLt:
+		return r < 0
+	default: // OpLte
+		return r <= 0
+	}
+}
+
+// equalityOperands returns the values an Eq/In condition can match exactly, for index lookups.
+func (c *condition) equalityOperands() ([]any, bool) {
+	if c.op == OpEq || c.op == OpIn {
+		return c.operands, true
+	}
+	return nil, false
+}
+
+// compareValues orders two canonical values of the same kind; ok is false for mixed kinds.
+func compareValues(a, b any) (int, bool) {
+	switch x := a.(type) {
+	case float64:
+		y, ok := b.(float64)
+		return cmp.Compare(x, y), ok
+	case string:
+		y, ok := b.(string)
+		return strings.Compare(x, y), ok
+	case time.Time:
+		y, ok := b.(time.Time)
+		return x.Compare(y), ok
+	case bool:
+		y, ok := b.(bool)
+		if !ok {
+			return 0, false
+		}
+		switch {
+		case x == y:
+			return 0, true
+		case y:
+			return -1, true
+		default:
+			return 1, true
+		}
+	}
+	return 0, false
+}
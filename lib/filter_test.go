@@ -0,0 +1,88 @@
+package lib
+
+import (
+	"testing"
+	"time"
+)
+
+func productDB(t *testing.T) *VectorDB {
+	t.Helper()
+	db := NewVectorDB(2)
+	add := func(id string, attrs map[string]any, tags map[string]string) {
+		if err := db.Add(id, []float32{1, 0}, VectorMetadata{Attributes: attrs, Tags: tags}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	add("cheap", map[string]any{"price": 10, "year": 2019, "labels": []string{"sale", "new"}}, map[string]string{"sku": "AB-1"})
+	add("mid", map[string]any{"price": 50.5, "year": 2020, "published": time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)}, map[string]string{"sku": "AB-2"})
+	add("pricey", map[string]any{"price": 200, "year": 2023, "published": time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)}, map[string]string{"sku": "CD-1"})
+	add("tagged", nil, map[string]string{"price": "cheap", "sku": "AB-3"}) // string tag: never matches numeric ranges
+	return db
+}
+
+func whereIDs(t *testing.T, db *VectorDB, conds ...Condition) map[string]bool {
+	t.Helper()
+	res, err := db.SearchWithOptions([]float32{1, 0}, 10, &SearchOptions{Where: conds})
+	if err != nil {
+		t.Fatalf("search failed: %v", err)
+	}
+	ids := make(map[string]bool, res.Total)
+	for _, r := range res.Results {
+		ids[r.ID] = true
+	}
+	return ids
+}
+
+func TestWhere_Operators(t *testing.T) {
+	for _, indexed := range []bool{false, true} {
+		db := productDB(t)
+		if indexed {
+			for _, k := range []string{"price", "year", "labels", "sku", "published"} {
+				_ = db.CreateTagIndex(k)
+			}
+		}
+		cases := []struct {
+			name  string
+			conds []Condition
+			want  []string
+		}{
+			{"lt", []Condition{Lt("price", 100)}, []string{"cheap", "mid"}},
+			{"range", []Condition{Gte("year", 2020), Lte("price", 200)}, []string{"mid", "pricey"}},
+			{"gt float", []Condition{Gt("price", 50.5)}, []string{"pricey"}},
+			{"in", []Condition{In("year", 2019, 2023)}, []string{"cheap", "pricey"}},
+			{"tag fallback", []Condition{Eq("price", "cheap")}, []string{"tagged"}},
+			{"prefix", []Condition{Prefix("sku", "AB-")}, []string{"cheap", "mid", "tagged"}},
+			{"list", []Condition{Eq("labels", "sale")}, []string{"cheap"}},
+			{"time", []Condition{Gt("published", time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC))}, []string{"pricey"}},
+			{"missing", []Condition{Eq("color", "red")}, nil},
+		}
+		for _, tc := range cases {
+			got := whereIDs(t, db, tc.conds...)
+			if len(got) != len(tc.want) {
+				t.Errorf("indexed=%v %s: got %v, want %v", indexed, tc.name, got, tc.want)
+				continue
+			}
+			for _, id := range tc.want {
+				if !got[id] {
+					t.Errorf("indexed=%v %s: missing %s in %v", indexed, tc.name, id, got)
+				}
+			}
+		}
+	}
+}
+
+func TestWhere_Invalid(t *testing.T) {
+	db := NewVectorDB(2)
+	for _, c := range []Condition{
+		{Op: OpEq, Value: 1},
+		In("k"),
+		{Key: "k", Op: OpPrefix, Value: 3},
+		Eq("k", []int{1}),
+		Eq("k", []string{"a"}),
+		{Key: "k", Op: FilterOp(99)},
+	} {
+		if _, err := db.SearchWithOptions([]float32{1, 0}, 10, &SearchOptions{Where: []Condition{c}}); err == nil {
+			t.Errorf("expected error for %+v", c)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package lib
+
+import (
+	"bufio"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// KNNGraph is the directed k-nearest-neighbour graph of a DB's vectors, built with
+// BuildKNNGraph, for community detection (e.g. Louvain or Leiden) or for drawing the
+// embedding space with tools such as Gephi or Graphviz.
+type KNNGraph struct {
+	Nodes []string                      // Every vector ID, in order.
+	Edges map[string][]SimilarityResult // Per ID, its k nearest other vectors, best first.
+	// LowerIsBetter reports whether edge scores are distances rather than similarities.
+	LowerIsBetter bool
+}
+
+// BuildKNNGraph links every vector to its k nearest others of the same dimension, scored by
+// the DB's distance. A vector with fewer than k others links to all of them. Every pair of
+// vectors is compared, so it is O(n²·d).
+func (db *VectorDB) BuildKNNGraph(k int) (*KNNGraph, error) {
+	if k <= 0 {
+		return nil, errors.New("k must be > 0")
+	}
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	live, neighbours, err := db.neighboursLocked(k)
+	if err != nil {
+		return nil, err
+	}
+	g := &KNNGraph{
+		Nodes:         make([]string, len(live)),
+		Edges:         make(map[string][]SimilarityResult, len(live)),
+		LowerIsBetter: db.distFunc.lowerIsBetter(),
+	}
+	for i, v := range live {
+		g.Nodes[i] = v.ID
+		g.Edges[v.ID] = neighbours[i]
+	}
+	return g, nil
+}
+
+// WriteDOT writes g in Graphviz DOT, with each edge's score as its weight attribute.
+func (g *KNNGraph) WriteDOT(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	bw.WriteString("digraph knn {\n")
+	for _, id := range g.Nodes {
+		fmt.Fprintf(bw, "  %s;\n", dotID(id))
+	}
+	for _, id := range g.Nodes {
+		for _, r := range g.Edges[id] {
+			fmt.Fprintf(bw, "  %s -> %s [weight=%s];\n", dotID(id), dotID(r.ID), formatScore(r.Score))
+		}
+	}
+	bw.WriteString("}\n")
+	return bw.Flush()
+}
+
+// WriteGraphML writes g in GraphML, with each edge's score as its "score" data.
+func (g *KNNGraph) WriteGraphML(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	bw.WriteString(xml.Header)
+	bw.WriteString(`<graphml xmlns="http://graphml.graphdrawing.org/xmlns">` + "\n")
+	bw.WriteString(`  <key id="score" for="edge" attr.name="score" attr.type="double"/>` + "\n")
+	bw.WriteString(`  <graph id="knn" edgedefault="directed">` + "\n")
+	for _, id := range g.Nodes {
+		fmt.Fprintf(bw, "    <node id=\"%s\"/>\n", xmlAttr(id))
+	}
+	for _, id := range g.Nodes {
+		for _, r := range g.Edges[id] {
+			fmt.Fprintf(bw, "    <edge source=\"%s\" target=\"%s\"><data key=\"score\">%s</data></edge>\n",
+				xmlAttr(id), xmlAttr(r.ID), formatScore(r.Score))
+		}
+	}
+	bw.WriteString("  </graph>\n</graphml>\n")
+	return bw.Flush()
+}
+
+func dotID(id string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(id) + `"`
+}
+
+func xmlAttr(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+func formatScore(f float64) string { return strconv.FormatFloat(f, 'g', -1, 64) }
@@ -0,0 +1,64 @@
+package lib
+
+import (
+	"bytes"
+	"encoding/xml"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestBuildKNNGraph(t *testing.T) {
+	db := NewVectorDB(2, EuclideanDistance)
+	_ = db.Add("a", []float32{0, 0})
+	_ = db.Add("b", []float32{1, 0})
+	_ = db.Add("c", []float32{3, 0})
+	_ = db.Add(`q"x`, []float32{10, 0})
+	g, err := db.BuildKNNGraph(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(g.Nodes, []string{"a", "b", "c", `q"x`}) || !g.LowerIsBetter {
+		t.Fatalf("unexpected graph %+v", g)
+	}
+	if e := g.Edges["c"]; len(e) != 2 || e[0].ID != "b" || e[0].Score != 2 || e[1].ID != "a" {
+		t.Errorf("c must link to b then a, got %+v", e)
+	}
+	if _, err := db.BuildKNNGraph(0); err == nil {
+		t.Error("expected an error for k = 0")
+	}
+
+	var dot bytes.Buffer
+	if err := g.WriteDOT(&dot); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(dot.String(), `"c" -> "b" [weight=2];`) || !strings.Contains(dot.String(), `"q\"x" -> "c" [weight=7];`) {
+		t.Errorf("unexpected DOT:\n%s", dot.String())
+	}
+
+	var gml bytes.Buffer
+	if err := g.WriteGraphML(&gml); err != nil {
+		t.Fatal(err)
+	}
+	var doc struct {
+		Graph struct {
+			Nodes []struct {
+				ID string `xml:"id,attr"`
+			} `xml:"node"`
+			Edges []struct {
+				Source string `xml:"source,attr"`
+				Target string `xml:"target,attr"`
+				Score  string `xml:"data"`
+			} `xml:"edge"`
+		} `xml:"graph"`
+	}
+	if err := xml.Unmarshal(gml.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.Graph.Nodes) != 4 || doc.Graph.Nodes[3].ID != `q"x` || len(doc.Graph.Edges) != 8 {
+		t.Fatalf("unexpected GraphML:\n%s", gml.String())
+	}
+	if e := doc.Graph.Edges[4]; e.Source != "c" || e.Target != "b" || e.Score != "2" {
+		t.Errorf("unexpected edge %+v", e)
+	}
+}
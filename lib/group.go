@@ -0,0 +1,79 @@
+package lib
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// groupCollector keeps the best results per metadata group for SearchOptions.GroupBy.
+type groupCollector struct {
+	key           string
+	size          int
+	lowerIsBetter bool
+	groups        map[string][]SimilarityResult // best first, at most size each
+}
+
+func newGroupCollector(key string, size int, lowerIsBetter bool) *groupCollector {
+	if size <= 0 {
+		size = 1
+	}
+	return &groupCollector{key: key, size: size, lowerIsBetter: lowerIsBetter, groups: make(map[string][]SimilarityResult)}
+}
+
+// add files r under v's group. Vectors without the key form a group of their own.
+func (g *groupCollector) add(v *Vector, r SimilarityResult) {
+	group, ok := groupValue(v, g.key)
+	k := "\x00" + v.ID
+	if ok {
+		r.Group, k = group, "\x01"+group
+	}
+	list := g.groups[k]
+	i := sort.Search(len(list), func(i int) bool { return ranksBefore(r, list[i], g.lowerIsBetter) })
+	if i >= g.size {
+		return
+	}
+	if len(list) < g.size {
+		list = append(list, SimilarityResult{})
+	}
+	copy(list[i+1:], list[i:])
+	list[i] = r
+	g.groups[k] = list
+}
+
+// page returns the results of groups [offset, offset+topK) ranked by their leading scores,
+// group by group, and whether more groups follow.
+func (g *groupCollector) page(offset, topK int) ([]SimilarityResult, bool) {
+	lists := make([][]SimilarityResult, 0, len(g.groups))
+	for _, list := range g.groups {
+		lists = append(lists, list)
+	}
+	sort.Slice(lists, func(i, j int) bool { return ranksBefore(lists[i][0], lists[j][0], g.lowerIsBetter) })
+	lists, more := page(lists, offset, topK)
+	results := make([]SimilarityResult, 0, len(lists))
+	for _, list := range lists {
+		results = append(results, list...)
+	}
+	return results, more
+}
+
+// groupValue returns v's value for key as a string: the attribute if set, else the tag.
+func groupValue(v *Vector, key string) (string, bool) {
+	if a, ok := v.Metadata.Attributes[key]; ok {
+		switch x := a.(type) {
+		case string:
+			return x, true
+		case float64:
+			return strconv.FormatFloat(x, 'g', -1, 64), true
+		case bool:
+			return strconv.FormatBool(x), true
+		case time.Time:
+			return x.Format(time.RFC3339Nano), true
+		case []string:
+			return strings.Join(x, ","), true
+		}
+	}
+	t, ok := v.Metadata.Tags[key]
+	return t, ok
+}
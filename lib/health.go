@@ -0,0 +1,69 @@
+package lib
+
+import (
+	"maps"
+	"slices"
+)
+
+// HealthStatus is what Health reports, for readiness probes and dashboards. It marshals to
+// JSON as is.
+type HealthStatus struct {
+	Ready    bool     `json:"ready"`              // No Problems: searches can be served.
+	Problems []string `json:"problems,omitempty"` // Why searches would fail, e.g. a missing covariance.
+
+	Vectors     int      `json:"vectors"`
+	SoftDeleted int      `json:"soft_deleted"`
+	TagIndexes  []string `json:"tag_indexes,omitempty"` // Keys with a tag index, sorted.
+	BinaryIndex bool     `json:"binary_index"`
+
+	// Memory pressure: vector memory as in GetStats against Options.MaxMemoryBytes. Pressure
+	// is their ratio, 0 when unbounded; writes evict vectors once it passes 1.
+	MemoryBytes    int64   `json:"memory_bytes"`
+	MaxMemoryBytes int64   `json:"max_memory_bytes,omitempty"`
+	MemoryPressure float64 `json:"memory_pressure"`
+	Evicted        int     `json:"evicted"`
+
+	AutoSnapshots []AutoSnapshotStatus `json:"auto_snapshots,omitempty"` // Running StartAutoSnapshot loops.
+}
+
+// AutoSnapshotStatus describes one running StartAutoSnapshot loop.
+type AutoSnapshotStatus struct {
+	Pending   uint64 `json:"pending"`              // Changes not yet saved: lost if the process dies now.
+	LastError string `json:"last_error,omitempty"` // Error of the last save, "" once one succeeds.
+}
+
+// Health reports whether the DB can serve searches, its indexes, memory pressure and the
+// changes its auto snapshots have yet to save. It takes the read lock briefly and never scans
+// vector data, so it is cheap enough for every health check.
+func (db *VectorDB) Health() HealthStatus {
+	db.mu.RLock()
+	h := HealthStatus{
+		Vectors:        len(db.vectors),
+		SoftDeleted:    len(db.trash),
+		TagIndexes:     slices.Sorted(maps.Keys(db.tagIndex)),
+		BinaryIndex:    db.binaryIndex,
+		MemoryBytes:    db.mem.used,
+		MaxMemoryBytes: db.mem.max,
+		Evicted:        db.mem.evicted,
+	}
+	if db.distFunc == MahalanobisDistance && db.covChol == nil && len(db.vectors) > 0 {
+		h.Problems = append(h.Problems, "MahalanobisDistance needs FitCovariance or SetCovariance")
+	}
+	db.mu.RUnlock()
+	if h.MaxMemoryBytes > 0 {
+		h.MemoryPressure = float64(h.MemoryBytes) / float64(h.MaxMemoryBytes)
+	}
+
+	seq := db.Seq()
+	db.bgMu.Lock()
+	for a := range db.autoSnapshots {
+		s := AutoSnapshotStatus{Pending: seq - min(a.saved.Load(), seq)}
+		if msg := a.lastErr.Load(); msg != nil {
+			s.LastError = *msg
+		}
+		h.AutoSnapshots = append(h.AutoSnapshots, s)
+	}
+	db.bgMu.Unlock()
+	h.Ready = len(h.Problems) == 0
+	return h
+}
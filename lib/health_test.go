@@ -0,0 +1,47 @@
+package lib
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestHealth(t *testing.T) {
+	db := NewVectorDBWithOptions(&Options{Dimension: 2, MaxMemoryBytes: 1 << 20})
+	_ = db.Add("a", []float32{1, 0}, VectorMetadata{Tags: map[string]string{"lang": "en"}})
+	_ = db.Add("b", []float32{0, 1})
+	_ = db.CreateTagIndex("lang")
+	h := db.Health()
+	if !h.Ready || h.Vectors != 2 || len(h.TagIndexes) != 1 || h.TagIndexes[0] != "lang" {
+		t.Errorf("unexpected health %+v", h)
+	}
+	if h.MemoryBytes <= 0 || h.MemoryPressure != float64(h.MemoryBytes)/(1<<20) {
+		t.Errorf("unexpected memory pressure %+v", h)
+	}
+
+	broken := FileStorage(filepath.Join(t.TempDir(), "missing", "db.parquet"))
+	a := db.StartAutoSnapshot(time.Hour, broken)
+	defer a.Stop(context.Background())
+	_ = db.Add("c", []float32{1, 1})
+	if err := a.Flush(context.Background()); err == nil {
+		t.Fatal("expected the save to a missing directory to fail")
+	}
+	h = db.Health()
+	if len(h.AutoSnapshots) != 1 || h.AutoSnapshots[0].Pending != 1 || h.AutoSnapshots[0].LastError == "" {
+		t.Errorf("unexpected auto snapshot status %+v", h.AutoSnapshots)
+	}
+	a.storage = &memStorage{}
+	if err := a.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if s := db.Health().AutoSnapshots[0]; s.Pending != 0 || s.LastError != "" {
+		t.Errorf("a successful save must clear the status, got %+v", s)
+	}
+
+	m := NewVectorDB(2, MahalanobisDistance)
+	_ = m.Add("a", []float32{1, 0})
+	if h := m.Health(); h.Ready || len(h.Problems) != 1 {
+		t.Errorf("a Mahalanobis DB without a covariance must not be ready, got %+v", h)
+	}
+}
@@ -0,0 +1,76 @@
+package lib
+
+import "time"
+
+// Hooks are called around DB operations for validation, enrichment, metrics or audit logging.
+// Nil fields are skipped. Hooks run without the DB lock, so they may call the DB. To follow
+// committed changes rather than attempts, use Subscribe.
+type Hooks struct {
+	// OnAdd is called with each vector that Add, BatchAdd or an import is about to store,
+	// before it is validated. It may change v.Data and v.Metadata; an error rejects the write
+	// (the whole batch for BatchAdd and imports).
+	OnAdd func(v *Vector) error
+
+	// OnUpdate is OnAdd for Update (RevertTo restores a stored version without it). When
+	// Update is given no metadata, v.Metadata holds the current metadata and the vector keeps what OnUpdate
+	// leaves there.
+	OnUpdate func(v *Vector) error
+
+	// OnDelete is called before Delete or SoftDelete removes id; an error keeps the vector.
+	OnDelete func(id string) error
+
+	// OnSearch is called before each search with the query and its options (which may be
+	// nil); an error fails the search.
+	OnSearch func(query []float32, topK int, opts *SearchOptions) error
+
+	// OnSearchDone is called after each search with its result or error and duration.
+	OnSearchDone func(res *SearchResult, err error, took time.Duration)
+}
+
+// SetHooks installs h, replacing any previous hooks; nil removes them.
+func (db *VectorDB) SetHooks(h *Hooks) {
+	db.hooks.Store(h)
+}
+
+// writeHook passes a vector about to be written to OnAdd (or OnUpdate), if set, and returns
+// its possibly changed data, mapped through Options.Transform, and metadata. metadata is the
+// caller's optional metadata.
+func (db *VectorDB) writeHook(update bool, id string, vec []float32, metadata []VectorMetadata) ([]float32, []VectorMetadata, error) {
+	h := db.hooks.Load()
+	var fn func(*Vector) error
+	if h != nil {
+		fn = h.OnAdd
+		if update {
+			fn = h.OnUpdate
+		}
+	}
+	if fn == nil {
+		vec, err := db.transformInput(vec)
+		return vec, metadata, err
+	}
+	v := &Vector{ID: id, Data: vec, Dimension: len(vec)}
+	if len(metadata) > 0 {
+		v.Metadata = metadata[0]
+	} else if update {
+		v.Metadata, _ = db.GetMetadata(id)
+	}
+	if err := fn(v); err != nil {
+		return nil, nil, err
+	}
+	var err error
+	if v.Metadata.Attributes, err = normalizeAttributes(v.Metadata.Attributes); err != nil {
+		return nil, nil, err
+	}
+	if v.Data, err = db.transformInput(v.Data); err != nil {
+		return nil, nil, err
+	}
+	return v.Data, []VectorMetadata{v.Metadata}, nil
+}
+
+// deleteHook runs OnDelete, if set.
+func (db *VectorDB) deleteHook(id string) error {
+	if h := db.hooks.Load(); h != nil && h.OnDelete != nil {
+		return h.OnDelete(id)
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package lib
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// IDGenerator returns a new vector ID for AddAuto. It must be safe for concurrent use.
+type IDGenerator func() string
+
+// NewUUIDv7 returns a random UUID version 7 (RFC 9562) in its 36-character text form. Its
+// first 48 bits are the Unix time in milliseconds, so IDs sort roughly by creation time.
+func NewUUIDv7() string {
+	var u [16]byte
+	_, _ = rand.Read(u[6:]) // never fails
+	ms := uint64(time.Now().UnixMilli())
+	binary.BigEndian.PutUint16(u[0:2], uint16(ms>>32))
+	binary.BigEndian.PutUint32(u[2:6], uint32(ms))
+	u[6] = u[6]&0x0f | 0x70 // version 7
+	u[8] = u[8]&0x3f | 0x80 // RFC 9562 variant
+	h := hex.EncodeToString(u[:])
+	return fmt.Sprintf("%s-%s-%s-%s-%s", h[:8], h[8:12], h[12:16], h[16:20], h[20:])
+}
+
+// AddAuto adds a vector under a new ID from Options.IDGenerator and returns the ID. It never
+// replaces an existing vector: if the generator repeats an ID, AddAuto fails.
+func (db *VectorDB) AddAuto(data any, metadata ...VectorMetadata) (string, error) {
+	gen := db.newID
+	if gen == nil {
+		gen = NewUUIDv7
+	}
+	id := gen()
+	if _, err := db.add("", id, data, metadata, true); err != nil {
+		return "", err
+	}
+	return id, nil
+}
@@ -0,0 +1,55 @@
+package lib
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewUUIDv7(t *testing.T) {
+	re := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-7[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	prev := ""
+	for range 100 {
+		id := NewUUIDv7()
+		if !re.MatchString(id) {
+			t.Fatalf("%s is not a UUIDv7", id)
+		}
+		if id == prev {
+			t.Fatal("IDs must be unique")
+		}
+		prev = id
+	}
+	before := NewUUIDv7()
+	time.Sleep(2 * time.Millisecond)
+	if after := NewUUIDv7(); strings.Compare(before, after) >= 0 {
+		t.Errorf("IDs must sort by time: %s then %s", before, after)
+	}
+}
+
+func TestAddAuto(t *testing.T) {
+	db := NewVectorDB(2)
+	id, err := db.AddAuto([]float32{1, 2}, VectorMetadata{Tags: map[string]string{"k": "v"}})
+	if err != nil || len(id) != 36 {
+		t.Fatalf("expected a UUID, got %q, %v", id, err)
+	}
+	if v, err := db.Get(id); err != nil || v.Metadata.Tags["k"] != "v" {
+		t.Errorf("the vector must be stored under the returned ID: %+v, %v", v, err)
+	}
+
+	n := 0
+	db = NewVectorDBWithOptions(&Options{Dimension: 2, IDGenerator: func() string {
+		n++
+		return "chunk-" + strings.Repeat("x", n%2)
+	}})
+	if id, _ := db.AddAuto([]float32{1, 2}); id != "chunk-x" {
+		t.Errorf("the custom generator must be used, got %q", id)
+	}
+	_, _ = db.AddAuto([]float32{1, 2})
+	if _, err := db.AddAuto([]float32{3, 4}); err == nil {
+		t.Error("a repeated ID must fail rather than replace the vector")
+	}
+	if _, err := db.AddAuto([]float32{1}); err == nil {
+		t.Error("an invalid vector must fail")
+	}
+}
@@ -0,0 +1,54 @@
+package lib
+
+import "errors"
+
+// IdempotencyTokens is how many of the latest AddIdempotent tokens a DB remembers.
+const IdempotencyTokens = 10000
+
+// tokenSet remembers the latest IdempotencyTokens tokens, forgetting the oldest first.
+type tokenSet struct {
+	seen  map[string]struct{}
+	order []string // ring of the tokens in seen, oldest at next once full
+	next  int
+}
+
+func (t *tokenSet) has(token string) bool {
+	_, ok := t.seen[token]
+	return ok
+}
+
+func (t *tokenSet) add(token string) {
+	if token == "" || t.has(token) {
+		return
+	}
+	if t.seen == nil {
+		t.seen = make(map[string]struct{})
+	}
+	if len(t.order) < IdempotencyTokens {
+		t.order = append(t.order, token)
+	} else {
+		delete(t.seen, t.order[t.next])
+		t.order[t.next] = token
+		t.next = (t.next + 1) % IdempotencyTokens
+	}
+	t.seen[token] = struct{}{}
+}
+
+// tokenSeen reports whether token was already applied.
+func (db *VectorDB) tokenSeen(token string) bool {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	return db.tokens.has(token)
+}
+
+// AddIdempotent is Add for at-least-once event delivery (SQS, Kinesis, EventBridge retries):
+// token identifies the event, e.g. its message ID, and a token already applied is ignored, so
+// a redelivered event neither adds twice nor overwrites a later Update of the same ID with
+// stale data. It reports whether the vector was stored. The latest IdempotencyTokens tokens
+// are remembered, in memory only; Clear keeps them.
+func (db *VectorDB) AddIdempotent(token, id string, data any, metadata ...VectorMetadata) (applied bool, err error) {
+	if token == "" {
+		return false, errors.New("idempotency token cannot be empty")
+	}
+	return db.add(token, id, data, metadata, false)
+}
@@ -0,0 +1,48 @@
+package lib
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestAddIdempotent(t *testing.T) {
+	db := NewVectorDB(2)
+	hooks := 0
+	db.SetHooks(&Hooks{OnAdd: func(*Vector) error { hooks++; return nil }})
+
+	if applied, err := db.AddIdempotent("msg-1", "a", []float32{1, 2}); !applied || err != nil {
+		t.Fatalf("first delivery must apply, got %v, %v", applied, err)
+	}
+	if err := db.Update("a", []float32{3, 4}); err != nil {
+		t.Fatal(err)
+	}
+	if applied, err := db.AddIdempotent("msg-1", "a", []float32{1, 2}); applied || err != nil {
+		t.Fatalf("a redelivery must be ignored, got %v, %v", applied, err)
+	}
+	if v, _ := db.Get("a"); v.Data[0] != 3 {
+		t.Errorf("a redelivery must not overwrite the later update, got %v", v.Data)
+	}
+	if hooks != 1 {
+		t.Errorf("a redelivery must not run OnAdd, ran %d times", hooks)
+	}
+
+	if applied, err := db.AddIdempotent("msg-2", "b", []float32{1}); applied || err == nil {
+		t.Fatal("an invalid vector must fail")
+	}
+	if applied, _ := db.AddIdempotent("msg-2", "b", []float32{1, 2}); !applied {
+		t.Error("a failed attempt must not consume its token")
+	}
+	if _, err := db.AddIdempotent("", "c", []float32{1, 2}); err == nil {
+		t.Error("an empty token must be rejected")
+	}
+}
+
+func TestTokenSet_ForgetsOldest(t *testing.T) {
+	var s tokenSet
+	for i := range IdempotencyTokens + 5 {
+		s.add(fmt.Sprint(i))
+	}
+	if s.has("4") || !s.has("5") || !s.has(fmt.Sprint(IdempotencyTokens+4)) || len(s.seen) != IdempotencyTokens {
+		t.Errorf("expected the oldest 5 tokens to be forgotten, kept %d", len(s.seen))
+	}
+}
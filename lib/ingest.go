@@ -0,0 +1,134 @@
+package lib
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Record is one vector sent to an Ingester.
+type Record struct {
+	ID       string
+	Data     any // as for Add
+	Metadata VectorMetadata
+}
+
+// IngestOptions configures Ingest. Nil or zero values use defaults.
+type IngestOptions struct {
+	BatchSize     int           // Records per commit. Default 500.
+	Buffer        int           // Capacity of Ingester.C. Default BatchSize.
+	FlushInterval time.Duration // Longest a record waits for its batch to fill. Default 100ms.
+
+	// OnError is called from the ingestion goroutine with the IDs of a batch that failed to
+	// commit. Errors are also returned by Close.
+	OnError func(ids []string, err error)
+}
+
+// Ingester commits records sent on C in batches; see Ingest.
+type Ingester struct {
+	// C receives records to store. Sends block only while the buffer is full.
+	C chan<- Record
+
+	db            *VectorDB
+	ch            chan Record
+	batchSize     int
+	flushInterval time.Duration
+	onError       func([]string, error)
+	done          chan struct{}
+	closeOnce     sync.Once
+	errs          []error // owned by run until done is closed
+}
+
+// Ingest starts a goroutine that stores the records sent on the returned Ingester's C with
+// BatchAdd, once BatchSize records are waiting or the oldest has waited FlushInterval, so
+// producers streaming embeddings take the write lock once per batch rather than per
+// vector. A batch is stored all or nothing; later records with the same ID replace earlier
+// ones. Call Close once every producer is done.
+func (db *VectorDB) Ingest(opts *IngestOptions) *Ingester {
+	if opts == nil {
+		opts = &IngestOptions{}
+	}
+	in := &Ingester{
+		db:            db,
+		batchSize:     opts.BatchSize,
+		flushInterval: opts.FlushInterval,
+		onError:       opts.OnError,
+		done:          make(chan struct{}),
+	}
+	if in.batchSize <= 0 {
+		in.batchSize = 500
+	}
+	if in.flushInterval <= 0 {
+		in.flushInterval = 100 * time.Millisecond
+	}
+	buffer := opts.Buffer
+	if buffer <= 0 {
+		buffer = in.batchSize
+	}
+	in.ch = make(chan Record, buffer)
+	in.C = in.ch
+	go in.run()
+	return in
+}
+
+func (in *Ingester) run() {
+	defer close(in.done)
+	timer := time.NewTimer(in.flushInterval)
+	timer.Stop()
+	var pending []Record
+	for {
+		select {
+		case r, ok := <-in.ch:
+			if !ok {
+				in.commit(pending)
+				return
+			}
+			pending = append(pending, r)
+			if len(pending) == 1 {
+				timer.Reset(in.flushInterval)
+			}
+			if len(pending) >= in.batchSize {
+				timer.Stop()
+				in.commit(pending)
+				pending = pending[:0]
+			}
+		case <-timer.C:
+			in.commit(pending)
+			pending = pending[:0]
+		}
+	}
+}
+
+// commit stores batch with BatchAdd, recording the error if it fails.
+func (in *Ingester) commit(batch []Record) {
+	if len(batch) == 0 {
+		return
+	}
+	vectors := make(map[string]any, len(batch))
+	metadata := make(map[string]VectorMetadata, len(batch))
+	for _, r := range batch {
+		vectors[r.ID] = r.Data
+		metadata[r.ID] = r.Metadata
+	}
+	err := in.db.BatchAdd(vectors, metadata)
+	if err == nil {
+		return
+	}
+	ids := make([]string, len(batch))
+	for i, r := range batch {
+		ids[i] = r.ID
+	}
+	in.errs = append(in.errs, fmt.Errorf("ingesting %d records: %w", len(batch), err))
+	if in.onError != nil {
+		in.onError(ids, err)
+	}
+}
+
+// Close closes C, waits for the records already sent to be committed and returns the errors
+// of the batches that failed. No records may be sent after Close.
+func (in *Ingester) Close() error {
+	in.closeOnce.Do(func() { close(in.ch) })
+	<-in.done
+	return errors.Join(in.errs...)
+}
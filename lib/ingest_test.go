@@ -0,0 +1,69 @@
+package lib
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestIngest_Batches(t *testing.T) {
+	db := NewVectorDB(2)
+	var mu sync.Mutex
+	adds := 0
+	db.SetHooks(&Hooks{OnAdd: func(*Vector) error {
+		mu.Lock()
+		defer mu.Unlock()
+		adds++
+		return nil
+	}})
+	in := db.Ingest(&IngestOptions{BatchSize: 10, FlushInterval: time.Hour})
+	var wg sync.WaitGroup
+	for p := range 3 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 10 {
+				in.C <- Record{ID: fmt.Sprintf("%d-%d", p, i), Data: []float32{float32(i), 1}, Metadata: VectorMetadata{Tags: map[string]string{"p": fmt.Sprint(p)}}}
+			}
+		}()
+	}
+	wg.Wait()
+	in.C <- Record{ID: "last", Data: []float32{1, 1}}
+	if err := in.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if db.Size() != 31 || adds != 31 {
+		t.Fatalf("expected 31 vectors, got %d (%d hook calls)", db.Size(), adds)
+	}
+	if meta, _ := db.GetMetadata("2-3"); meta.Tags["p"] != "2" {
+		t.Errorf("metadata must be stored, got %+v", meta)
+	}
+	if err := in.Close(); err != nil {
+		t.Errorf("a second Close must return the same result, got %v", err)
+	}
+}
+
+func TestIngest_FlushIntervalAndErrors(t *testing.T) {
+	db := NewVectorDB(2)
+	failed := make(chan []string, 1)
+	in := db.Ingest(&IngestOptions{BatchSize: 100, FlushInterval: time.Millisecond, OnError: func(ids []string, err error) {
+		failed <- ids
+	}})
+	in.C <- Record{ID: "a", Data: []float32{1, 2}}
+	deadline := time.Now().Add(time.Second)
+	for db.Size() == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if db.Size() != 1 {
+		t.Fatal("a partial batch must be committed after FlushInterval")
+	}
+
+	in.C <- Record{ID: "b", Data: []float32{1, 2, 3}}
+	if ids := <-failed; len(ids) != 1 || ids[0] != "b" {
+		t.Errorf("OnError must receive the failed batch, got %v", ids)
+	}
+	if err := in.Close(); err == nil {
+		t.Error("Close must return the failed batch's error")
+	}
+}
@@ -0,0 +1,93 @@
+package lib
+
+import (
+	"slices"
+	"time"
+)
+
+// limits holds the Options caps on what the DB stores; zero fields are unlimited.
+type limits struct {
+	vectors       int
+	dimension     int
+	metadataBytes int
+}
+
+// metadataBytes estimates the size of m's tags and attributes: the length of every key and
+// string, and 8 bytes for each number, bool or time.
+func metadataBytes(m VectorMetadata) int {
+	n := 0
+	for k, v := range m.Tags {
+		n += len(k) + len(v)
+	}
+	for k, v := range m.Attributes {
+		n += len(k)
+		switch x := v.(type) {
+		case string:
+			n += len(x)
+		case []string:
+			for _, s := range x {
+				n += len(s)
+			}
+		case float64, bool, time.Time:
+			n += 8
+		}
+	}
+	return n
+}
+
+// checkMetadata rejects metadata tagged with another model than Options.Model, or larger than
+// Options.MaxMetadataBytes.
+func (db *VectorDB) checkMetadata(m VectorMetadata) error {
+	if err := db.checkModel(m.Tags[ModelTag]); err != nil {
+		return err
+	}
+	if db.limits.metadataBytes <= 0 {
+		return nil
+	}
+	if n := metadataBytes(m); n > db.limits.metadataBytes {
+		return &QuotaError{Limit: "MaxMetadataBytes", Value: float64(n), Max: float64(db.limits.metadataBytes)}
+	}
+	return nil
+}
+
+// checkRoomLocked rejects storing added new IDs beyond Options.MaxVectors. Callers must hold
+// the write lock.
+func (db *VectorDB) checkRoomLocked(added int) error {
+	if n := len(db.vectors) + added; db.limits.vectors > 0 && n > db.limits.vectors {
+		return &QuotaError{Limit: "MaxVectors", Value: float64(n), Max: float64(db.limits.vectors)}
+	}
+	return nil
+}
+
+// checkLength rejects vectors longer than Options.MaxDimension.
+func (db *VectorDB) checkLength(v []float32) error {
+	if db.limits.dimension > 0 && len(v) > db.limits.dimension {
+		return &QuotaError{Limit: "MaxDimension", Value: float64(len(v)), Max: float64(db.limits.dimension)}
+	}
+	return nil
+}
+
+// checkBatchRoomLocked applies Options.MaxVectors to the new IDs in batch. Without a failed
+// map it rejects a batch that does not fit; with one it keeps as many new IDs as fit, in ID
+// order, and moves the rest to failed. Callers must hold the write lock.
+func (db *VectorDB) checkBatchRoomLocked(batch map[string]*Vector, failed map[string]error) error {
+	if db.limits.vectors <= 0 {
+		return nil
+	}
+	var added []string
+	for id := range batch {
+		if _, exists := db.vectors[id]; !exists {
+			added = append(added, id)
+		}
+	}
+	err := db.checkRoomLocked(len(added))
+	if err == nil || failed == nil {
+		return err
+	}
+	slices.Sort(added)
+	for _, id := range added[max(0, db.limits.vectors-len(db.vectors)):] {
+		failed[id] = err
+		delete(batch, id)
+	}
+	return nil
+}
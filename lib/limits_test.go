@@ -0,0 +1,53 @@
+package lib
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestLimits(t *testing.T) {
+	db := NewVectorDBWithOptions(&Options{MaxVectors: 3, MaxDimension: 2, MaxMetadataBytes: 16})
+	var qe *QuotaError
+	if err := db.Add("a", []float32{1, 2, 3}); !errors.As(err, &qe) || qe.Limit != "MaxDimension" {
+		t.Errorf("expected a MaxDimension error, got %v", err)
+	}
+	long := VectorMetadata{Tags: map[string]string{"description": "too long"}}
+	if err := db.Add("a", []float32{1, 2}, long); !errors.As(err, &qe) || qe.Limit != "MaxMetadataBytes" || qe.Value != 19 {
+		t.Errorf("expected a MaxMetadataBytes error, got %v", err)
+	}
+	_ = db.Add("a", []float32{1, 2}, VectorMetadata{Attributes: map[string]any{"year": 2024}})
+	if err := db.Update("a", []float32{1, 2}, long); !errors.As(err, &qe) {
+		t.Errorf("Update must check metadata, got %v", err)
+	}
+	if err := db.BatchAdd(map[string]any{"b": []float32{1, 0}, "c": []float32{0, 1}, "d": []float32{1, 1}}, nil); !errors.As(err, &qe) ||
+		qe.Limit != "MaxVectors" || !strings.Contains(err.Error(), "needs 4") {
+		t.Errorf("expected a MaxVectors error, got %v", err)
+	}
+	if db.Size() != 1 {
+		t.Fatalf("a rejected batch must store nothing, got %d vectors", db.Size())
+	}
+	failed, _ := db.BatchAddPartial(map[string]any{"a": []float32{2, 2}, "b": []float32{1, 0}, "c": []float32{0, 1}, "d": []float32{1, 1}}, nil)
+	if len(failed) != 1 || failed["d"] == nil || db.Size() != 3 {
+		t.Errorf("expected only d to be rejected, got %v and %d vectors", failed, db.Size())
+	}
+	if err := db.Add("e", []float32{1, 2}); !errors.As(err, &qe) || qe.Limit != "MaxVectors" {
+		t.Errorf("expected a MaxVectors error, got %v", err)
+	}
+	if err := db.Add("a", []float32{2, 1}); err != nil {
+		t.Errorf("replacing a stored ID must not count against MaxVectors: %v", err)
+	}
+
+	_ = db.SoftDelete("a")
+	_ = db.Add("e", []float32{1, 2})
+	if err := db.Restore("a"); !errors.As(err, &qe) {
+		t.Errorf("Restore must respect MaxVectors, got %v", err)
+	}
+
+	bulk := NewVectorDBWithOptions(&Options{MaxMetadataBytes: 16})
+	in := `{"id": "a", "vector": [1, 2], "tags": {"description": "too long"}}`
+	if err := bulk.BulkLoad(context.Background(), strings.NewReader(in), BulkJSONL, nil); !errors.As(err, &qe) {
+		t.Errorf("imports must check metadata, got %v", err)
+	}
+}
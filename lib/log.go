@@ -0,0 +1,35 @@
+package lib
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// log writes to Options.Logger, if set.
+func (db *VectorDB) log(level slog.Level, msg string, args ...any) {
+	if db.logger != nil {
+		db.logger.Log(context.Background(), level, msg, args...)
+	}
+}
+
+// logRejected logs a write that failed validation. err points at the method's result.
+func (db *VectorDB) logRejected(op, id string, err *error) {
+	if *err != nil {
+		db.log(slog.LevelWarn, "write rejected", "op", op, "id", id, "error", *err)
+	}
+}
+
+// logPersistence logs an import or export once it finishes. err points at the method's result.
+func (db *VectorDB) logPersistence(op string, start time.Time, err *error) {
+	if *err != nil {
+		db.log(slog.LevelError, op+" failed", "error", *err, "took", time.Since(start))
+		return
+	}
+	db.log(slog.LevelInfo, op, "vectors", db.Size(), "took", time.Since(start))
+}
+
+// logIndexBuild logs an index build or rebuild that started at start over n vectors.
+func (db *VectorDB) logIndexBuild(index string, n int, start time.Time, args ...any) {
+	db.log(slog.LevelInfo, "index built", append([]any{"index", index, "vectors", n, "took", time.Since(start)}, args...)...)
+}
@@ -0,0 +1,39 @@
+package lib
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOptions_Logger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	db := NewVectorDBWithOptions(&Options{Dimension: 2, Logger: logger, SlowSearch: time.Nanosecond})
+
+	_ = db.Add("a", []float32{1, 0})
+	_ = db.Add("b", []float32{1, 0, 0})
+	_ = db.CreateTagIndex("k")
+	_, _ = db.Search([]float32{1, 0}, 1)
+	var out bytes.Buffer
+	_ = db.ExportParquet(&out, nil)
+	_ = db.ImportNPY(strings.NewReader("not npy"), []string{"x"})
+
+	log := buf.String()
+	for _, want := range []string{
+		`level=WARN msg="write rejected" op=add id=b error="vector dimension 3 does not match expected 2"`,
+		`level=INFO msg="index built" index=tag vectors=1`,
+		`level=WARN msg="slow search"`,
+		`level=INFO msg="parquet export" vectors=1`,
+		`level=ERROR msg="npy import failed"`,
+	} {
+		if !strings.Contains(log, want) {
+			t.Errorf("missing %q in log:\n%s", want, log)
+		}
+	}
+	if strings.Contains(log, "id=a") {
+		t.Errorf("successful writes must not be logged:\n%s", log)
+	}
+}
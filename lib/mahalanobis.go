@@ -0,0 +1,164 @@
+package lib
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// covarianceRidge is added to the diagonal, relative to the mean variance, so that a covariance
+// fitted from fewer vectors than dimensions can still be inverted.
+const covarianceRidge = 1e-6
+
+// FitCovariance estimates the covariance of the stored vectors for MahalanobisDistance.
+// It is O(n·d²) and is not updated by later writes; call it again after large changes.
+func (db *VectorDB) FitCovariance() error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	start := time.Now()
+	if len(db.vectors) < 2 {
+		return errors.New("need at least 2 vectors to fit a covariance")
+	}
+	d := -1
+	mean := []float64(nil)
+	var buf []float32
+	for _, v := range db.vectors {
+		if d < 0 {
+			d = v.Dimension
+			mean = make([]float64, d)
+		} else if v.Dimension != d {
+			return fmt.Errorf("cannot fit a covariance over mixed dimensions %d and %d", d, v.Dimension)
+		}
+		for i, x := range db.floats(v, &buf) {
+			mean[i] += float64(x)
+		}
+	}
+	n := float64(len(db.vectors))
+	for i := range mean {
+		mean[i] /= n
+	}
+	cov := make([]float64, d*d)
+	dev := make([]float64, d)
+	for _, v := range db.vectors {
+		for i, x := range db.floats(v, &buf) {
+			dev[i] = float64(x) - mean[i]
+		}
+		for i := range d {
+			for j := 0; j <= i; j++ {
+				cov[i*d+j] += dev[i] * dev[j]
+			}
+		}
+	}
+	var trace float64
+	for i := range d {
+		for j := 0; j <= i; j++ {
+			cov[i*d+j] /= n - 1
+			cov[j*d+i] = cov[i*d+j]
+		}
+		trace += cov[i*d+i]
+	}
+	ridge := covarianceRidge * math.Max(trace/float64(d), 1e-12)
+	for i := range d {
+		cov[i*d+i] += ridge
+	}
+	if err := db.setCovarianceLocked(cov, d); err != nil {
+		return err
+	}
+	db.logIndexBuild("covariance", len(db.vectors), start, "dimension", d)
+	return nil
+}
+
+// SetCovariance uses cov, a symmetric positive-definite d×d matrix, for MahalanobisDistance
+// instead of fitting one.
+func (db *VectorDB) SetCovariance(cov [][]float64) error {
+	d := len(cov)
+	if d == 0 {
+		return errors.New("covariance cannot be empty")
+	}
+	flat := make([]float64, 0, d*d)
+	for i, row := range cov {
+		if len(row) != d {
+			return fmt.Errorf("covariance must be square: row %d has %d columns, want %d", i, len(row), d)
+		}
+		for j, x := range row {
+			if x != cov[j][i] {
+				return fmt.Errorf("covariance must be symmetric: [%d][%d] != [%d][%d]", i, j, j, i)
+			}
+		}
+		flat = append(flat, row...)
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if db.dimension > 0 && d != db.dimension {
+		return fmt.Errorf("covariance dimension %d does not match expected %d", d, db.dimension)
+	}
+	return db.setCovarianceLocked(flat, d)
+}
+
+// setCovarianceLocked stores the Cholesky factor of cov (row-major d×d). Callers must hold the
+// write lock.
+func (db *VectorDB) setCovarianceLocked(cov []float64, d int) error {
+	l, err := cholesky(cov, d)
+	if err != nil {
+		return err
+	}
+	db.covChol = l
+	return nil
+}
+
+// mahalanobisTo is distanceTo for MahalanobisDistance, which only scores whole, unweighted
+// vectors. Callers must hold db.mu.
+func (db *VectorDB) mahalanobisTo(query32 []float32, opts *SearchOptions, dims int) (func([]float32) float64, error) {
+	if db.covChol == nil {
+		return nil, errors.New("MahalanobisDistance needs FitCovariance or SetCovariance first")
+	}
+	if len(db.covChol) != len(query32)*len(query32) {
+		return nil, fmt.Errorf("query dimension %d does not match the covariance", len(query32))
+	}
+	if dims != len(query32) || opts != nil && opts.Weights != nil {
+		return nil, errors.New("MahalanobisDistance does not support Weights or Dimensions")
+	}
+	y := make([]float64, len(query32))
+	return func(v []float32) float64 { return mahalanobis(query32, v, db.covChol, y) }, nil
+}
+
+// cholesky returns the lower-triangular L with L·Lᵀ = a, or an error when a is not positive
+// definite.
+func cholesky(a []float64, d int) ([]float64, error) {
+	l := make([]float64, d*d)
+	for i := range d {
+		for j := 0; j <= i; j++ {
+			sum := a[i*d+j]
+			for k := range j {
+				sum -= l[i*d+k] * l[j*d+k]
+			}
+			if i == j {
+				if sum <= 0 || math.IsNaN(sum) {
+					return nil, errors.New("covariance is not positive definite")
+				}
+				l[i*d+i] = math.Sqrt(sum)
+			} else {
+				l[i*d+j] = sum / l[j*d+j]
+			}
+		}
+	}
+	return l, nil
+}
+
+// mahalanobis returns sqrt((a-b)ᵀ Σ⁻¹ (a-b)) for Σ = l·lᵀ by forward substitution, O(d²).
+// y is scratch space of len(a).
+func mahalanobis(a, b []float32, l, y []float64) float64 {
+	d := len(a)
+	var sum float64
+	for i := range d {
+		r := float64(a[i]) - float64(b[i])
+		row := l[i*d : i*d+i]
+		for k, lk := range row {
+			r -= lk * y[k]
+		}
+		y[i] = r / l[i*d+i]
+		sum += y[i] * y[i]
+	}
+	return math.Sqrt(sum)
+}
@@ -0,0 +1,60 @@
+package lib
+
+import (
+	"math"
+	"testing"
+)
+
+func TestMahalanobis_SetCovariance(t *testing.T) {
+	db := NewVectorDB(2, MahalanobisDistance)
+	_ = db.Add("x", []float32{3, 0})
+	_ = db.Add("y", []float32{0, 3})
+	if _, err := db.Search([]float32{0, 0}, 1); err == nil {
+		t.Fatal("search before a covariance is set must return error")
+	}
+	// Variance 9 along x, 1 along y: x is 1 deviation away, y is 3.
+	if err := db.SetCovariance([][]float64{{9, 0}, {0, 1}}); err != nil {
+		t.Fatalf("SetCovariance failed: %v", err)
+	}
+	res, err := db.Search([]float32{0, 0}, 2)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if res.Results[0].ID != "x" || math.Abs(res.Results[0].Score-1) > 1e-9 || math.Abs(res.Results[1].Score-3) > 1e-9 {
+		t.Errorf("expected x at 1 then y at 3, got %+v", res.Results)
+	}
+
+	for _, bad := range [][][]float64{
+		{{1, 0}},                          // not square
+		{{1, 2}, {0, 1}},                  // not symmetric
+		{{1, 2}, {2, 1}},                  // not positive definite
+		{{1, 0, 0}, {0, 1, 0}, {0, 0, 1}}, // wrong dimension
+	} {
+		if err := db.SetCovariance(bad); err == nil {
+			t.Errorf("SetCovariance(%v) must return error", bad)
+		}
+	}
+	if _, err := db.SearchWithOptions([]float32{0, 0}, 1, &SearchOptions{Weights: []float32{1, 1}}); err == nil {
+		t.Error("Weights with MahalanobisDistance must return error")
+	}
+}
+
+func TestMahalanobis_FitCovariance(t *testing.T) {
+	db := NewVectorDB(2, MahalanobisDistance)
+	// Points spread along the diagonal; the outlier sits off it.
+	for i, p := range [][]float32{{-2, -2.1}, {-1, -0.9}, {0, 0.1}, {1, 1.1}, {2, 1.9}, {3, 3}} {
+		_ = db.Add(string(rune('a'+i)), p)
+	}
+	_ = db.Add("outlier", []float32{1, -1})
+	if err := db.FitCovariance(); err != nil {
+		t.Fatalf("FitCovariance failed: %v", err)
+	}
+	// The outlier is nearer the origin than most points in Euclidean terms, but furthest here.
+	res, _ := db.Search([]float32{0, 0}, 7)
+	if res.Results[6].ID != "outlier" {
+		t.Errorf("expected outlier furthest, got %+v", res.Results)
+	}
+	if err := NewVectorDB(2).FitCovariance(); err == nil {
+		t.Error("FitCovariance on an empty DB must return error")
+	}
+}
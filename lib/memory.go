@@ -0,0 +1,101 @@
+package lib
+
+import (
+	"cmp"
+	"log/slog"
+	"maps"
+	"slices"
+	"sync/atomic"
+)
+
+// vectorOverhead is the estimated per-vector cost beyond its data: the struct, ID, metadata
+// and map entry.
+const vectorOverhead = 256
+
+// vectorMemory estimates the memory held by v, as reported by GetStats.
+func vectorMemory(v *Vector) int64 {
+	return int64(4*len(v.Data)+len(v.packed)) + vectorOverhead
+}
+
+// memoryBudget is the state behind Options.MaxMemoryBytes.
+type memoryBudget struct {
+	max     int64         // Options.MaxMemoryBytes; 0 is unbounded
+	used    int64         // vectorMemory of every vector holding an ordinal
+	onEvict func(*Vector) // Options.OnEvict
+	clock   atomic.Int64  // source of lastUsed stamps
+	evicted int           // total evictions, for GetStats
+	spilled []*Vector     // evicted copies waiting for onEvict
+	// lastUsed is the clock stamp of each ordinal's last use. Its length only changes under
+	// the write lock; its elements are set atomically under the read lock too.
+	lastUsed []int64
+}
+
+// touch marks v as just used. It is safe under the read lock.
+func (db *VectorDB) touch(v *Vector) {
+	if db.mem.max > 0 && v != nil {
+		atomic.StoreInt64(&db.mem.lastUsed[v.ord], db.mem.clock.Add(1))
+	}
+}
+
+// touchResults marks every vector in results as just used. It is safe under the read lock.
+func (db *VectorDB) touchResults(results []SimilarityResult) {
+	if db.mem.max == 0 {
+		return
+	}
+	for _, r := range results {
+		db.touch(db.vectors[r.ID])
+	}
+}
+
+// accountLocked adds v, which has just taken its ordinal, to the memory in use and marks it
+// as used. Callers must hold the write lock.
+func (db *VectorDB) accountLocked(v *Vector) {
+	db.mem.used += vectorMemory(v)
+	if db.mem.max == 0 {
+		return
+	}
+	if n := int(v.ord) + 1; len(db.mem.lastUsed) < n {
+		db.mem.lastUsed = append(db.mem.lastUsed, make([]int64, n-len(db.mem.lastUsed))...)
+	}
+	db.touch(v)
+}
+
+// evictLocked removes the least recently used vectors while the memory in use exceeds the
+// budget, down to 90% of it so that a write at the limit does not evict on every call.
+// Soft-deleted vectors count toward the budget but are left for Vacuum. Callers must hold the
+// write lock.
+func (db *VectorDB) evictLocked() {
+	if db.mem.max == 0 || db.mem.used <= db.mem.max {
+		return
+	}
+	target := db.mem.max / 10 * 9
+	victims := slices.SortedFunc(maps.Values(db.vectors), func(a, b *Vector) int {
+		return cmp.Compare(db.mem.lastUsed[a.ord], db.mem.lastUsed[b.ord])
+	})
+	n := 0
+	for _, v := range victims {
+		if db.mem.used <= target {
+			break
+		}
+		if db.mem.onEvict != nil {
+			db.mem.spilled = append(db.mem.spilled, &Vector{
+				ID:        v.ID,
+				Data:      slices.Clone(db.floats(v, nil)),
+				Metadata:  v.Metadata,
+				Dimension: v.Dimension,
+			})
+		}
+		db.removeLocked(v)
+		n++
+	}
+	db.mem.evicted += n
+	db.log(slog.LevelWarn, "memory budget exceeded", "evicted", n, "bytes", db.mem.used, "max_bytes", db.mem.max)
+}
+
+// spill hands the vectors evicted by the last write to Options.OnEvict. It runs after the
+// write lock is released so the callback may call back into the DB.
+func (db *VectorDB) spill(spilled []*Vector) {
+	for _, v := range spilled {
+		db.mem.onEvict(v)
+	}
+}
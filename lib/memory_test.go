@@ -0,0 +1,86 @@
+package lib
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestMaxMemoryBytes_EvictsLeastRecentlyUsed(t *testing.T) {
+	var mu sync.Mutex
+	var spilled []*Vector
+	per := vectorMemory(&Vector{Data: make([]float32, 4)})
+	db := NewVectorDBWithOptions(&Options{
+		Dimension:      4,
+		MaxMemoryBytes: 10 * per,
+		OnEvict: func(v *Vector) {
+			mu.Lock()
+			defer mu.Unlock()
+			spilled = append(spilled, v)
+		},
+	})
+	for i := range 10 {
+		if err := db.Add(fmt.Sprint(i), []float32{float32(i), 1, 0, 0}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if db.Size() != 10 || len(spilled) != 0 {
+		t.Fatalf("nothing should be evicted at the limit, got size %d", db.Size())
+	}
+	if _, err := db.Get("0"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.SearchWithOptions([]float32{1, 0, 0, 0}, 1, &SearchOptions{Filter: func(v *Vector) bool { return v.ID == "1" }}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := db.Add("10", []float32{10, 1, 0, 0}); err != nil {
+		t.Fatal(err)
+	}
+	// 11 vectors exceed the budget; eviction stops at 90% of it, i.e. 9 vectors.
+	if db.Size() != 9 {
+		t.Fatalf("expected 9 vectors after eviction, got %d", db.Size())
+	}
+	ids := make([]string, len(spilled))
+	for i, v := range spilled {
+		ids[i] = v.ID
+	}
+	if !slices.Equal(ids, []string{"2", "3"}) {
+		t.Fatalf("expected the least recently used 2 and 3 to be evicted, got %v", ids)
+	}
+	if !slices.Equal(spilled[0].Data, []float32{2, 1, 0, 0}) {
+		t.Errorf("evicted vector must carry its data, got %v", spilled[0].Data)
+	}
+	for _, id := range []string{"0", "1", "10"} {
+		if _, err := db.Get(id); err != nil {
+			t.Errorf("recently used %s must survive: %v", id, err)
+		}
+	}
+	stats := db.GetStats()
+	if stats["evicted"] != 2 || db.mem.used != 9*per {
+		t.Errorf("unexpected accounting: evicted %v, used %d", stats["evicted"], db.mem.used)
+	}
+
+	db.Clear()
+	if db.mem.used != 0 {
+		t.Errorf("Clear must reset memory in use, got %d", db.mem.used)
+	}
+}
+
+func TestMaxMemoryBytes_Accounting(t *testing.T) {
+	db := NewVectorDBWithOptions(&Options{Dimension: 2, Storage: Float16, MaxMemoryBytes: 1 << 20})
+	_ = db.Add("a", []float32{1, 2})
+	_ = db.BatchAdd(map[string]any{"b": []float32{3, 4}, "a": []float32{5, 6}}, nil)
+	_ = db.Update("b", []float32{7, 8})
+	_ = db.SoftDelete("b")
+	per := vectorMemory(&Vector{packed: make([]byte, 4)})
+	if db.mem.used != 2*per {
+		t.Fatalf("soft-deleted vectors hold memory until Vacuum: got %d, want %d", db.mem.used, 2*per)
+	}
+	db.Vacuum()
+	_ = db.Delete("a")
+	if db.mem.used != 0 {
+		t.Errorf("expected 0 bytes in use, got %d", db.mem.used)
+	}
+}
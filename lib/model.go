@@ -0,0 +1,20 @@
+package lib
+
+import "fmt"
+
+// ModelTag is the tag key naming the embedding model that produced a vector, checked against
+// Options.Model on every write.
+const ModelTag = "model"
+
+// Model returns the embedding model set with Options.Model, or "" if the DB accepts vectors
+// from any.
+func (db *VectorDB) Model() string { return db.model }
+
+// checkModel rejects a vector or query from another model than Options.Model. An empty model
+// is unknown and passes.
+func (db *VectorDB) checkModel(model string) error {
+	if db.model == "" || model == "" || model == db.model {
+		return nil
+	}
+	return fmt.Errorf("embedding model %q does not match the DB model %q", model, db.model)
+}
@@ -0,0 +1,357 @@
+package lib
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const npyMagic = "\x93NUMPY"
+
+// Bounds on what an untrusted .npy header can make the reader allocate before the data is
+// there: numpy writes headers of a few hundred bytes, and arrays are read in blocks of at
+// most npyBlockValues values, so a header claiming more than the file holds fails at the end
+// of the data instead of exhausting memory.
+const (
+	npyMaxHeader   = 64 << 10
+	npyBlockValues = 1 << 20
+)
+
+// npyHeader is the parsed header of a .npy array.
+type npyHeader struct {
+	descr string
+	shape []int
+}
+
+// ReadNPY reads a 1-D or 2-D NumPy .npy array of float32 or float64 values
+// and returns one []float32 per row. float64 input is narrowed to float32.
+func ReadNPY(r io.Reader) ([][]float32, error) {
+	br := bufio.NewReader(r)
+	h, err := readNPYHeader(br)
+	if err != nil {
+		return nil, err
+	}
+	rows, cols, err := npyMatrixShape(h.shape)
+	if err != nil {
+		return nil, err
+	}
+	var order binary.ByteOrder
+	switch h.descr[0] {
+	case '<', '|':
+		order = binary.LittleEndian
+	case '>':
+		order = binary.BigEndian
+	default:
+		return nil, fmt.Errorf("npy: unsupported byte order in dtype %q", h.descr)
+	}
+	size := 0
+	switch h.descr[1:] {
+	case "f4":
+		size = 4
+	case "f8":
+		size = 8
+	default:
+		return nil, fmt.Errorf("npy: unsupported dtype %q (use float32 or float64)", h.descr)
+	}
+
+	if cols == 0 && rows > 0 {
+		return nil, fmt.Errorf("npy: shape %v has empty rows", h.shape)
+	}
+	if cols > 0 && rows > math.MaxInt/cols/size {
+		return nil, fmt.Errorf("npy: shape %v is too large", h.shape)
+	}
+
+	// Rows are carved from blocks allocated as the data is read; a row longer than a block
+	// grows as it is read.
+	out := make([][]float32, 0, min(rows, npyBlockValues/max(cols, 1)+1))
+	buf := make([]byte, min(cols, 8192)*size)
+	var block []float32
+	for i := range rows {
+		var row []float32
+		if cols <= npyBlockValues {
+			if len(block) < cols {
+				block = make([]float32, min(rows-i, npyBlockValues/cols)*cols)
+			}
+			row, block = block[:0:cols], block[cols:]
+		}
+		for j := 0; j < cols; {
+			n := min(cols-j, len(buf)/size)
+			if _, err := io.ReadFull(br, buf[:n*size]); err != nil {
+				return nil, fmt.Errorf("npy: reading row %d: %w", i, err)
+			}
+			for k := range n {
+				if size == 4 {
+					row = append(row, math.Float32frombits(order.Uint32(buf[k*4:])))
+				} else {
+					row = append(row, float32(math.Float64frombits(order.Uint64(buf[k*8:]))))
+				}
+			}
+			j += n
+		}
+		out = append(out, row)
+	}
+	return out, nil
+}
+
+// ReadNPYStrings reads a 1-D NumPy unicode array (dtype '<U<n>'), as produced by
+// np.save("ids.npy", np.array(ids)).
+func ReadNPYStrings(r io.Reader) ([]string, error) {
+	br := bufio.NewReader(r)
+	h, err := readNPYHeader(br)
+	if err != nil {
+		return nil, err
+	}
+	if len(h.shape) != 1 {
+		return nil, fmt.Errorf("npy: string array must be 1-D, got shape %v", h.shape)
+	}
+	if len(h.descr) < 3 || h.descr[0] != '<' && h.descr[0] != '|' || h.descr[1] != 'U' {
+		return nil, fmt.Errorf("npy: unsupported string dtype %q (use little-endian unicode)", h.descr)
+	}
+	width, err := strconv.Atoi(h.descr[2:])
+	if err != nil || width <= 0 || width > npyBlockValues {
+		return nil, fmt.Errorf("npy: invalid string dtype %q", h.descr)
+	}
+	out := make([]string, 0, min(h.shape[0], npyBlockValues/width+1))
+	buf := make([]byte, width*4)
+	var sb strings.Builder
+	for i := range h.shape[0] {
+		if _, err := io.ReadFull(br, buf); err != nil {
+			return nil, fmt.Errorf("npy: reading string %d: %w", i, err)
+		}
+		sb.Reset()
+		for j := 0; j < width; j++ {
+			c := rune(binary.LittleEndian.Uint32(buf[j*4:]))
+			if c == 0 {
+				break
+			}
+			if !utf8.ValidRune(c) {
+				return nil, fmt.Errorf("npy: invalid code point in string %d", i)
+			}
+			sb.WriteRune(c)
+		}
+		out = append(out, sb.String())
+	}
+	return out, nil
+}
+
+// ImportNPY bulk-loads the rows of a .npy float32/float64 matrix using ids[i] for row i.
+// The whole file is validated before any vector is stored.
+func (db *VectorDB) ImportNPY(r io.Reader, ids []string) (err error) {
+	defer db.logPersistence("npy import", time.Now(), &err)
+	rows, err := ReadNPY(r)
+	if err != nil {
+		return err
+	}
+	return db.importRows(rows, ids, nil)
+}
+
+// ImportNPZ bulk-loads the array named name from a .npz archive (np.savez).
+// If ids is nil, IDs are read from an "ids" unicode array in the same archive.
+func (db *VectorDB) ImportNPZ(r io.ReaderAt, size int64, name string, ids []string) (err error) {
+	defer db.logPersistence("npz import", time.Now(), &err)
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return fmt.Errorf("npz: %w", err)
+	}
+	var rows [][]float32
+	found := false
+	for _, f := range zr.File {
+		key := strings.TrimSuffix(f.Name, ".npy")
+		if key != name && !(ids == nil && key == "ids") {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return fmt.Errorf("npz: %w", err)
+		}
+		if key == name {
+			rows, err = ReadNPY(rc)
+			found = true
+		} else {
+			ids, err = ReadNPYStrings(rc)
+		}
+		rc.Close()
+		if err != nil {
+			return err
+		}
+	}
+	if !found {
+		return fmt.Errorf("npz: array %q not found", name)
+	}
+	if ids == nil {
+		return errors.New("npz: no ids provided and archive has no ids array")
+	}
+	return db.importRows(rows, ids, nil)
+}
+
+// importRows validates rows and merges them in one write (see mergeBatch).
+// metas is optional; when set it must be parallel to rows. Zero timestamps are set to now.
+func (db *VectorDB) importRows(rows [][]float32, ids []string, metas []VectorMetadata) error {
+	if len(rows) != len(ids) {
+		return fmt.Errorf("got %d vectors but %d ids", len(rows), len(ids))
+	}
+	if len(rows) == 0 {
+		return errors.New("no vectors provided")
+	}
+	now := time.Now().Unix()
+	batch := make(map[string]*Vector, len(rows))
+	for i, row := range rows {
+		id := ids[i]
+		if id == "" {
+			return fmt.Errorf("vector ID at row %d cannot be empty", i)
+		}
+		if _, dup := batch[id]; dup {
+			return fmt.Errorf("duplicate vector ID %s", id)
+		}
+		var meta []VectorMetadata
+		if metas != nil {
+			meta = metas[i : i+1]
+		}
+		row, meta, err := db.writeHook(false, id, row, meta)
+		if err != nil {
+			return fmt.Errorf("vector %s: %w", id, err)
+		}
+		if len(meta) > 0 && meta[0].Attributes != nil {
+			m := meta[0] // normalize a copy; the caller's slice is left alone
+			if m.Attributes, err = normalizeAttributes(m.Attributes); err != nil {
+				return fmt.Errorf("vector %s: %w", id, err)
+			}
+			meta = []VectorMetadata{m}
+		}
+		if len(meta) > 0 {
+			if err := db.checkMetadata(meta[0]); err != nil {
+				return fmt.Errorf("vector %s: %w", id, err)
+			}
+		}
+		if len(row) == 0 {
+			return errors.New("vector data cannot be empty")
+		}
+		if err := db.checkVector(row); err != nil {
+			return fmt.Errorf("vector %s: %w", id, err)
+		}
+		vector := &Vector{ID: id, Data: row, Dimension: len(row)}
+		if len(meta) > 0 {
+			vector.Metadata = meta[0]
+		}
+		if vector.Metadata.CreatedAt == 0 { // kept when the source has them, e.g. a snapshot
+			vector.Metadata.CreatedAt = now
+		}
+		if vector.Metadata.UpdatedAt == 0 {
+			vector.Metadata.UpdatedAt = now
+		}
+		batch[id] = vector
+	}
+	return db.mergeBatch(batch, nil)
+}
+
+// readNPYHeader consumes the magic, version and header dict of a .npy stream.
+func readNPYHeader(r io.Reader) (*npyHeader, error) {
+	pre := make([]byte, 8)
+	if _, err := io.ReadFull(r, pre); err != nil {
+		return nil, fmt.Errorf("npy: reading magic: %w", err)
+	}
+	if string(pre[:6]) != npyMagic {
+		return nil, errors.New("npy: not a .npy file")
+	}
+	var hlen int
+	switch pre[6] {
+	case 1:
+		b := make([]byte, 2)
+		if _, err := io.ReadFull(r, b); err != nil {
+			return nil, fmt.Errorf("npy: reading header length: %w", err)
+		}
+		hlen = int(binary.LittleEndian.Uint16(b))
+	case 2, 3:
+		b := make([]byte, 4)
+		if _, err := io.ReadFull(r, b); err != nil {
+			return nil, fmt.Errorf("npy: reading header length: %w", err)
+		}
+		hlen = int(binary.LittleEndian.Uint32(b))
+	default:
+		return nil, fmt.Errorf("npy: unsupported format version %d.%d", pre[6], pre[7])
+	}
+	if hlen > npyMaxHeader {
+		return nil, fmt.Errorf("npy: header of %d bytes exceeds %d", hlen, npyMaxHeader)
+	}
+	hb := make([]byte, hlen)
+	if _, err := io.ReadFull(r, hb); err != nil {
+		return nil, fmt.Errorf("npy: reading header: %w", err)
+	}
+	return parseNPYHeader(string(hb))
+}
+
+// parseNPYHeader parses the Python dict literal, e.g.
+// {'descr': '<f4', 'fortran_order': False, 'shape': (3, 4), }
+func parseNPYHeader(s string) (*npyHeader, error) {
+	descr, ok := npyDictValue(s, "descr")
+	if !ok {
+		return nil, errors.New("npy: header missing descr")
+	}
+	descr = strings.Trim(descr, "'\"")
+	if len(descr) < 3 {
+		return nil, fmt.Errorf("npy: invalid dtype %q", descr)
+	}
+	if fo, ok := npyDictValue(s, "fortran_order"); ok && fo == "True" {
+		return nil, errors.New("npy: fortran_order arrays are not supported")
+	}
+	shapeStr, ok := npyDictValue(s, "shape")
+	if !ok {
+		return nil, errors.New("npy: header missing shape")
+	}
+	shapeStr = strings.Trim(shapeStr, "()")
+	var shape []int
+	for _, part := range strings.Split(shapeStr, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("npy: invalid shape %q", shapeStr)
+		}
+		shape = append(shape, n)
+	}
+	return &npyHeader{descr: descr, shape: shape}, nil
+}
+
+// npyDictValue returns the raw value text for key in a .npy header dict.
+func npyDictValue(s, key string) (string, bool) {
+	i := strings.Index(s, "'"+key+"'")
+	if i < 0 {
+		return "", false
+	}
+	rest := s[i+len(key)+2:]
+	j := strings.Index(rest, ":")
+	if j < 0 {
+		return "", false
+	}
+	rest = strings.TrimSpace(rest[j+1:])
+	end := strings.IndexAny(rest, ",}")
+	if strings.HasPrefix(rest, "(") {
+		end = strings.Index(rest, ")") + 1
+	}
+	if end <= 0 {
+		return "", false
+	}
+	return strings.TrimSpace(rest[:end]), true
+}
+
+// npyMatrixShape maps a .npy shape to (rows, cols); a 1-D array is a single row.
+func npyMatrixShape(shape []int) (int, int, error) {
+	switch len(shape) {
+	case 1:
+		return 1, shape[0], nil
+	case 2:
+		return shape[0], shape[1], nil
+	default:
+		return 0, 0, fmt.Errorf("npy: expected 1-D or 2-D array, got shape %v", shape)
+	}
+}
@@ -0,0 +1,170 @@
+package lib
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+)
+
+// encodeNPY builds a version 1.0 .npy file the way np.save does.
+func encodeNPY(descr string, shape string, payload []byte) []byte {
+	header := fmt.Sprintf("{'descr': '%s', 'fortran_order': False, 'shape': %s, }", descr, shape)
+	pad := 64 - (10+len(header)+1)%64
+	header += strings.Repeat(" ", pad%64) + "\n"
+	var buf bytes.Buffer
+	buf.WriteString(npyMagic)
+	buf.Write([]byte{1, 0})
+	_ = binary.Write(&buf, binary.LittleEndian, uint16(len(header)))
+	buf.WriteString(header)
+	buf.Write(payload)
+	return buf.Bytes()
+}
+
+func float32Payload(vals ...float32) []byte {
+	b := make([]byte, 4*len(vals))
+	for i, v := range vals {
+		binary.LittleEndian.PutUint32(b[i*4:], math.Float32bits(v))
+	}
+	return b
+}
+
+func float64Payload(vals ...float64) []byte {
+	b := make([]byte, 8*len(vals))
+	for i, v := range vals {
+		binary.LittleEndian.PutUint64(b[i*8:], math.Float64bits(v))
+	}
+	return b
+}
+
+func stringPayload(width int, vals ...string) []byte {
+	b := make([]byte, 4*width*len(vals))
+	for i, s := range vals {
+		for j, r := range []rune(s) {
+			binary.LittleEndian.PutUint32(b[(i*width+j)*4:], uint32(r))
+		}
+	}
+	return b
+}
+
+func TestReadNPY_Float32Matrix(t *testing.T) {
+	data := encodeNPY("<f4", "(2, 3)", float32Payload(1, 2, 3, 4, 5, 6))
+	rows, err := ReadNPY(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("ReadNPY failed: %v", err)
+	}
+	if len(rows) != 2 || len(rows[0]) != 3 {
+		t.Fatalf("expected 2x3, got %d rows", len(rows))
+	}
+	if rows[1][2] != 6 {
+		t.Errorf("expected rows[1][2]=6, got %v", rows[1][2])
+	}
+}
+
+func TestReadNPY_Float64Narrowed(t *testing.T) {
+	data := encodeNPY("<f8", "(1, 2)", float64Payload(0.5, -1.25))
+	rows, err := ReadNPY(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("ReadNPY failed: %v", err)
+	}
+	if rows[0][0] != 0.5 || rows[0][1] != -1.25 {
+		t.Errorf("unexpected values %v", rows[0])
+	}
+}
+
+func TestReadNPY_RejectsUnsupported(t *testing.T) {
+	if _, err := ReadNPY(bytes.NewReader(encodeNPY("<i4", "(1, 1)", make([]byte, 4)))); err == nil {
+		t.Error("expected int32 dtype to be rejected")
+	}
+	if _, err := ReadNPY(bytes.NewReader(encodeNPY("<f4", "(1, 1, 1)", make([]byte, 4)))); err == nil {
+		t.Error("expected 3-D shape to be rejected")
+	}
+	if _, err := ReadNPY(bytes.NewReader(encodeNPY("<f4", "(2, 2)", float32Payload(1, 2)))); err == nil {
+		t.Error("expected truncated payload to be rejected")
+	}
+	if _, err := ReadNPY(strings.NewReader("not numpy")); err == nil {
+		t.Error("expected bad magic to be rejected")
+	}
+}
+
+func TestReadNPY_RejectsMalformedHeader(t *testing.T) {
+	for _, shape := range []string{"(4611686018427387904, 4)", "(1, 9223372036854775807)"} {
+		if _, err := ReadNPY(bytes.NewReader(encodeNPY("<f4", shape, float32Payload(1, 2)))); err == nil {
+			t.Errorf("expected shape %s to be rejected", shape)
+		}
+	}
+	// A shape that fits in memory arithmetic but not in the file fails at the end of the data.
+	if _, err := ReadNPY(bytes.NewReader(encodeNPY("<f8", "(1000000000, 1000)", float64Payload(1, 2)))); err == nil {
+		t.Error("expected a shape larger than the data to be rejected")
+	}
+	if _, err := ReadNPYStrings(bytes.NewReader(encodeNPY("<U4", "(1000000000000,)", stringPayload(4, "a")))); err == nil {
+		t.Error("expected a string count larger than the data to be rejected")
+	}
+
+	var huge bytes.Buffer
+	huge.WriteString(npyMagic)
+	huge.Write([]byte{2, 0})
+	_ = binary.Write(&huge, binary.LittleEndian, uint32(1<<31))
+	if _, err := ReadNPY(&huge); err == nil || !strings.Contains(err.Error(), "header") {
+		t.Errorf("expected an oversized header length to be rejected, got %v", err)
+	}
+}
+
+func TestImportNPY(t *testing.T) {
+	db := NewVectorDB(2)
+	data := encodeNPY("<f4", "(2, 2)", float32Payload(1, 0, 0, 1))
+	if err := db.ImportNPY(bytes.NewReader(data), []string{"a", "b"}); err != nil {
+		t.Fatalf("ImportNPY failed: %v", err)
+	}
+	if db.Size() != 2 {
+		t.Fatalf("expected 2 vectors, got %d", db.Size())
+	}
+	v, _ := db.Get("b")
+	if v.Data[1] != 1 || v.Metadata.CreatedAt == 0 {
+		t.Errorf("unexpected vector b: %+v", v)
+	}
+
+	if err := db.ImportNPY(bytes.NewReader(data), []string{"only-one"}); err == nil {
+		t.Error("expected id count mismatch to fail")
+	}
+	if err := db.ImportNPY(bytes.NewReader(data), []string{"x", "x"}); err == nil {
+		t.Error("expected duplicate ids to fail")
+	}
+	wrongDim := encodeNPY("<f4", "(1, 3)", float32Payload(1, 2, 3))
+	if err := db.ImportNPY(bytes.NewReader(wrongDim), []string{"c"}); err == nil {
+		t.Error("expected dimension mismatch to fail")
+	}
+	if db.Size() != 2 {
+		t.Errorf("failed imports must not store vectors, size=%d", db.Size())
+	}
+}
+
+func TestImportNPZ_WithIDsArray(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	w, _ := zw.Create("embeddings.npy")
+	_, _ = w.Write(encodeNPY("<f4", "(2, 2)", float32Payload(1, 0, 0, 1)))
+	w, _ = zw.Create("ids.npy")
+	_, _ = w.Write(encodeNPY("<U5", "(2,)", stringPayload(5, "alpha", "beta")))
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	db := NewVectorDB(2)
+	r := bytes.NewReader(buf.Bytes())
+	if err := db.ImportNPZ(r, int64(r.Len()), "embeddings", nil); err != nil {
+		t.Fatalf("ImportNPZ failed: %v", err)
+	}
+	if _, err := db.Get("alpha"); err != nil {
+		t.Errorf("expected alpha to be imported: %v", err)
+	}
+	if _, err := db.Get("beta"); err != nil {
+		t.Errorf("expected beta to be imported: %v", err)
+	}
+	if err := db.ImportNPZ(r, int64(r.Len()), "missing", nil); err == nil {
+		t.Error("expected missing array to fail")
+	}
+}
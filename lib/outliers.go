@@ -0,0 +1,79 @@
+package lib
+
+import (
+	"errors"
+	"slices"
+	"strings"
+)
+
+// OutlierScores returns, for every vector, its mean distance to its k nearest neighbours of
+// the same dimension: the score itself for distances and 1 - score for similarities (the
+// cosine distance for CosineSimilarity). Mis-embedded or off-distribution records stand out
+// with the highest scores. A vector with fewer than k neighbours averages over those it has,
+// and one with none gets no score. Every pair of vectors is compared, so it is O(n²·d).
+func (db *VectorDB) OutlierScores(k int) (map[string]float64, error) {
+	if k <= 0 {
+		return nil, errors.New("k must be > 0")
+	}
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	live, neighbours, err := db.neighboursLocked(k)
+	if err != nil {
+		return nil, err
+	}
+	lowerIsBetter := db.distFunc.lowerIsBetter()
+	scores := make(map[string]float64, len(live))
+	for i, v := range live {
+		if len(neighbours[i]) == 0 {
+			continue
+		}
+		var sum float64
+		for _, r := range neighbours[i] {
+			if lowerIsBetter {
+				sum += r.Score
+			} else {
+				sum += 1 - r.Score
+			}
+		}
+		scores[v.ID] = sum / float64(len(neighbours[i]))
+	}
+	return scores, nil
+}
+
+// neighboursLocked returns the live vectors sorted by ID and, for each, its k nearest other
+// vectors of the same dimension, best first. Callers must hold the read lock.
+func (db *VectorDB) neighboursLocked(k int) ([]*Vector, [][]SimilarityResult, error) {
+	var live []*Vector
+	for _, v := range db.byOrd {
+		if v != nil && !v.deleted {
+			live = append(live, v)
+		}
+	}
+	slices.SortFunc(live, func(a, b *Vector) int { return strings.Compare(a.ID, b.ID) })
+
+	lowerIsBetter := db.distFunc.lowerIsBetter()
+	neighbours := make([][]SimilarityResult, len(live))
+	var buf []float32
+	for i, a := range live {
+		query := db.floatsCopy(a)
+		score, err := db.distanceTo(query, nil, len(query))
+		if err != nil {
+			return nil, nil, err
+		}
+		h := &resultHeap{lowerIsBetter: lowerIsBetter}
+		for j, b := range live {
+			if j == i || b.Dimension != a.Dimension {
+				continue
+			}
+			h.offer(SimilarityResult{ID: b.ID, Score: score(db.floats(b, &buf))}, k)
+		}
+		slices.SortFunc(h.results, func(x, y SimilarityResult) int {
+			if ranksBefore(x, y, lowerIsBetter) {
+				return -1
+			}
+			return 1
+		})
+		neighbours[i] = h.results
+	}
+	return live, neighbours, nil
+}
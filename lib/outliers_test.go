@@ -0,0 +1,40 @@
+package lib
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestOutlierScores(t *testing.T) {
+	db := NewVectorDB(2, EuclideanDistance)
+	for i := range 10 {
+		_ = db.Add(fmt.Sprint("p", i), []float32{float32(i % 3), float32(i / 3)})
+	}
+	_ = db.Add("outlier", []float32{20, 20})
+	scores, err := db.OutlierScores(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(scores) != 11 {
+		t.Fatalf("expected a score per vector, got %d", len(scores))
+	}
+	for id, s := range scores {
+		if id != "outlier" && s >= scores["outlier"] {
+			t.Errorf("%s scores %v, not below the outlier's %v", id, s, scores["outlier"])
+		}
+	}
+	if math.Abs(scores["p0"]-(1+1+math.Sqrt2)/3) > 1e-6 {
+		t.Errorf("p0 must average its 3 nearest distances, got %v", scores["p0"])
+	}
+
+	cos := NewVectorDB(2)
+	_ = cos.Add("a", []float32{1, 0})
+	_ = cos.Add("b", []float32{0, 1})
+	if s, _ := cos.OutlierScores(5); math.Abs(s["a"]-1) > 1e-6 {
+		t.Errorf("similarities must be turned into distances, got %v", s)
+	}
+	if s, _ := NewVectorDB(2).OutlierScores(1); len(s) != 0 {
+		t.Errorf("an empty DB has no scores, got %v", s)
+	}
+}
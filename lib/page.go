@@ -0,0 +1,55 @@
+package lib
+
+import (
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// cursorPrefix versions the cursor format so it can change without misreading old cursors.
+const cursorPrefix = "o1:"
+
+var errInvalidCursor = errors.New("invalid search cursor")
+
+// encodeCursor returns the opaque cursor for the page starting at offset.
+func encodeCursor(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(cursorPrefix + strconv.Itoa(offset)))
+}
+
+func decodeCursor(cursor string) (int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, errInvalidCursor
+	}
+	s, ok := strings.CutPrefix(string(raw), cursorPrefix)
+	if !ok {
+		return 0, errInvalidCursor
+	}
+	offset, err := strconv.Atoi(s)
+	if err != nil || offset < 0 {
+		return 0, errInvalidCursor
+	}
+	return offset, nil
+}
+
+// offset returns the number of ranked results (groups with GroupBy) to skip.
+func (o *SearchOptions) offset() (int, error) {
+	if o == nil {
+		return 0, nil
+	}
+	if o.Cursor != "" {
+		return decodeCursor(o.Cursor)
+	}
+	if o.Offset < 0 {
+		return 0, errors.New("offset must be >= 0")
+	}
+	return o.Offset, nil
+}
+
+// page cuts the window [offset, offset+topK) from ranked and reports whether more follow.
+func page[T any](ranked []T, offset, topK int) ([]T, bool) {
+	start := min(offset, len(ranked))
+	end := start + min(topK, len(ranked)-start) // offset+topK may overflow
+	return ranked[start:end], end < len(ranked)
+}
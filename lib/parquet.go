@@ -0,0 +1,955 @@
+package lib
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/bits"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParquetOptions names the columns used by ImportParquet and ExportParquet. Nil uses defaults.
+type ParquetOptions struct {
+	IDColumn     string // String column holding vector IDs. Default "id".
+	VectorColumn string // list<float> or list<double> column. Default "embedding".
+}
+
+func (o *ParquetOptions) columns() (string, string) {
+	idCol, vecCol := "id", "embedding"
+	if o != nil && o.IDColumn != "" {
+		idCol = o.IDColumn
+	}
+	if o != nil && o.VectorColumn != "" {
+		vecCol = o.VectorColumn
+	}
+	return idCol, vecCol
+}
+
+// Metadata columns ExportParquet writes after the tag columns and ImportParquet reads back
+// into VectorMetadata rather than tags, so snapshots keep more than tags.
+const (
+	parquetCreatedCol    = "_created_at" // required INT64, Unix seconds
+	parquetUpdatedCol    = "_updated_at" // required INT64, Unix seconds
+	parquetAttributesCol = "_attributes" // optional UTF8, attributes as JSON (see marshalAttributes)
+)
+
+// Parquet enums (parquet.thrift) used by the reader and writer.
+const (
+	parquetMagic = "PAR1"
+
+	pqBoolean   = 0
+	pqInt32     = 1
+	pqInt64     = 2
+	pqFloat     = 4
+	pqDouble    = 5
+	pqByteArray = 6
+
+	pqRequired = 0
+	pqOptional = 1
+	pqRepeated = 2
+
+	pqConvertedUTF8 = 0
+	pqConvertedList = 3
+
+	pqEncPlain         = 0
+	pqEncPlainDict     = 2
+	pqEncRLE           = 3
+	pqEncRLEDictionary = 8
+
+	pqCodecUncompressed = 0
+	pqCodecSnappy       = 1
+	pqCodecGzip         = 2
+
+	pqPageData       = 0
+	pqPageDictionary = 2
+	pqPageDataV2     = 3
+)
+
+// ExportParquet writes all vectors as a Parquet file with an ID column, a list<float>
+// vector column, one optional string column per tag key, and the _created_at and
+// _updated_at (INT64 Unix seconds) and _attributes (JSON) columns. Rows are ordered by ID.
+// Output is uncompressed and PLAIN-encoded so any Parquet reader (Spark, DuckDB, pyarrow) can load it.
+func (db *VectorDB) ExportParquet(w io.Writer, opts *ParquetOptions) (err error) {
+	defer db.logPersistence("parquet export", time.Now(), &err)
+	return db.exportParquet(w, opts)
+}
+
+func (db *VectorDB) exportParquet(w io.Writer, opts *ParquetOptions) error {
+	idCol, vecCol := opts.columns()
+
+	db.mu.RLock()
+	snap := make([]Vector, 0, len(db.vectors))
+	for _, v := range db.vectors {
+		c := *v
+		c.Data = db.floatsCopy(v)
+		snap = append(snap, c)
+	}
+	db.mu.RUnlock()
+	sort.Slice(snap, func(i, j int) bool { return snap[i].ID < snap[j].ID })
+
+	keySet := make(map[string]struct{})
+	for _, v := range snap {
+		for k := range v.Metadata.Tags {
+			keySet[k] = struct{}{}
+		}
+	}
+	tagKeys := make([]string, 0, len(keySet))
+	for k := range keySet {
+		if k == idCol || k == vecCol || k == parquetCreatedCol || k == parquetUpdatedCol || k == parquetAttributesCol {
+			return fmt.Errorf("parquet: tag key %q collides with a reserved column name", k)
+		}
+		tagKeys = append(tagKeys, k)
+	}
+	sort.Strings(tagKeys)
+
+	pw := &parquetWriter{w: w}
+	pw.write([]byte(parquetMagic))
+
+	// ID column: required UTF8 byte array.
+	var vals bytes.Buffer
+	for _, v := range snap {
+		appendPlainString(&vals, v.ID)
+	}
+	pw.writeChunk([]string{idCol}, pqByteArray, len(snap), vals.Bytes())
+
+	// Vector column: 3-level list, max repetition and definition level 1.
+	vals.Reset()
+	var reps, defs []int32
+	for _, v := range snap {
+		for i, x := range v.Data {
+			rep := int32(1)
+			if i == 0 {
+				rep = 0
+			}
+			reps = append(reps, rep)
+			defs = append(defs, 1)
+			_ = binary.Write(&vals, binary.LittleEndian, math.Float32bits(x))
+		}
+	}
+	var page bytes.Buffer
+	appendLevels(&page, reps)
+	appendLevels(&page, defs)
+	page.Write(vals.Bytes())
+	pw.writeChunk([]string{vecCol, "list", "element"}, pqFloat, len(defs), page.Bytes())
+
+	// Tag columns: optional UTF8 byte arrays, null where the tag is absent.
+	for _, key := range tagKeys {
+		vals.Reset()
+		page.Reset()
+		defs = defs[:0]
+		for _, v := range snap {
+			s, ok := v.Metadata.Tags[key]
+			if !ok {
+				defs = append(defs, 0)
+				continue
+			}
+			defs = append(defs, 1)
+			appendPlainString(&vals, s)
+		}
+		appendLevels(&page, defs)
+		page.Write(vals.Bytes())
+		pw.writeChunk([]string{key}, pqByteArray, len(snap), page.Bytes())
+	}
+
+	// Timestamps: required INT64s.
+	for _, col := range []string{parquetCreatedCol, parquetUpdatedCol} {
+		vals.Reset()
+		for _, v := range snap {
+			ts := v.Metadata.CreatedAt
+			if col == parquetUpdatedCol {
+				ts = v.Metadata.UpdatedAt
+			}
+			_ = binary.Write(&vals, binary.LittleEndian, ts)
+		}
+		pw.writeChunk([]string{col}, pqInt64, len(snap), vals.Bytes())
+	}
+
+	// Attributes: optional JSON, null without attributes.
+	vals.Reset()
+	page.Reset()
+	defs = defs[:0]
+	for _, v := range snap {
+		if len(v.Metadata.Attributes) == 0 {
+			defs = append(defs, 0)
+			continue
+		}
+		data, err := marshalAttributes(v.Metadata.Attributes)
+		if err != nil {
+			return fmt.Errorf("parquet: vector %s: %w", v.ID, err)
+		}
+		defs = append(defs, 1)
+		appendPlainString(&vals, string(data))
+	}
+	appendLevels(&page, defs)
+	page.Write(vals.Bytes())
+	pw.writeChunk([]string{parquetAttributesCol}, pqByteArray, len(snap), page.Bytes())
+
+	footer := pw.footer(idCol, vecCol, tagKeys, int64(len(snap)))
+	pw.write(footer)
+	var tail [4]byte
+	binary.LittleEndian.PutUint32(tail[:], uint32(len(footer)))
+	pw.write(tail[:])
+	pw.write([]byte(parquetMagic))
+	return pw.err
+}
+
+// ImportParquet loads vectors from a Parquet file. The vector column may be list<float>
+// or list<double>; the _created_at, _updated_at and _attributes columns written by
+// ExportParquet restore those fields, and other top-level scalar columns become string tags. Files written by
+// Spark, DuckDB or pyarrow with PLAIN or dictionary encoding and no, snappy or gzip
+// compression are supported. The whole file is validated before any vector is stored.
+func (db *VectorDB) ImportParquet(r io.ReaderAt, size int64, opts *ParquetOptions) (err error) {
+	defer db.logPersistence("parquet import", time.Now(), &err)
+	idCol, vecCol := opts.columns()
+	meta, err := readParquetFooter(r, size)
+	if err != nil {
+		return err
+	}
+	leaves, err := parquetLeaves(meta.list(2))
+	if err != nil {
+		return err
+	}
+
+	var ids []string
+	var rows [][]float32
+	var metas []VectorMetadata
+	for gi, g := range meta.list(4) {
+		rg, _ := g.(thriftStruct)
+		numRows, _ := rg.int(3)
+		var gIDs []string
+		var gRows [][]float32
+		gTags := make(map[string][]*string)
+		var gMeta [3][]*string // parquetCreatedCol, parquetUpdatedCol, parquetAttributesCol
+		for _, c := range rg.list(1) {
+			cm := c.(thriftStruct).strct(3)
+			if cm == nil {
+				return errors.New("parquet: column chunk without metadata")
+			}
+			var path []string
+			for _, p := range cm.list(3) {
+				b, _ := p.([]byte)
+				path = append(path, string(b))
+			}
+			leaf, ok := leaves[strings.Join(path, ".")]
+			if !ok {
+				return fmt.Errorf("parquet: column %v not in schema", path)
+			}
+			isVec := path[0] == vecCol && leaf.maxRep == 1
+			if leaf.maxRep > 0 && !isVec {
+				continue // nested columns other than the vector are not imported
+			}
+			col, err := readParquetChunk(r, size, cm, leaf)
+			if err != nil {
+				return fmt.Errorf("parquet: column %s: %w", strings.Join(path, "."), err)
+			}
+			switch {
+			case isVec:
+				if gRows, err = col.lists(leaf); err != nil {
+					return fmt.Errorf("parquet: column %s: %w", vecCol, err)
+				}
+			case path[0] == idCol:
+				for _, s := range col.scalars(leaf) {
+					if s == nil {
+						return errors.New("parquet: null vector ID")
+					}
+					gIDs = append(gIDs, *s)
+				}
+			case path[0] == parquetCreatedCol:
+				gMeta[0] = col.scalars(leaf)
+			case path[0] == parquetUpdatedCol:
+				gMeta[1] = col.scalars(leaf)
+			case path[0] == parquetAttributesCol:
+				gMeta[2] = col.scalars(leaf)
+			default:
+				gTags[path[0]] = col.scalars(leaf)
+			}
+		}
+		if int64(len(gIDs)) != numRows || int64(len(gRows)) != numRows {
+			return fmt.Errorf("parquet: row group %d: expected %d rows with %q and %q columns", gi, numRows, idCol, vecCol)
+		}
+		for i := range gIDs {
+			var md VectorMetadata
+			for key, col := range gTags {
+				if i < len(col) && col[i] != nil {
+					if md.Tags == nil {
+						md.Tags = make(map[string]string, len(gTags))
+					}
+					md.Tags[key] = *col[i]
+				}
+			}
+			if err := md.restore(gMeta, i); err != nil {
+				return fmt.Errorf("parquet: vector %s: %w", gIDs[i], err)
+			}
+			metas = append(metas, md)
+		}
+		ids = append(ids, gIDs...)
+		rows = append(rows, gRows...)
+	}
+	return db.importRows(rows, ids, metas)
+}
+
+// restore sets the timestamps and attributes of row i from the metadata columns, if present.
+func (md *VectorMetadata) restore(cols [3][]*string, i int) error {
+	for c, dst := range []*int64{&md.CreatedAt, &md.UpdatedAt} {
+		if i < len(cols[c]) && cols[c][i] != nil {
+			ts, err := strconv.ParseInt(*cols[c][i], 10, 64)
+			if err != nil {
+				return err
+			}
+			*dst = ts
+		}
+	}
+	if i < len(cols[2]) && cols[2][i] != nil {
+		attrs, err := unmarshalAttributes([]byte(*cols[2][i]))
+		if err != nil {
+			return err
+		}
+		md.Attributes = attrs
+	}
+	return nil
+}
+
+// parquetWriter tracks the file offset while writing column chunks.
+type parquetWriter struct {
+	w      io.Writer
+	off    int64
+	err    error
+	chunks []parquetChunk
+}
+
+type parquetChunk struct {
+	path      []string
+	typ       int32
+	numValues int
+	offset    int64
+	size      int64
+}
+
+func (pw *parquetWriter) write(b []byte) {
+	if pw.err != nil {
+		return
+	}
+	n, err := pw.w.Write(b)
+	pw.off += int64(n)
+	pw.err = err
+}
+
+// writeChunk writes one column chunk holding a single uncompressed v1 data page.
+func (pw *parquetWriter) writeChunk(path []string, typ int32, numValues int, page []byte) {
+	h := newThriftWriter()
+	h.i32(1, pqPageData)
+	h.i32(2, int32(len(page)))
+	h.i32(3, int32(len(page)))
+	h.structBegin(5)
+	h.i32(1, int32(numValues))
+	h.i32(2, pqEncPlain)
+	h.i32(3, pqEncRLE)
+	h.i32(4, pqEncRLE)
+	h.structEnd()
+	header := h.bytes()
+
+	c := parquetChunk{path: path, typ: typ, numValues: numValues, offset: pw.off}
+	pw.write(header)
+	pw.write(page)
+	c.size = pw.off - c.offset
+	pw.chunks = append(pw.chunks, c)
+}
+
+// footer encodes FileMetaData for the schema written by ExportParquet.
+func (pw *parquetWriter) footer(idCol, vecCol string, tagKeys []string, numRows int64) []byte {
+	t := newThriftWriter()
+	t.i32(1, 1)
+	t.listBegin(2, thriftStructT, 8+len(tagKeys))
+	schemaElem := func(typ, rep int32, name string, children, converted int32) {
+		t.structBegin(-1)
+		if typ >= 0 {
+			t.i32(1, typ)
+		}
+		if rep >= 0 {
+			t.i32(3, rep)
+		}
+		t.str(4, name)
+		if children > 0 {
+			t.i32(5, children)
+		}
+		if converted >= 0 {
+			t.i32(6, converted)
+		}
+		t.structEnd()
+	}
+	schemaElem(-1, -1, "schema", int32(5+len(tagKeys)), -1)
+	schemaElem(pqByteArray, pqRequired, idCol, 0, pqConvertedUTF8)
+	schemaElem(-1, pqRequired, vecCol, 1, pqConvertedList)
+	schemaElem(-1, pqRepeated, "list", 1, -1)
+	schemaElem(pqFloat, pqRequired, "element", 0, -1)
+	for _, k := range tagKeys {
+		schemaElem(pqByteArray, pqOptional, k, 0, pqConvertedUTF8)
+	}
+	schemaElem(pqInt64, pqRequired, parquetCreatedCol, 0, -1)
+	schemaElem(pqInt64, pqRequired, parquetUpdatedCol, 0, -1)
+	schemaElem(pqByteArray, pqOptional, parquetAttributesCol, 0, pqConvertedUTF8)
+	t.i64(3, numRows)
+
+	var total int64
+	for _, c := range pw.chunks {
+		total += c.size
+	}
+	t.listBegin(4, thriftStructT, 1)
+	t.structBegin(-1)
+	t.listBegin(1, thriftStructT, len(pw.chunks))
+	for _, c := range pw.chunks {
+		t.structBegin(-1)
+		t.i64(2, c.offset)
+		t.structBegin(3)
+		t.i32(1, c.typ)
+		t.listBegin(2, thriftI32, 2)
+		t.zigzag(pqEncPlain)
+		t.zigzag(pqEncRLE)
+		t.listBegin(3, thriftBinary, len(c.path))
+		for _, p := range c.path {
+			t.rawStr(p)
+		}
+		t.i32(4, pqCodecUncompressed)
+		t.i64(5, int64(c.numValues))
+		t.i64(6, c.size)
+		t.i64(7, c.size)
+		t.i64(9, c.offset)
+		t.structEnd()
+		t.structEnd()
+	}
+	t.i64(2, total)
+	t.i64(3, numRows)
+	t.structEnd()
+	t.str(6, "serverlessVector")
+	return t.bytes()
+}
+
+func appendPlainString(buf *bytes.Buffer, s string) {
+	var n [4]byte
+	binary.LittleEndian.PutUint32(n[:], uint32(len(s)))
+	buf.Write(n[:])
+	buf.WriteString(s)
+}
+
+// appendLevels writes 0/1 levels as length-prefixed RLE runs (bit width 1).
+func appendLevels(buf *bytes.Buffer, levels []int32) {
+	var runs []byte
+	for i := 0; i < len(levels); {
+		j := i
+		for j < len(levels) && levels[j] == levels[i] {
+			j++
+		}
+		runs = binary.AppendUvarint(runs, uint64(j-i)<<1)
+		runs = append(runs, byte(levels[i]))
+		i = j
+	}
+	var n [4]byte
+	binary.LittleEndian.PutUint32(n[:], uint32(len(runs)))
+	buf.Write(n[:])
+	buf.Write(runs)
+}
+
+// parquetLeaf is a primitive column with its schema path and max levels.
+type parquetLeaf struct {
+	typ    int64
+	maxDef int
+	maxRep int
+}
+
+func readParquetFooter(r io.ReaderAt, size int64) (thriftStruct, error) {
+	if size < 12 {
+		return nil, errors.New("parquet: file too small")
+	}
+	var tail [8]byte
+	if _, err := r.ReadAt(tail[:], size-8); err != nil {
+		return nil, fmt.Errorf("parquet: %w", err)
+	}
+	if string(tail[4:]) != parquetMagic {
+		return nil, errors.New("parquet: not a Parquet file")
+	}
+	n := int64(binary.LittleEndian.Uint32(tail[:4]))
+	if n > size-12 {
+		return nil, errors.New("parquet: invalid footer length")
+	}
+	buf := make([]byte, n)
+	if _, err := r.ReadAt(buf, size-8-n); err != nil {
+		return nil, fmt.Errorf("parquet: %w", err)
+	}
+	tr := &thriftReader{b: buf}
+	meta, err := tr.readStruct()
+	if err != nil {
+		return nil, fmt.Errorf("parquet: footer: %w", err)
+	}
+	return meta, nil
+}
+
+// parquetLeaves flattens the depth-first schema list into leaves keyed by dotted path.
+func parquetLeaves(schema []any) (map[string]parquetLeaf, error) {
+	leaves := make(map[string]parquetLeaf)
+	pos := 1
+	var walk func(n int, path []string, def, rep int) error
+	walk = func(n int, path []string, def, rep int) error {
+		for ; n > 0; n-- {
+			if pos >= len(schema) {
+				return errors.New("parquet: truncated schema")
+			}
+			el, _ := schema[pos].(thriftStruct)
+			pos++
+			d, r := def, rep
+			switch rt, _ := el.int(3); rt {
+			case pqOptional:
+				d++
+			case pqRepeated:
+				d++
+				r++
+			}
+			p := append(slices.Clip(path), el.str(4))
+			if children, _ := el.int(5); children > 0 {
+				if err := walk(int(children), p, d, r); err != nil {
+					return err
+				}
+				continue
+			}
+			typ, _ := el.int(1)
+			leaves[strings.Join(p, ".")] = parquetLeaf{typ: typ, maxDef: d, maxRep: r}
+		}
+		return nil
+	}
+	if len(schema) == 0 {
+		return nil, errors.New("parquet: empty schema")
+	}
+	root, _ := schema[0].(thriftStruct)
+	children, _ := root.int(5)
+	return leaves, walk(int(children), nil, 0, 0)
+}
+
+// parquetColumn holds the decoded levels and values of one column chunk.
+// FLOAT and DOUBLE values land in nums; every other type is formatted into strs.
+type parquetColumn struct {
+	defs, reps []int32
+	nums       []float64
+	strs       []string
+}
+
+// scalars returns one entry per row for a flat column; nil marks a null.
+func (c *parquetColumn) scalars(leaf parquetLeaf) []*string {
+	n := len(c.defs)
+	if leaf.maxDef == 0 {
+		n = len(c.nums) + len(c.strs)
+	}
+	out := make([]*string, n)
+	k := 0
+	for i := range out {
+		if leaf.maxDef > 0 && c.defs[i] != int32(leaf.maxDef) {
+			continue
+		}
+		var s string
+		if c.nums != nil {
+			bitSize := 64
+			if leaf.typ == pqFloat {
+				bitSize = 32
+			}
+			s = strconv.FormatFloat(c.nums[k], 'g', -1, bitSize)
+		} else {
+			s = c.strs[k]
+		}
+		out[i] = &s
+		k++
+	}
+	return out
+}
+
+// lists reassembles a repeated float column into one vector per row.
+func (c *parquetColumn) lists(leaf parquetLeaf) ([][]float32, error) {
+	if leaf.typ != pqFloat && leaf.typ != pqDouble {
+		return nil, errors.New("vector column must be list<float> or list<double>")
+	}
+	var rows [][]float32
+	k := 0
+	for i := range c.defs {
+		if c.reps[i] == 0 {
+			rows = append(rows, nil)
+		}
+		if rows == nil {
+			return nil, errors.New("first level must start a row")
+		}
+		if c.defs[i] != int32(leaf.maxDef) {
+			return nil, fmt.Errorf("row %d has a null or empty vector", len(rows)-1)
+		}
+		rows[len(rows)-1] = append(rows[len(rows)-1], float32(c.nums[k]))
+		k++
+	}
+	return rows, nil
+}
+
+// readParquetChunk decodes every page of a column chunk.
+func readParquetChunk(r io.ReaderAt, size int64, cm thriftStruct, leaf parquetLeaf) (*parquetColumn, error) {
+	codec, _ := cm.int(4)
+	numValues, _ := cm.int(5)
+	total, _ := cm.int(7)
+	start, _ := cm.int(9)
+	if dict, ok := cm.int(11); ok && dict > 0 && dict < start {
+		start = dict
+	}
+	if start < 4 || total < 0 || start+total > size {
+		return nil, errors.New("invalid chunk offsets")
+	}
+	buf := make([]byte, total)
+	if _, err := r.ReadAt(buf, start); err != nil {
+		return nil, err
+	}
+
+	col := &parquetColumn{}
+	var dict *parquetColumn
+	repWidth := bits.Len(uint(leaf.maxRep))
+	defWidth := bits.Len(uint(leaf.maxDef))
+	pos := 0
+	for read := int64(0); read < numValues; {
+		tr := &thriftReader{b: buf, pos: pos}
+		ph, err := tr.readStruct()
+		if err != nil {
+			return nil, fmt.Errorf("page header: %w", err)
+		}
+		ptype, _ := ph.int(1)
+		usize, _ := ph.int(2)
+		csize, _ := ph.int(3)
+		if csize < 0 || tr.pos+int(csize) > len(buf) {
+			return nil, errors.New("page exceeds column chunk")
+		}
+		data := buf[tr.pos : tr.pos+int(csize)]
+		pos = tr.pos + int(csize)
+
+		switch ptype {
+		case pqPageDictionary:
+			if data, err = parquetDecompress(codec, data, usize); err != nil {
+				return nil, err
+			}
+			n, _ := ph.strct(7).int(1)
+			if dict, err = decodePlain(data, leaf.typ, int(n)); err != nil {
+				return nil, fmt.Errorf("dictionary page: %w", err)
+			}
+		case pqPageData:
+			dph := ph.strct(5)
+			n, _ := dph.int(1)
+			enc, _ := dph.int(2)
+			if data, err = parquetDecompress(codec, data, usize); err != nil {
+				return nil, err
+			}
+			var reps, defs []int32
+			if leaf.maxRep > 0 {
+				if reps, data, err = readPrefixedLevels(data, repWidth, int(n)); err != nil {
+					return nil, err
+				}
+			}
+			if leaf.maxDef > 0 {
+				if defs, data, err = readPrefixedLevels(data, defWidth, int(n)); err != nil {
+					return nil, err
+				}
+			}
+			if err := col.appendPage(reps, defs, data, enc, int(n), leaf, dict); err != nil {
+				return nil, err
+			}
+			read += n
+		case pqPageDataV2:
+			h2 := ph.strct(8)
+			n, _ := h2.int(1)
+			enc, _ := h2.int(4)
+			defLen, _ := h2.int(5)
+			repLen, _ := h2.int(6)
+			if defLen < 0 || repLen < 0 || repLen+defLen > int64(len(data)) {
+				return nil, errors.New("invalid level lengths")
+			}
+			var reps, defs []int32
+			if leaf.maxRep > 0 {
+				if reps, err = decodeRLEHybrid(data[:repLen], repWidth, int(n)); err != nil {
+					return nil, err
+				}
+			}
+			if leaf.maxDef > 0 {
+				if defs, err = decodeRLEHybrid(data[repLen:repLen+defLen], defWidth, int(n)); err != nil {
+					return nil, err
+				}
+			}
+			values := data[repLen+defLen:]
+			if compressed, ok := h2[7].(bool); !ok || compressed {
+				if values, err = parquetDecompress(codec, values, usize-repLen-defLen); err != nil {
+					return nil, err
+				}
+			}
+			if err := col.appendPage(reps, defs, values, enc, int(n), leaf, dict); err != nil {
+				return nil, err
+			}
+			read += n
+		}
+		if pos >= len(buf) && read < numValues {
+			return nil, errors.New("column chunk ended early")
+		}
+	}
+	return col, nil
+}
+
+// appendPage decodes the values of a data page and appends levels and values to c.
+func (c *parquetColumn) appendPage(reps, defs []int32, data []byte, enc int64, n int, leaf parquetLeaf, dict *parquetColumn) error {
+	count := n
+	if leaf.maxDef > 0 {
+		count = 0
+		for _, d := range defs {
+			if d == int32(leaf.maxDef) {
+				count++
+			}
+		}
+	}
+	var vals *parquetColumn
+	var err error
+	switch enc {
+	case pqEncPlain:
+		vals, err = decodePlain(data, leaf.typ, count)
+	case pqEncPlainDict, pqEncRLEDictionary:
+		if dict == nil {
+			return errors.New("dictionary-encoded page without dictionary")
+		}
+		if len(data) == 0 {
+			if count > 0 {
+				return errors.New("missing dictionary indices")
+			}
+			vals = &parquetColumn{}
+			break
+		}
+		var idx []int32
+		if idx, err = decodeRLEHybrid(data[1:], int(data[0]), count); err != nil {
+			return err
+		}
+		vals = &parquetColumn{}
+		for _, i := range idx {
+			if i < 0 || int(i) >= len(dict.nums)+len(dict.strs) {
+				return errors.New("dictionary index out of range")
+			}
+			if dict.nums != nil {
+				vals.nums = append(vals.nums, dict.nums[i])
+			} else {
+				vals.strs = append(vals.strs, dict.strs[i])
+			}
+		}
+	default:
+		return fmt.Errorf("unsupported encoding %d", enc)
+	}
+	if err != nil {
+		return err
+	}
+	c.reps = append(c.reps, reps...)
+	c.defs = append(c.defs, defs...)
+	c.nums = append(c.nums, vals.nums...)
+	c.strs = append(c.strs, vals.strs...)
+	return nil
+}
+
+// decodePlain decodes count PLAIN-encoded values of a physical type.
+func decodePlain(data []byte, typ int64, count int) (*parquetColumn, error) {
+	out := &parquetColumn{}
+	short := errors.New("truncated PLAIN values")
+	switch typ {
+	case pqBoolean:
+		if len(data)*8 < count {
+			return nil, short
+		}
+		for i := 0; i < count; i++ {
+			out.strs = append(out.strs, strconv.FormatBool(data[i/8]>>(i%8)&1 == 1))
+		}
+	case pqInt32:
+		if len(data) < 4*count {
+			return nil, short
+		}
+		for i := 0; i < count; i++ {
+			out.strs = append(out.strs, strconv.FormatInt(int64(int32(binary.LittleEndian.Uint32(data[i*4:]))), 10))
+		}
+	case pqInt64:
+		if len(data) < 8*count {
+			return nil, short
+		}
+		for i := 0; i < count; i++ {
+			out.strs = append(out.strs, strconv.FormatInt(int64(binary.LittleEndian.Uint64(data[i*8:])), 10))
+		}
+	case pqFloat:
+		if len(data) < 4*count {
+			return nil, short
+		}
+		out.nums = make([]float64, count)
+		for i := range out.nums {
+			out.nums[i] = float64(math.Float32frombits(binary.LittleEndian.Uint32(data[i*4:])))
+		}
+	case pqDouble:
+		if len(data) < 8*count {
+			return nil, short
+		}
+		out.nums = make([]float64, count)
+		for i := range out.nums {
+			out.nums[i] = math.Float64frombits(binary.LittleEndian.Uint64(data[i*8:]))
+		}
+	case pqByteArray:
+		for i := 0; i < count; i++ {
+			if len(data) < 4 {
+				return nil, short
+			}
+			n := binary.LittleEndian.Uint32(data)
+			if uint64(n) > uint64(len(data)-4) {
+				return nil, short
+			}
+			out.strs = append(out.strs, string(data[4:4+n]))
+			data = data[4+n:]
+		}
+	default:
+		return nil, fmt.Errorf("unsupported physical type %d", typ)
+	}
+	return out, nil
+}
+
+// readPrefixedLevels decodes v1-page levels (4-byte length prefix) and returns the rest of data.
+func readPrefixedLevels(data []byte, width, n int) ([]int32, []byte, error) {
+	if len(data) < 4 {
+		return nil, nil, errors.New("truncated levels")
+	}
+	l := binary.LittleEndian.Uint32(data)
+	if uint64(l) > uint64(len(data)-4) {
+		return nil, nil, errors.New("truncated levels")
+	}
+	levels, err := decodeRLEHybrid(data[4:4+l], width, n)
+	return levels, data[4+l:], err
+}
+
+// decodeRLEHybrid decodes n values of the RLE/bit-packing hybrid encoding.
+func decodeRLEHybrid(data []byte, width, n int) ([]int32, error) {
+	if width > 32 {
+		return nil, fmt.Errorf("invalid bit width %d", width)
+	}
+	out := make([]int32, 0, n)
+	byteWidth := (width + 7) / 8
+	for len(out) < n {
+		h, k := binary.Uvarint(data)
+		if k <= 0 {
+			return nil, errors.New("truncated RLE run")
+		}
+		data = data[k:]
+		if h&1 == 0 {
+			if len(data) < byteWidth {
+				return nil, errors.New("truncated RLE run")
+			}
+			var v uint32
+			for i := 0; i < byteWidth; i++ {
+				v |= uint32(data[i]) << (8 * i)
+			}
+			data = data[byteWidth:]
+			for run := h >> 1; run > 0 && len(out) < n; run-- {
+				out = append(out, int32(v))
+			}
+			continue
+		}
+		groups := int(h >> 1)
+		if groups*width > len(data) {
+			return nil, errors.New("truncated bit-packed run")
+		}
+		for i := 0; i < groups*8 && len(out) < n; i++ {
+			var v uint32
+			for b := 0; b < width; b++ {
+				bit := i*width + b
+				v |= uint32(data[bit/8]>>(bit%8)&1) << b
+			}
+			out = append(out, int32(v))
+		}
+		data = data[groups*width:]
+	}
+	return out, nil
+}
+
+func parquetDecompress(codec int64, data []byte, size int64) ([]byte, error) {
+	switch codec {
+	case pqCodecUncompressed:
+		return data, nil
+	case pqCodecSnappy:
+		return snappyDecode(data, size)
+	case pqCodecGzip:
+		zr, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		return io.ReadAll(io.LimitReader(zr, size))
+	default:
+		return nil, fmt.Errorf("unsupported compression codec %d (use uncompressed, snappy or gzip)", codec)
+	}
+}
+
+// snappyDecode decodes a raw (unframed) snappy block of at most limit bytes.
+func snappyDecode(src []byte, limit int64) ([]byte, error) {
+	n, k := binary.Uvarint(src)
+	if k <= 0 || n > uint64(limit) {
+		return nil, errors.New("snappy: invalid length")
+	}
+	bad := errors.New("snappy: corrupt input")
+	dst := make([]byte, 0, n)
+	for s := k; s < len(src); {
+		tag := src[s]
+		var length, offset int
+		switch tag & 3 {
+		case 0:
+			x := int(tag >> 2)
+			s++
+			if x >= 60 {
+				nb := x - 59
+				if s+nb > len(src) {
+					return nil, bad
+				}
+				x = 0
+				for i := 0; i < nb; i++ {
+					x |= int(src[s+i]) << (8 * i)
+				}
+				s += nb
+			}
+			length = x + 1
+			if length > len(src)-s || len(dst)+length > int(n) {
+				return nil, bad
+			}
+			dst = append(dst, src[s:s+length]...)
+			s += length
+			continue
+		case 1:
+			if s+2 > len(src) {
+				return nil, bad
+			}
+			length = 4 + int(tag>>2&7)
+			offset = int(tag&0xe0)<<3 | int(src[s+1])
+			s += 2
+		case 2:
+			if s+3 > len(src) {
+				return nil, bad
+			}
+			length = 1 + int(tag>>2)
+			offset = int(binary.LittleEndian.Uint16(src[s+1:]))
+			s += 3
+		case 3:
+			if s+5 > len(src) {
+				return nil, bad
+			}
+			length = 1 + int(tag>>2)
+			offset = int(binary.LittleEndian.Uint32(src[s+1:]))
+			s += 5
+		}
+		if offset <= 0 || offset > len(dst) || len(dst)+length > int(n) {
+			return nil, bad
+		}
+		for i := 0; i < length; i++ {
+			dst = append(dst, dst[len(dst)-offset])
+		}
+	}
+	if len(dst) != int(n) {
+		return nil, bad
+	}
+	return dst, nil
+}
@@ -0,0 +1,153 @@
+package lib
+
+import (
+	"bytes"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParquet_RoundTrip(t *testing.T) {
+	src := NewVectorDB(3)
+	_ = src.Add("a", []float32{1, 2, 3}, VectorMetadata{Tags: map[string]string{"lang": "en"}})
+	_ = src.Add("b", []float32{4, 5, 6}, VectorMetadata{Tags: map[string]string{"lang": "fr", "src": "web"}})
+	_ = src.Add("c", []float32{-1, 0, 0.5})
+
+	var buf bytes.Buffer
+	if err := src.ExportParquet(&buf, nil); err != nil {
+		t.Fatalf("ExportParquet failed: %v", err)
+	}
+	if !bytes.HasPrefix(buf.Bytes(), []byte("PAR1")) || !bytes.HasSuffix(buf.Bytes(), []byte("PAR1")) {
+		t.Fatal("output must start and end with PAR1")
+	}
+
+	dst := NewVectorDB(3)
+	r := bytes.NewReader(buf.Bytes())
+	if err := dst.ImportParquet(r, int64(r.Len()), nil); err != nil {
+		t.Fatalf("ImportParquet failed: %v", err)
+	}
+	if dst.Size() != 3 {
+		t.Fatalf("expected 3 vectors, got %d", dst.Size())
+	}
+	b, _ := dst.Get("b")
+	if b.Data[2] != 6 || b.Metadata.Tags["lang"] != "fr" || b.Metadata.Tags["src"] != "web" {
+		t.Errorf("unexpected vector b: %+v", b)
+	}
+	c, _ := dst.Get("c")
+	if c.Data[0] != -1 || len(c.Metadata.Tags) != 0 {
+		t.Errorf("unexpected vector c: %+v", c)
+	}
+}
+
+func TestParquet_Attributes(t *testing.T) {
+	when := time.Date(2024, 5, 1, 12, 30, 0, 5, time.UTC)
+	attrs := map[string]any{"year": 2020, "price": 9.5, "live": true, "name": "x", "langs": []string{"en", "fr"}, "when": when, "cap": math.Inf(1)}
+	src := NewVectorDB(2)
+	_ = src.Add("a", []float32{1, 0}, VectorMetadata{Attributes: attrs})
+	_ = src.Add("b", []float32{0, 1})
+	var buf bytes.Buffer
+	if err := src.ExportParquet(&buf, nil); err != nil {
+		t.Fatal(err)
+	}
+	dst := NewVectorDB(2)
+	if err := dst.ImportParquet(bytes.NewReader(buf.Bytes()), int64(buf.Len()), nil); err != nil {
+		t.Fatal(err)
+	}
+	want, _ := src.Get("a")
+	got, _ := dst.Get("a")
+	if !reflect.DeepEqual(got.Metadata.Attributes, want.Metadata.Attributes) || len(got.Metadata.Tags) != 0 {
+		t.Errorf("attributes changed in a round trip: %v, want %v", got.Metadata.Attributes, want.Metadata.Attributes)
+	}
+	if b, _ := dst.Get("b"); b.Metadata.Attributes != nil || b.Metadata.Tags != nil {
+		t.Errorf("a vector without attributes must import without any, got %+v", b.Metadata)
+	}
+	if n, _ := dst.Count(Eq("year", 2020)); n != 1 {
+		t.Error("imported attributes must be filterable")
+	}
+}
+
+func TestParquet_CustomColumns(t *testing.T) {
+	src := NewVectorDB(2)
+	_ = src.Add("x", []float32{1, 0})
+	opts := &ParquetOptions{IDColumn: "doc_id", VectorColumn: "vec"}
+	var buf bytes.Buffer
+	if err := src.ExportParquet(&buf, opts); err != nil {
+		t.Fatalf("ExportParquet failed: %v", err)
+	}
+	r := bytes.NewReader(buf.Bytes())
+	if err := NewVectorDB(2).ImportParquet(r, int64(r.Len()), nil); err == nil {
+		t.Error("expected import with default column names to fail")
+	}
+	dst := NewVectorDB(2)
+	if err := dst.ImportParquet(r, int64(r.Len()), opts); err != nil {
+		t.Fatalf("ImportParquet failed: %v", err)
+	}
+	if _, err := dst.Get("x"); err != nil {
+		t.Errorf("expected x to be imported: %v", err)
+	}
+}
+
+func TestParquet_RejectsTagCollision(t *testing.T) {
+	db := NewVectorDB(2)
+	_ = db.Add("x", []float32{1, 0}, VectorMetadata{Tags: map[string]string{"id": "dup"}})
+	if err := db.ExportParquet(&bytes.Buffer{}, nil); err == nil {
+		t.Error("expected tag named like the ID column to be rejected")
+	}
+}
+
+func TestParquet_RejectsBadInput(t *testing.T) {
+	db := NewVectorDB(2)
+	data := []byte("PAR1 not really parquet PAR1")
+	if err := db.ImportParquet(bytes.NewReader(data), int64(len(data)), nil); err == nil {
+		t.Error("expected corrupt file to be rejected")
+	}
+	if err := db.ImportParquet(bytes.NewReader(nil), 0, nil); err == nil {
+		t.Error("expected empty file to be rejected")
+	}
+}
+
+func TestDecodeRLEHybrid_BitPacked(t *testing.T) {
+	// Example from the Parquet spec: 0..7 bit-packed with width 3.
+	got, err := decodeRLEHybrid([]byte{0x03, 0x88, 0xC6, 0xFA}, 3, 8)
+	if err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+	for i, v := range got {
+		if v != int32(i) {
+			t.Fatalf("expected %d at %d, got %d", i, i, v)
+		}
+	}
+}
+
+func TestParquet_DictionaryPage(t *testing.T) {
+	dict := &parquetColumn{strs: []string{"x", "y"}}
+	col := &parquetColumn{}
+	// Bit width 1, one bit-packed group holding indices 0,1,1,0.
+	data := []byte{1, 0x03, 0x06}
+	leaf := parquetLeaf{typ: pqByteArray}
+	if err := col.appendPage(nil, nil, data, pqEncRLEDictionary, 4, leaf, dict); err != nil {
+		t.Fatalf("appendPage failed: %v", err)
+	}
+	want := []string{"x", "y", "y", "x"}
+	for i, s := range col.strs {
+		if s != want[i] {
+			t.Fatalf("expected %v, got %v", want, col.strs)
+		}
+	}
+}
+
+func TestSnappyDecode(t *testing.T) {
+	// Literal "abcd" followed by a copy of length 8 at offset 4.
+	src := []byte{12, 0x0c, 'a', 'b', 'c', 'd', 0x11, 0x04}
+	got, err := snappyDecode(src, 12)
+	if err != nil {
+		t.Fatalf("snappyDecode failed: %v", err)
+	}
+	if string(got) != "abcdabcdabcd" {
+		t.Errorf("expected abcdabcdabcd, got %q", got)
+	}
+	if _, err := snappyDecode([]byte{12, 0x11, 0x04}, 12); err == nil {
+		t.Error("expected copy before any output to fail")
+	}
+}
@@ -0,0 +1,275 @@
+package lib
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"time"
+)
+
+// pcaIterations and pcaOversample tune the randomized subspace iteration in FitPCA: a few
+// passes over the covariance with a handful of extra directions recover the leading
+// components to well within float32 precision for embedding spectra.
+const (
+	pcaIterations = 12
+	pcaOversample = 10
+)
+
+// PCA is a Transform projecting vectors onto their principal components, fitted with
+// FitPCA. Its fields are exported so a fitted PCA can be saved (e.g. as JSON) and passed to
+// Options.Transform at the next cold start.
+type PCA struct {
+	Mean          []float32   // Subtracted from each vector before projecting.
+	Components    [][]float32 // Unit directions of length len(Mean), by decreasing variance.
+	Variance      []float64   // Variance of the fitted vectors along each component.
+	TotalVariance float64     // Variance of the fitted vectors over all dimensions.
+}
+
+// Dimensions returns the input and output vector lengths.
+func (p *PCA) Dimensions() (in, out int) { return len(p.Mean), len(p.Components) }
+
+// Apply writes the coordinates of v - Mean along each component to dst.
+func (p *PCA) Apply(dst, v []float32) {
+	for i, c := range p.Components {
+		var sum float64
+		for j, x := range v {
+			sum += float64(x-p.Mean[j]) * float64(c[j])
+		}
+		dst[i] = float32(sum)
+	}
+}
+
+// Explained returns the share of the fitted vectors' variance the components keep, in [0, 1],
+// to choose how many to fit: 1536-dimension text embeddings typically keep over 90% in 256.
+func (p *PCA) Explained() float64 {
+	if p.TotalVariance == 0 {
+		return 1
+	}
+	var sum float64
+	for _, v := range p.Variance {
+		sum += v
+	}
+	return sum / p.TotalVariance
+}
+
+// FitPCA fits the principal components of the stored vectors that keep dims dimensions. Pass
+// the result to Transformed to get a smaller DB, or to Options.Transform. It is O(n·d²) for
+// n vectors of dimension d and reads the vectors under the read lock.
+func (db *VectorDB) FitPCA(dims int) (*PCA, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	start := time.Now()
+	if len(db.vectors) < 2 {
+		return nil, errors.New("need at least 2 vectors to fit a PCA")
+	}
+	d := -1
+	var mean []float64
+	var buf []float32
+	for _, v := range db.vectors {
+		if d < 0 {
+			d = v.Dimension
+			mean = make([]float64, d)
+		} else if v.Dimension != d {
+			return nil, fmt.Errorf("cannot fit a PCA over mixed dimensions %d and %d", d, v.Dimension)
+		}
+		for i, x := range db.floats(v, &buf) {
+			mean[i] += float64(x)
+		}
+	}
+	if dims <= 0 || dims >= d {
+		return nil, fmt.Errorf("PCA dimensions must be between 1 and %d, got %d", d-1, dims)
+	}
+	n := float64(len(db.vectors))
+	for i := range mean {
+		mean[i] /= n
+	}
+	cov := make([]float64, d*d)
+	dev := make([]float64, d)
+	for _, v := range db.vectors {
+		for i, x := range db.floats(v, &buf) {
+			dev[i] = float64(x) - mean[i]
+		}
+		for i := range d {
+			for j := 0; j <= i; j++ {
+				cov[i*d+j] += dev[i] * dev[j]
+			}
+		}
+	}
+	var trace float64
+	for i := range d {
+		for j := 0; j <= i; j++ {
+			cov[i*d+j] /= n - 1
+			cov[j*d+i] = cov[i*d+j]
+		}
+		trace += cov[i*d+i]
+	}
+
+	values, vectors := topEigen(cov, d, dims)
+	p := &PCA{Mean: make([]float32, d), Variance: values, TotalVariance: trace}
+	for i, m := range mean {
+		p.Mean[i] = float32(m)
+	}
+	for _, vec := range vectors {
+		c := make([]float32, d)
+		for i, x := range vec {
+			c[i] = float32(x)
+		}
+		p.Components = append(p.Components, c)
+	}
+	db.logIndexBuild("pca", len(db.vectors), start, "dimension", d, "components", dims)
+	return p, nil
+}
+
+// topEigen returns the k largest eigenvalues of the symmetric d×d matrix a, in decreasing
+// order, and their unit eigenvectors, by randomized subspace iteration followed by a
+// Rayleigh-Ritz step.
+func topEigen(a []float64, d, k int) ([]float64, [][]float64) {
+	m := min(d, k+pcaOversample)
+	rng := rand.New(rand.NewPCG(1, 2)) // fixed seed: the same vectors fit the same PCA
+	q := make([][]float64, m)
+	for i := range q {
+		q[i] = make([]float64, d)
+		for j := range q[i] {
+			q[i][j] = rng.NormFloat64()
+		}
+	}
+	orthonormalize(q)
+	for range pcaIterations {
+		for i := range q {
+			q[i] = matVec(a, d, q[i])
+		}
+		orthonormalize(q)
+	}
+
+	// Rayleigh-Ritz: eigen-decompose T = Qᵀ A Q and rotate Q by T's eigenvectors.
+	aq := make([][]float64, m)
+	for i := range q {
+		aq[i] = matVec(a, d, q[i])
+	}
+	t := make([]float64, m*m)
+	for i := range m {
+		for j := range m {
+			t[i*m+j] = dot64(q[i], aq[j])
+		}
+	}
+	values, rot := jacobiEigen(t, m)
+	order := make([]int, m)
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortFunc(order, func(x, y int) int {
+		switch {
+		case values[x] > values[y]:
+			return -1
+		case values[x] < values[y]:
+			return 1
+		}
+		return x - y
+	})
+	outValues := make([]float64, k)
+	outVectors := make([][]float64, k)
+	for r, col := range order[:k] {
+		outValues[r] = values[col]
+		vec := make([]float64, d)
+		for i := range m {
+			w := rot[i*m+col]
+			for j := range vec {
+				vec[j] += w * q[i][j]
+			}
+		}
+		outVectors[r] = vec
+	}
+	return outValues, outVectors
+}
+
+func matVec(a []float64, d int, x []float64) []float64 {
+	out := make([]float64, d)
+	for i := range d {
+		out[i] = dot64(a[i*d:(i+1)*d], x)
+	}
+	return out
+}
+
+func dot64(a, b []float64) float64 {
+	var sum float64
+	for i := range a {
+		sum += a[i] * b[i]
+	}
+	return sum
+}
+
+// orthonormalize makes the rows of q orthonormal by modified Gram-Schmidt, replacing rows that
+// vanish with a unit basis vector orthogonal to the rest.
+func orthonormalize(q [][]float64) {
+	for i := range q {
+		for attempt := 0; ; attempt++ {
+			for j := range i {
+				p := dot64(q[i], q[j])
+				for x := range q[i] {
+					q[i][x] -= p * q[j][x]
+				}
+			}
+			norm := math.Sqrt(dot64(q[i], q[i]))
+			if norm > 1e-12 || attempt == len(q[i]) {
+				for x := range q[i] {
+					q[i][x] /= norm
+				}
+				break
+			}
+			clear(q[i])
+			q[i][(i+attempt)%len(q[i])] = 1
+		}
+	}
+}
+
+// jacobiEigen returns the eigenvalues of the symmetric n×n matrix a and its eigenvectors as
+// the columns of an n×n matrix, by cyclic Jacobi rotations. a is overwritten.
+func jacobiEigen(a []float64, n int) ([]float64, []float64) {
+	v := make([]float64, n*n)
+	for i := range n {
+		v[i*n+i] = 1
+	}
+	scale := dot64(a, a)
+	for range 100 {
+		var off float64
+		for i := range n {
+			for j := i + 1; j < n; j++ {
+				off += a[i*n+j] * a[i*n+j]
+			}
+		}
+		if off <= 1e-24*scale {
+			break
+		}
+		for p := range n {
+			for q := p + 1; q < n; q++ {
+				apq := a[p*n+q]
+				if math.Abs(apq) < 1e-300 {
+					continue
+				}
+				theta := (a[q*n+q] - a[p*n+p]) / (2 * apq)
+				t := math.Copysign(1, theta) / (math.Abs(theta) + math.Sqrt(theta*theta+1))
+				c := 1 / math.Sqrt(t*t+1)
+				s := t * c
+				for k := range n {
+					akp, akq := a[k*n+p], a[k*n+q]
+					a[k*n+p], a[k*n+q] = c*akp-s*akq, s*akp+c*akq
+				}
+				for k := range n {
+					apk, aqk := a[p*n+k], a[q*n+k]
+					a[p*n+k], a[q*n+k] = c*apk-s*aqk, s*apk+c*aqk
+				}
+				for k := range n {
+					vkp, vkq := v[k*n+p], v[k*n+q]
+					v[k*n+p], v[k*n+q] = c*vkp-s*vkq, s*vkp+c*vkq
+				}
+			}
+		}
+	}
+	values := make([]float64, n)
+	for i := range n {
+		values[i] = a[i*n+i]
+	}
+	return values, v
+}
@@ -0,0 +1,111 @@
+package lib
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"testing"
+)
+
+func TestFitPCA(t *testing.T) {
+	rng := rand.New(rand.NewPCG(7, 7))
+	db := NewVectorDB(3, EuclideanDistance)
+	for i := range 500 {
+		a, b, c := rng.NormFloat64()*10, rng.NormFloat64()*3, rng.NormFloat64()*0.1
+		// a along (1,1,0)/√2, b along z, c along (1,-1,0)/√2, around (5, 5, 5)
+		x := 5 + (a+c)/math.Sqrt2
+		y := 5 + (a-c)/math.Sqrt2
+		_ = db.Add(fmt.Sprint(i), []float32{float32(x), float32(y), float32(5 + b)})
+	}
+	p, err := db.FitPCA(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if in, out := p.Dimensions(); in != 3 || out != 2 {
+		t.Fatalf("unexpected dimensions %d, %d", in, out)
+	}
+	c0, c1 := p.Components[0], p.Components[1]
+	if math.Abs(math.Abs(float64(c0[0]))-1/math.Sqrt2) > 0.01 || math.Abs(float64(c0[0]-c0[1])) > 0.01 || math.Abs(float64(c0[2])) > 0.01 {
+		t.Errorf("first component should be ±(1,1,0)/√2, got %v", c0)
+	}
+	if math.Abs(math.Abs(float64(c1[2]))-1) > 0.01 {
+		t.Errorf("second component should be ±z, got %v", c1)
+	}
+	if p.Variance[0] < 80 || p.Variance[1] > p.Variance[0] || p.Explained() < 0.999 {
+		t.Errorf("unexpected variance %v, explained %v", p.Variance, p.Explained())
+	}
+	if _, err := db.FitPCA(3); err == nil {
+		t.Error("expected an error when keeping every dimension")
+	}
+}
+
+func TestTransformed(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 1))
+	db := NewVectorDB(16, EuclideanDistance)
+	basis := make([][]float64, 4) // the data lives in a 4-dimensional subspace
+	for i := range basis {
+		basis[i] = make([]float64, 16)
+		for j := range basis[i] {
+			basis[i][j] = rng.NormFloat64()
+		}
+	}
+	point := func() []float32 {
+		v := make([]float32, 16)
+		for _, b := range basis {
+			w := rng.NormFloat64()
+			for j := range v {
+				v[j] += float32(w * b[j])
+			}
+		}
+		return v
+	}
+	for i := range 200 {
+		_ = db.Add(fmt.Sprint("doc", i), point(), VectorMetadata{Tags: map[string]string{"i": fmt.Sprint(i)}})
+	}
+	p, err := db.FitPCA(4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	small, err := db.Transformed(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if small.Dimension() != 4 || small.Size() != 200 || db.Dimension() != 16 {
+		t.Fatalf("unexpected dimensions %d and %d, size %d", small.Dimension(), db.Dimension(), small.Size())
+	}
+	for range 10 {
+		q := point()
+		want, _ := db.Search(q, 5)
+		got, err := small.Search(q, 5) // full-size queries are projected
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := range want.Results {
+			if got.Results[i].ID != want.Results[i].ID || math.Abs(got.Results[i].Score-want.Results[i].Score) > 1e-3 {
+				t.Fatalf("projection onto the data's subspace must keep distances: %v vs %v", got.Results, want.Results)
+			}
+		}
+	}
+
+	if err := small.Add("new", point()); err != nil {
+		t.Fatal(err)
+	}
+	v, _ := small.Get("doc7")
+	if v.Dimension != 4 || v.Metadata.Tags["i"] != "7" {
+		t.Errorf("unexpected transformed vector %+v", v)
+	}
+	if res, err := small.Search(v.Data, 1); err != nil || res.Results[0].ID != "doc7" {
+		t.Errorf("stored vectors must work as queries, got %+v, %v", res, err)
+	}
+	if err := small.Add("bad", make([]float32, 7)); err == nil {
+		t.Error("expected an error for a vector matching neither dimension")
+	}
+	if _, err := small.Transformed(p); err == nil {
+		t.Error("expected an error transforming twice")
+	}
+
+	fresh := NewVectorDBWithOptions(&Options{Transform: p, Distance: EuclideanDistance})
+	if err := fresh.Add("a", point()); err != nil || fresh.Dimension() != 4 {
+		t.Errorf("Options.Transform must apply to writes: %v, dimension %d", err, fresh.Dimension())
+	}
+}
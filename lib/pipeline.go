@@ -0,0 +1,138 @@
+package lib
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+	"time"
+)
+
+// DefaultBinaryRescore is how many candidates per requested result a Binary first pass keeps
+// for exact rescoring when SearchOptions.RescoreTop is 0.
+const DefaultBinaryRescore = 10
+
+// pipeline is how a search scores candidates. scan ranks every vector in scope; when refine > 0
+// only the best refine of them by scan are rescored with final, otherwise scan is final.
+type pipeline struct {
+	scan              func(*Vector) float64
+	scanLowerIsBetter bool
+	final             func(*Vector) float64
+	refine            int
+	scratch           *[]float32 // pooled decoding buffer; release returns it
+}
+
+// release returns the pipeline's scratch buffer to its pool. The pipeline must not be used after.
+func (p pipeline) release() { putFloats(p.scratch) }
+
+// pipeline builds the stages opts asks for. want is offset+topK capped at the stored vectors,
+// the results the final stage must produce; a refine stage always keeps more than that.
+func (db *VectorDB) pipeline(query32 []float32, opts *SearchOptions, want int) (pipeline, error) {
+	distance, err := db.distanceTo(query32, opts, len(query32))
+	if err != nil {
+		return pipeline{}, err
+	}
+	buf := getFloats() // decoding scratch for packed storage
+	final := func(v *Vector) float64 { return distance(db.floats(v, buf)) }
+	if kernel := db.intKernel(query32, opts); kernel != nil {
+		final = func(v *Vector) float64 { return kernel(v.packed) }
+	}
+	exact := pipeline{
+		scan:              final,
+		scanLowerIsBetter: db.distFunc.lowerIsBetter(),
+		final:             final,
+		scratch:           buf,
+	}
+	if opts == nil {
+		return exact, nil
+	}
+	dims := opts.Dimensions
+	if dims == 0 {
+		dims = len(query32)
+	}
+	if dims < 0 || dims > len(query32) {
+		exact.release()
+		return pipeline{}, fmt.Errorf("dimensions must be between 1 and the query dimension %d, got %d", len(query32), opts.Dimensions)
+	}
+	if opts.RescoreTop < 0 {
+		exact.release()
+		return pipeline{}, fmt.Errorf("rescore top must be >= 0, got %d", opts.RescoreTop)
+	}
+	if !opts.Binary && dims == len(query32) {
+		return exact, nil
+	}
+
+	p := exact
+	if opts.Binary {
+		if !db.binaryIndex {
+			p.release()
+			return pipeline{}, errors.New("binary search needs CreateBinaryIndex")
+		}
+		q := signCode(query32)
+		p.scan = func(v *Vector) float64 { return float64(hamming(q, v.code, dims)) }
+		p.scanLowerIsBetter = true
+	} else {
+		truncated, _ := db.distanceTo(query32, opts, dims)
+		p.scan = func(v *Vector) float64 { return truncated(db.floats(v, buf)) }
+		if opts.RescoreTop == 0 { // truncated scores are final
+			p.final = p.scan
+			return p, nil
+		}
+	}
+	p.refine = max(opts.RescoreTop, want+1)
+	if opts.RescoreTop == 0 { // Hamming distances are not scores; always rescore
+		p.refine = DefaultBinaryRescore * (want + 1)
+	}
+	return p, nil
+}
+
+// CreateBinaryIndex keeps a one-bit-per-dimension sign code of every vector (d/8 bytes each) so
+// that SearchOptions.Binary can shortlist candidates by Hamming distance before rescoring them
+// exactly. Best for cosine or dot-product embeddings centred on zero. Building it is O(n·d);
+// creating it again is a no-op.
+func (db *VectorDB) CreateBinaryIndex() {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if db.binaryIndex {
+		return
+	}
+	start := time.Now()
+	db.binaryIndex = true
+	for _, v := range db.vectors {
+		v.code = signCode(db.floats(v, nil))
+	}
+	db.logIndexBuild("binary", len(db.vectors), start)
+}
+
+// DropBinaryIndex frees the sign codes kept by CreateBinaryIndex.
+func (db *VectorDB) DropBinaryIndex() {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.binaryIndex = false
+	for _, v := range db.vectors {
+		v.code = nil
+	}
+}
+
+// signCode packs the sign of each component of v, one bit per dimension (1 for > 0).
+func signCode(v []float32) []uint64 {
+	code := make([]uint64, (len(v)+63)/64)
+	for i, x := range v {
+		if x > 0 {
+			code[i/64] |= 1 << (i % 64)
+		}
+	}
+	return code
+}
+
+// hamming counts the differing bits among the first dims dimensions of two sign codes.
+func hamming(a, b []uint64, dims int) int {
+	n := 0
+	full := dims / 64
+	for i := 0; i < full; i++ {
+		n += bits.OnesCount64(a[i] ^ b[i])
+	}
+	if rem := dims % 64; rem > 0 {
+		n += bits.OnesCount64((a[full] ^ b[full]) & (1<<rem - 1))
+	}
+	return n
+}
@@ -0,0 +1,23 @@
+package lib
+
+import "testing"
+
+func TestHamming_CountsLeadingDimensionsOnly(t *testing.T) {
+	a := make([]float32, 130)
+	b := make([]float32, 130)
+	for i := range a {
+		a[i] = 1
+		if i%2 == 0 {
+			b[i] = 1
+		}
+	}
+	ca, cb := signCode(a), signCode(b)
+	if len(ca) != 3 {
+		t.Fatalf("130 dimensions need 3 words, got %d", len(ca))
+	}
+	for _, tc := range []struct{ dims, want int }{{130, 65}, {128, 64}, {65, 32}, {3, 1}, {0, 0}} {
+		if got := hamming(ca, cb, tc.dims); got != tc.want {
+			t.Errorf("hamming over %d dims = %d, want %d", tc.dims, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package lib
+
+import "fmt"
+
+// Plan chooses how a search finds its candidates; see SearchOptions.Plan. Every plan returns
+// the vectors the options ask for; they differ only in the work done to find them.
+type Plan int
+
+const (
+	// PlanAuto picks per query from the estimated candidate count: tag indexes whose
+	// conditions match over planScanShare of the vectors are not used, and a two-stage search
+	// whose shortlist would hold every candidate scores them exactly in one pass.
+	PlanAuto Plan = iota
+	// PlanScan ignores tag indexes and scores every candidate exactly in one pass, skipping
+	// the Binary or Dimensions first pass of a two-stage search.
+	PlanScan
+	// PlanIndex always narrows by tag indexes and runs the requested first pass.
+	PlanIndex
+)
+
+// planScanShare is the share of the live vectors above which PlanAuto scans rather than narrow
+// by an indexed condition: so broad a condition saves little scoring, and unioning the
+// postings of a wide range costs more than checking the condition on each vector.
+const planScanShare = 0.5
+
+// plan returns the validated SearchOptions.Plan.
+func (o *SearchOptions) plan() (Plan, error) {
+	if o == nil {
+		return PlanAuto, nil
+	}
+	if o.Plan < PlanAuto || o.Plan > PlanIndex {
+		return 0, fmt.Errorf("unknown plan %d", o.Plan)
+	}
+	return o.Plan, nil
+}
+
+// useIndex reports whether plan narrows by the index over c's key, holding values.
+func (db *VectorDB) useIndex(plan Plan, values valueSet, c *condition) bool {
+	switch plan {
+	case PlanScan:
+		return false
+	case PlanIndex:
+		return true
+	}
+	return float64(values.estimate(c)) <= planScanShare*float64(len(db.vectors))
+}
+
+// estimate returns an upper bound on the ordinals postings(c) would return, without building it.
+func (vs valueSet) estimate(c *condition) int {
+	n := 0
+	if operands, ok := c.equalityOperands(); ok {
+		for _, o := range operands {
+			if b, ok := vs[o]; ok {
+				n += b.len()
+			}
+		}
+		return n
+	}
+	for value, b := range vs {
+		if c.matchValue(value) {
+			n += b.len()
+		}
+	}
+	return n
+}
+
+// skipFirstPass makes p score every candidate with its final stage in one pass.
+func (p *pipeline) skipFirstPass(lowerIsBetter bool) {
+	p.scan, p.scanLowerIsBetter, p.refine = p.final, lowerIsBetter, 0
+}
@@ -0,0 +1,42 @@
+package lib
+
+import "sync"
+
+// maxPooled caps the length of buffers returned to the pools, so one search with a huge topK
+// or dimension does not keep that memory alive for every later one.
+const maxPooled = 1 << 14
+
+// Scratch buffers reused across searches so that high-QPS handlers allocate little more than
+// the results they return. Pools hold pointers so Put does not allocate.
+var (
+	resultPool = sync.Pool{New: func() any { return new([]SimilarityResult) }}
+	floatPool  = sync.Pool{New: func() any { return new([]float32) }}
+)
+
+// getResults returns an empty result buffer with room for at least n results.
+func getResults(n int) *[]SimilarityResult {
+	p := resultPool.Get().(*[]SimilarityResult)
+	if cap(*p) < n {
+		*p = make([]SimilarityResult, 0, n)
+	}
+	*p = (*p)[:0]
+	return p
+}
+
+// putResults clears buf, so pooled results do not pin metadata, and returns it to the pool.
+func putResults(p *[]SimilarityResult) {
+	if cap(*p) > maxPooled {
+		return
+	}
+	clear((*p)[:cap(*p)])
+	resultPool.Put(p)
+}
+
+// getFloats returns a decoding scratch buffer; floats grows it as needed.
+func getFloats() *[]float32 { return floatPool.Get().(*[]float32) }
+
+func putFloats(p *[]float32) {
+	if cap(*p) <= maxPooled {
+		floatPool.Put(p)
+	}
+}
@@ -0,0 +1,28 @@
+package lib
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestSearch_PooledBuffers(t *testing.T) {
+	db := NewVectorDB(4)
+	for i := range 1000 {
+		_ = db.Add(fmt.Sprint(i), []float32{float32(i % 7), float32(i % 11), 1, float32(i % 13)})
+	}
+	query := []float32{1, 2, 3, 4}
+	first, _ := db.Search(query, 5)
+	want := fmt.Sprint(first.Results)
+	for range 3 {
+		_, _ = db.Search([]float32{4, 3, 2, 1}, 5)
+	}
+	if got := fmt.Sprint(first.Results); got != want {
+		t.Fatalf("later searches changed returned results:\n%s\n%s", want, got)
+	}
+
+	allocs := testing.AllocsPerRun(100, func() { _, _ = db.Search(query, 10) })
+	t.Logf("%.0f allocations per search", allocs)
+	if allocs > 20 {
+		t.Errorf("search over 1000 vectors made %.0f allocations; buffers should be pooled", allocs)
+	}
+}
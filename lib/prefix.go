@@ -0,0 +1,61 @@
+package lib
+
+import (
+	"errors"
+	"sort"
+	"strings"
+)
+
+// IDsWithPrefix returns the IDs starting with prefix in ascending order, e.g. the chunk IDs
+// "doc42:0", "doc42:1", ... of one document for the prefix "doc42:".
+func (db *VectorDB) IDsWithPrefix(prefix string) []string {
+	db.mu.RLock()
+	var ids []string
+	for id := range db.vectors {
+		if strings.HasPrefix(id, prefix) {
+			ids = append(ids, id)
+		}
+	}
+	db.mu.RUnlock()
+	sort.Strings(ids)
+	return ids
+}
+
+// CountPrefix returns how many IDs start with prefix.
+func (db *VectorDB) CountPrefix(prefix string) int {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	n := 0
+	for id := range db.vectors {
+		if strings.HasPrefix(id, prefix) {
+			n++
+		}
+	}
+	return n
+}
+
+// DeletePrefix deletes every vector whose ID starts with prefix in one write and returns how
+// many were deleted, e.g. all chunks of a document before re-ingesting it. Hooks.OnDelete runs
+// for each ID first; if it fails for any, nothing is deleted. Use Clear to delete everything.
+func (db *VectorDB) DeletePrefix(prefix string) (int, error) {
+	if prefix == "" {
+		return 0, errors.New("prefix cannot be empty (use Clear)")
+	}
+	if h := db.hooks.Load(); h != nil && h.OnDelete != nil {
+		for _, id := range db.IDsWithPrefix(prefix) {
+			if err := h.OnDelete(id); err != nil {
+				return 0, err
+			}
+		}
+	}
+	db.mu.Lock()
+	defer db.unlock()
+	n := 0
+	for id, v := range db.vectors {
+		if strings.HasPrefix(id, prefix) {
+			db.removeLocked(v)
+			n++
+		}
+	}
+	return n, nil
+}
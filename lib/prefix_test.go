@@ -0,0 +1,49 @@
+package lib
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func TestPrefixOperations(t *testing.T) {
+	db := NewVectorDB(2)
+	for doc := range 3 {
+		for chunk := range 4 {
+			_ = db.Add(fmt.Sprintf("doc%d:%d", doc, chunk), []float32{float32(doc), float32(chunk)})
+		}
+	}
+	_ = db.Add("doc10:0", []float32{1, 1})
+
+	if n := db.CountPrefix("doc1:"); n != 4 {
+		t.Errorf("CountPrefix: got %d, want 4", n)
+	}
+	if ids := db.IDsWithPrefix("doc1"); !slices.Equal(ids, []string{"doc10:0", "doc1:0", "doc1:1", "doc1:2", "doc1:3"}) {
+		t.Errorf("IDsWithPrefix: got %v", ids)
+	}
+
+	var deleted []string
+	cancel := db.Subscribe(func(e Event) { deleted = append(deleted, e.ID) })
+	defer cancel()
+	if n, err := db.DeletePrefix("doc1:"); n != 4 || err != nil {
+		t.Fatalf("DeletePrefix: got %d, %v", n, err)
+	}
+	slices.Sort(deleted)
+	if !slices.Equal(deleted, []string{"doc1:0", "doc1:1", "doc1:2", "doc1:3"}) || db.Size() != 9 {
+		t.Errorf("expected the 4 chunks of doc1 deleted, events %v, size %d", deleted, db.Size())
+	}
+
+	db.SetHooks(&Hooks{OnDelete: func(id string) error {
+		if id == "doc2:3" {
+			return errors.New("locked")
+		}
+		return nil
+	}})
+	if n, err := db.DeletePrefix("doc2:"); n != 0 || err == nil || db.CountPrefix("doc2:") != 4 {
+		t.Errorf("a vetoed delete must delete nothing, got %d, %v", n, err)
+	}
+	if _, err := db.DeletePrefix(""); err == nil {
+		t.Error("an empty prefix must be rejected")
+	}
+}
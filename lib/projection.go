@@ -0,0 +1,73 @@
+package lib
+
+import (
+	"math"
+	"math/rand/v2"
+)
+
+// RandomProjection is a Transform that multiplies vectors by a fixed sparse random matrix
+// (Achlioptas: √(3/out)·{+1, 0, -1} with probabilities 1/6, 2/3, 1/6). By the
+// Johnson-Lindenstrauss lemma it keeps Euclidean distances within a factor 1±ε with high
+// probability for out around JLDimension(n, ε), needs no training, and applies in about a
+// third of the multiplications of a dense matrix.
+type RandomProjection struct {
+	in    int
+	scale float32
+	plus  [][]int32 // per output dimension, the inputs added
+	minus [][]int32 // per output dimension, the inputs subtracted
+}
+
+// NewRandomProjection returns the projection from in to out dimensions drawn from seed. The
+// same arguments always return the same projection, so a DB can be reopened with it after a
+// cold start.
+func NewRandomProjection(in, out int, seed uint64) *RandomProjection {
+	if in <= 0 || out <= 0 {
+		panic("projection dimensions must be > 0")
+	}
+	rng := rand.New(rand.NewPCG(seed, uint64(in)<<32|uint64(out)))
+	p := &RandomProjection{
+		in:    in,
+		scale: float32(math.Sqrt(3 / float64(out))),
+		plus:  make([][]int32, out),
+		minus: make([][]int32, out),
+	}
+	for i := range out {
+		for j := range in {
+			switch rng.IntN(6) {
+			case 0:
+				p.plus[i] = append(p.plus[i], int32(j))
+			case 1:
+				p.minus[i] = append(p.minus[i], int32(j))
+			}
+		}
+	}
+	return p
+}
+
+// Dimensions returns the input and output vector lengths.
+func (p *RandomProjection) Dimensions() (in, out int) { return p.in, len(p.plus) }
+
+// Apply writes the projection of v to dst.
+func (p *RandomProjection) Apply(dst, v []float32) {
+	for i := range dst {
+		var sum float32
+		for _, j := range p.plus[i] {
+			sum += v[j]
+		}
+		for _, j := range p.minus[i] {
+			sum -= v[j]
+		}
+		dst[i] = sum * p.scale
+	}
+}
+
+// JLDimension returns the output dimension at which a random projection of n vectors keeps
+// every pairwise Euclidean distance within a factor 1±eps with high probability (Dasgupta and
+// Gupta's bound, 4·ln n / (eps²/2 - eps³/3)). It is independent of the input dimension and
+// conservative in practice: search recall usually holds at a fraction of it.
+func JLDimension(n int, eps float64) int {
+	if n < 2 || eps <= 0 || eps >= 1 {
+		panic("JLDimension needs n >= 2 and 0 < eps < 1")
+	}
+	return int(math.Ceil(4 * math.Log(float64(n)) / (eps*eps/2 - eps*eps*eps/3)))
+}
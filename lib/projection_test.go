@@ -0,0 +1,62 @@
+package lib
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"testing"
+)
+
+func TestRandomProjection(t *testing.T) {
+	p := NewRandomProjection(512, 256, 42)
+	if in, out := p.Dimensions(); in != 512 || out != 256 {
+		t.Fatalf("unexpected dimensions %d, %d", in, out)
+	}
+	again := NewRandomProjection(512, 256, 42)
+	rng := rand.New(rand.NewPCG(3, 3))
+	vecs := make([][]float32, 20)
+	for i := range vecs {
+		vecs[i] = make([]float32, 512)
+		for j := range vecs[i] {
+			vecs[i][j] = float32(rng.NormFloat64())
+		}
+	}
+	a, b := make([]float32, 256), make([]float32, 256)
+	p.Apply(a, vecs[0])
+	again.Apply(b, vecs[0])
+	for i := range a {
+		if a[i] != b[i] {
+			t.Fatal("the same seed must give the same projection")
+		}
+	}
+
+	projected := make([][]float32, len(vecs))
+	for i, v := range vecs {
+		projected[i] = make([]float32, 256)
+		p.Apply(projected[i], v)
+	}
+	for i := range vecs {
+		for j := i + 1; j < len(vecs); j++ {
+			ratio := euclidean32(projected[i], projected[j]) / euclidean32(vecs[i], vecs[j])
+			if math.Abs(ratio-1) > 0.25 {
+				t.Errorf("distance %d-%d distorted by %.2f", i, j, ratio)
+			}
+		}
+	}
+
+	db := NewVectorDBWithOptions(&Options{Transform: p, Distance: EuclideanDistance})
+	for i, v := range vecs {
+		if err := db.Add(fmt.Sprint(i), v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if res, err := db.Search(vecs[3], 1); err != nil || res.Results[0].ID != "3" {
+		t.Errorf("queries must be projected like inserts: %+v, %v", res, err)
+	}
+}
+
+func TestJLDimension(t *testing.T) {
+	if d := JLDimension(10000, 0.1); d < 7000 || d > 8000 {
+		t.Errorf("expected about 7895 dimensions for 10000 vectors at 10%%, got %d", d)
+	}
+}
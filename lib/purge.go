@@ -0,0 +1,136 @@
+package lib
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"slices"
+	"time"
+)
+
+// PurgeReport records what PurgeWhere erased, signed so it can be kept as evidence for a
+// right-to-be-forgotten request and checked later with Verify.
+type PurgeReport struct {
+	IDs      []string  `json:"ids"`      // Vectors purged, live or soft-deleted, in order.
+	Versions int       `json:"versions"` // Previous versions purged (Options.KeepVersions), including those of vectors kept.
+	Seq      uint64    `json:"seq"`      // Seq after the purge: snapshots saved from then on no longer hold the vectors.
+	PurgedAt time.Time `json:"purged_at"`
+	// Signature is the hex HMAC-SHA256, under the key given to PurgeWhere, of the report's
+	// JSON encoding with Signature empty.
+	Signature string `json:"signature"`
+}
+
+// PurgeWhere permanently erases every vector filter accepts, live or soft-deleted, and every
+// retained previous version it accepts, and returns a report signed with key. Unlike Delete,
+// the erased data is zeroed in memory rather than left for its slot to be reused, and nothing
+// is left to Restore or RevertTo. Hooks.OnDelete runs for each live ID first; if it fails for
+// any, nothing is purged. filter gets the stored vector, as for SearchOptions.Filter, and must
+// not call db.
+//
+// Copies outside the DB are the caller's to erase: save a new snapshot (AutoSnapshot.Flush)
+// once Seq has passed the report's, and purge replicas and exports.
+func (db *VectorDB) PurgeWhere(key []byte, filter func(*Vector) bool) (*PurgeReport, error) {
+	if len(key) == 0 {
+		panic("purge key cannot be empty")
+	}
+	if filter == nil {
+		panic("purge filter cannot be nil")
+	}
+	db.mu.RLock()
+	var live []string
+	for _, v := range db.vectors {
+		if filter(v) {
+			live = append(live, v.ID)
+		}
+	}
+	db.mu.RUnlock()
+	slices.Sort(live)
+	for _, id := range live {
+		if err := db.deleteHook(id); err != nil {
+			return nil, err
+		}
+	}
+
+	db.mu.Lock()
+	var erase []*Vector
+	for _, v := range db.trash {
+		if filter(v) {
+			erase = append(erase, v)
+		}
+	}
+	for _, id := range live {
+		if v, ok := db.vectors[id]; ok && filter(v) { // not replaced since the hooks ran
+			erase = append(erase, v)
+		}
+	}
+	// IDs left without a vector lose every previous version; the others only those filter
+	// accepts.
+	drop := make(map[string]bool)
+	for _, v := range erase {
+		if _, live := db.vectors[v.ID]; !v.deleted || !live {
+			drop[v.ID] = true
+		}
+	}
+	r := &PurgeReport{}
+	for id, versions := range db.history {
+		kept := versions[:0]
+		for _, old := range versions {
+			if drop[id] || filter(old) {
+				clear(old.Data)
+				r.Versions++
+			} else {
+				kept = append(kept, old)
+			}
+		}
+		clear(versions[len(kept):])
+		if len(kept) == 0 {
+			delete(db.history, id)
+		} else {
+			db.history[id] = kept
+		}
+	}
+	for _, v := range erase {
+		if v.deleted {
+			db.vacuumOneLocked(v)
+		} else {
+			db.removeLocked(v)
+		}
+		scrub(v)
+		r.IDs = append(r.IDs, v.ID)
+	}
+	db.unlock()
+
+	slices.Sort(r.IDs)
+	r.IDs = slices.Compact(r.IDs) // an ID both soft-deleted and re-added
+	r.Seq = db.Seq()
+	r.PurgedAt = time.Now().UTC()
+	r.Signature = r.sign(key)
+	return r, nil
+}
+
+// Verify reports whether the report is unchanged since PurgeWhere signed it with key.
+func (r *PurgeReport) Verify(key []byte) bool {
+	want, err := hex.DecodeString(r.Signature)
+	if err != nil {
+		return false
+	}
+	got, _ := hex.DecodeString(r.sign(key))
+	return hmac.Equal(got, want)
+}
+
+func (r *PurgeReport) sign(key []byte) string {
+	unsigned := *r
+	unsigned.Signature = ""
+	data, _ := json.Marshal(unsigned) // cannot fail: no maps, channels or funcs
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// scrub zeroes v's data, in its arena slot if it has one, and its binary code.
+func scrub(v *Vector) {
+	clear(v.Data)
+	clear(v.packed)
+	clear(v.code)
+}
@@ -0,0 +1,62 @@
+package lib
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestPurgeWhere(t *testing.T) {
+	db := NewVectorDBWithOptions(&Options{Dimension: 2, KeepVersions: 2})
+	user := func(u string) VectorMetadata { return VectorMetadata{Tags: map[string]string{"user": u}} }
+	_ = db.Add("a", []float32{1, 2}, user("u1"))
+	_ = db.Add("b", []float32{3, 4}, user("u1"))
+	_ = db.Add("c", []float32{5, 6}, user("u2"))
+	_ = db.Add("moved", []float32{7, 8}, user("u1"))
+	_ = db.Update("moved", []float32{7, 9}, user("u2")) // version 1 still belongs to u1
+	_ = db.Update("a", []float32{1, 3}, user("u1"))
+	_ = db.SoftDelete("b")
+	data := db.vectors["a"].Data // the arena slot
+	byU1 := func(v *Vector) bool { return v.Metadata.Tags["user"] == "u1" }
+
+	key := []byte("secret")
+	r, err := db.PurgeWhere(key, byU1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(r.IDs, []string{"a", "b"}) || r.Versions != 2 || r.Seq != db.Seq() {
+		t.Errorf("unexpected report %+v", r)
+	}
+	if !slices.Equal(data, []float32{0, 0}) {
+		t.Errorf("purged data must be zeroed, got %v", data)
+	}
+	if db.Size() != 2 || db.SoftDeleted() != 0 || db.Restore("b") == nil {
+		t.Errorf("expected a and b gone for good, size %d", db.Size())
+	}
+	if db.Versions("moved") != 0 || db.Versions("a") != 0 {
+		t.Error("versions accepted by the filter must be purged")
+	}
+	if v, _ := db.Get("moved"); v.Data[1] != 9 {
+		t.Errorf("the current version of moved must be kept, got %v", v.Data)
+	}
+
+	if !r.Verify(key) || r.Verify([]byte("other")) {
+		t.Error("the report must verify with its key only")
+	}
+	raw, _ := json.Marshal(r)
+	var decoded PurgeReport
+	if err := json.Unmarshal(raw, &decoded); err != nil || !decoded.Verify(key) {
+		t.Errorf("a report must still verify after a JSON round trip: %v", err)
+	}
+	decoded.IDs = decoded.IDs[:1]
+	if decoded.Verify(key) {
+		t.Error("a tampered report must not verify")
+	}
+
+	veto := errors.New("veto")
+	db.SetHooks(&Hooks{OnDelete: func(string) error { return veto }})
+	if _, err := db.PurgeWhere(key, func(*Vector) bool { return true }); !errors.Is(err, veto) || db.Size() != 2 {
+		t.Errorf("a failing OnDelete must purge nothing, got %v, size %d", err, db.Size())
+	}
+}
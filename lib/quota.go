@@ -0,0 +1,168 @@
+package lib
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Quota limits one tenant or caller. Zero fields are unlimited.
+type Quota struct {
+	MaxVectors   int     // Vectors the store may hold; Add of a new ID beyond it fails.
+	MaxQPS       float64 // Calls per second, with bursts of up to max(1, MaxQPS) calls.
+	MaxTopK      int     // Largest topK a search may ask for (counting Offset).
+	MaxDimension int     // Longest vector Add and Update accept.
+}
+
+// QuotaError reports the Quota limit, or Options cap, a call exceeded. Match it with errors.As.
+type QuotaError struct {
+	Limit string  // "MaxVectors", "MaxQPS", "MaxTopK", "MaxDimension" or "MaxMetadataBytes"
+	Value float64 // What the call needed; 0 for MaxQPS
+	Max   float64 // What the quota allows
+}
+
+func (e *QuotaError) Error() string {
+	if e.Limit == "MaxQPS" {
+		return fmt.Sprintf("quota exceeded: more than %g calls per second (MaxQPS)", e.Max)
+	}
+	return fmt.Sprintf("quota exceeded: needs %g, %s is %g", e.Value, e.Limit, e.Max)
+}
+
+// QuotaStore enforces a Quota on the calls made through it to a VectorStore, so several
+// tenants can share a function without one starving or exhausting the others: give each
+// tenant its own QuotaStore, over its own DB or shard. Calls over quota fail with a
+// *QuotaError before reaching the store. It is safe for concurrent use.
+type QuotaStore struct {
+	store VectorStore
+	quota Quota
+
+	mu     sync.Mutex // guards tokens and last; held across Add when MaxVectors is set
+	tokens float64
+	last   time.Time
+}
+
+var _ VectorStore = (*QuotaStore)(nil)
+
+// NewQuotaStore returns a QuotaStore enforcing quota on store.
+func NewQuotaStore(store VectorStore, quota Quota) *QuotaStore {
+	return &QuotaStore{store: store, quota: quota, tokens: max(1, quota.MaxQPS), last: time.Now()}
+}
+
+// allow takes one call from the token bucket, refilled at MaxQPS per second.
+func (q *QuotaStore) allow() error {
+	if q.quota.MaxQPS <= 0 {
+		return nil
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	now := time.Now()
+	q.tokens = min(max(1, q.quota.MaxQPS), q.tokens+now.Sub(q.last).Seconds()*q.quota.MaxQPS)
+	q.last = now
+	if q.tokens < 1 {
+		return &QuotaError{Limit: "MaxQPS", Max: q.quota.MaxQPS}
+	}
+	q.tokens--
+	return nil
+}
+
+// checkDimension rejects data longer than MaxDimension.
+func (q *QuotaStore) checkDimension(data any) error {
+	var n int
+	switch v := data.(type) {
+	case []float32:
+		n = len(v)
+	case []int8:
+		n = len(v)
+	case []uint8:
+		n = len(v)
+	}
+	if q.quota.MaxDimension > 0 && n > q.quota.MaxDimension {
+		return &QuotaError{Limit: "MaxDimension", Value: float64(n), Max: float64(q.quota.MaxDimension)}
+	}
+	return nil
+}
+
+// Add adds a vector as VectorStore.Add, unless it is a new ID and the store already holds
+// MaxVectors.
+func (q *QuotaStore) Add(id string, data any, metadata ...VectorMetadata) error {
+	if err := q.allow(); err != nil {
+		return err
+	}
+	if err := q.checkDimension(data); err != nil {
+		return err
+	}
+	if q.quota.MaxVectors <= 0 {
+		return q.store.Add(id, data, metadata...)
+	}
+	q.mu.Lock() // so concurrent Adds cannot both take the last slot
+	defer q.mu.Unlock()
+	if n := q.store.Size(); n >= q.quota.MaxVectors {
+		if _, err := q.store.Get(id); err != nil {
+			return &QuotaError{Limit: "MaxVectors", Value: float64(n + 1), Max: float64(q.quota.MaxVectors)}
+		}
+	}
+	return q.store.Add(id, data, metadata...)
+}
+
+// Update updates a vector as VectorStore.Update.
+func (q *QuotaStore) Update(id string, data any, metadata ...VectorMetadata) error {
+	if err := q.allow(); err != nil {
+		return err
+	}
+	if err := q.checkDimension(data); err != nil {
+		return err
+	}
+	return q.store.Update(id, data, metadata...)
+}
+
+// Get returns a vector as VectorStore.Get.
+func (q *QuotaStore) Get(id string) (*Vector, error) {
+	if err := q.allow(); err != nil {
+		return nil, err
+	}
+	return q.store.Get(id)
+}
+
+// Delete removes a vector as VectorStore.Delete.
+func (q *QuotaStore) Delete(id string) error {
+	if err := q.allow(); err != nil {
+		return err
+	}
+	return q.store.Delete(id)
+}
+
+// Size returns the number of vectors in the store. It is not counted against MaxQPS.
+func (q *QuotaStore) Size() int {
+	return q.store.Size()
+}
+
+// Search is SearchWithOptions with default options.
+func (q *QuotaStore) Search(query any, topK ...int) (*SearchResult, error) {
+	k := 10
+	if len(topK) > 0 {
+		k = topK[0]
+	}
+	return q.SearchWithOptions(query, k, nil)
+}
+
+// SearchWithOptions searches as VectorStore.SearchWithOptions, unless Offset plus topK
+// exceeds MaxTopK.
+func (q *QuotaStore) SearchWithOptions(query any, topK int, opts *SearchOptions) (*SearchResult, error) {
+	if err := q.allow(); err != nil {
+		return nil, err
+	}
+	if q.quota.MaxTopK > 0 {
+		offset, err := opts.offset()
+		if err != nil {
+			return nil, err
+		}
+		k := topK
+		if k <= 0 {
+			k = 10 // the store's default
+		}
+		if offset > q.quota.MaxTopK-k {
+			return nil, &QuotaError{Limit: "MaxTopK", Value: float64(offset) + float64(k), Max: float64(q.quota.MaxTopK)}
+		}
+	}
+	return q.store.SearchWithOptions(query, topK, opts)
+}
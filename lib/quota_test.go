@@ -0,0 +1,51 @@
+package lib
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestQuotaStore(t *testing.T) {
+	q := NewQuotaStore(NewVectorDB(0), Quota{MaxVectors: 2, MaxTopK: 5, MaxDimension: 3})
+	if err := q.Add("a", []float32{1, 2, 3}); err != nil {
+		t.Fatal(err)
+	}
+	var qe *QuotaError
+	if err := q.Add("b", []float32{1, 2, 3, 4}); !errors.As(err, &qe) || qe.Limit != "MaxDimension" || qe.Value != 4 {
+		t.Errorf("expected a MaxDimension error, got %v", err)
+	}
+	_ = q.Add("b", []float32{3, 2, 1})
+	if err := q.Add("c", []float32{1, 1, 1}); !errors.As(err, &qe) || qe.Limit != "MaxVectors" {
+		t.Errorf("expected a MaxVectors error, got %v", err)
+	}
+	if err := q.Add("a", []float32{1, 1, 1}); err != nil {
+		t.Errorf("replacing a stored ID must not count against MaxVectors: %v", err)
+	}
+	if _, err := q.Search([]float32{1, 1, 1}, 5); err != nil {
+		t.Error(err)
+	}
+	if _, err := q.SearchWithOptions([]float32{1, 1, 1}, 3, &SearchOptions{Offset: 3}); !errors.As(err, &qe) || qe.Limit != "MaxTopK" || qe.Value != 6 {
+		t.Errorf("expected a MaxTopK error counting Offset, got %v", err)
+	}
+	if _, err := q.Search([]float32{1, 1, 1}); !errors.As(err, &qe) || qe.Value != 10 {
+		t.Errorf("the default topK must be checked, got %v", err)
+	}
+}
+
+func TestQuotaStore_MaxQPS(t *testing.T) {
+	q := NewQuotaStore(NewVectorDB(2), Quota{MaxQPS: 100})
+	allowed := 0
+	for range 150 {
+		if _, err := q.Get("missing"); err == nil || !errors.As(err, new(*QuotaError)) {
+			allowed++
+		}
+	}
+	if allowed < 100 || allowed > 105 {
+		t.Errorf("a burst must allow about MaxQPS calls, got %d", allowed)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if err := q.Delete("missing"); errors.As(err, new(*QuotaError)) {
+		t.Errorf("tokens must refill over time, got %v", err)
+	}
+}
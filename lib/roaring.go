@@ -0,0 +1,291 @@
+package lib
+
+import (
+	"iter"
+	"math/bits"
+	"slices"
+)
+
+// bitmap is a compressed set of uint32 in the Roaring layout: values are split by their high
+// 16 bits into containers, each a sorted []uint16 while small and a 65536-bit bitset once it
+// holds more than arrayMax values. Metadata indexes store vector ordinals in bitmaps so that
+// selective filters intersect in a few word operations instead of walking ID sets.
+type bitmap struct {
+	keys  []uint16 // sorted high bits
+	conts []*container
+}
+
+// arrayMax is the largest array container; beyond it a bitset is smaller.
+const arrayMax = 4096
+
+type container struct {
+	array []uint16 // sorted; used while bits is nil
+	bits  []uint64 // 1024 words once the container holds more than arrayMax values
+	n     int
+}
+
+func (b *bitmap) add(x uint32) {
+	hi, lo := uint16(x>>16), uint16(x)
+	i, found := slices.BinarySearch(b.keys, hi)
+	if !found {
+		b.keys = slices.Insert(b.keys, i, hi)
+		b.conts = slices.Insert(b.conts, i, &container{})
+	}
+	b.conts[i].add(lo)
+}
+
+func (b *bitmap) remove(x uint32) {
+	hi, lo := uint16(x>>16), uint16(x)
+	i, found := slices.BinarySearch(b.keys, hi)
+	if !found {
+		return
+	}
+	c := b.conts[i]
+	c.remove(lo)
+	if c.n == 0 {
+		b.keys = slices.Delete(b.keys, i, i+1)
+		b.conts = slices.Delete(b.conts, i, i+1)
+	}
+}
+
+func (b *bitmap) contains(x uint32) bool {
+	i, found := slices.BinarySearch(b.keys, uint16(x>>16))
+	return found && b.conts[i].contains(uint16(x))
+}
+
+// len returns the number of values in b.
+func (b *bitmap) len() int {
+	n := 0
+	for _, c := range b.conts {
+		n += c.n
+	}
+	return n
+}
+
+// and returns the intersection of b and o as a new bitmap.
+func (b *bitmap) and(o *bitmap) *bitmap {
+	out := &bitmap{}
+	for i, j := 0, 0; i < len(b.keys) && j < len(o.keys); {
+		switch {
+		case b.keys[i] < o.keys[j]:
+			i++
+		case b.keys[i] > o.keys[j]:
+			j++
+		default:
+			if c := andContainers(b.conts[i], o.conts[j]); c.n > 0 {
+				out.keys = append(out.keys, b.keys[i])
+				out.conts = append(out.conts, c)
+			}
+			i++
+			j++
+		}
+	}
+	return out
+}
+
+// or returns the union of b and o as a new bitmap.
+func (b *bitmap) or(o *bitmap) *bitmap {
+	out := &bitmap{}
+	i, j := 0, 0
+	for i < len(b.keys) || j < len(o.keys) {
+		switch {
+		case j == len(o.keys) || i < len(b.keys) && b.keys[i] < o.keys[j]:
+			out.keys = append(out.keys, b.keys[i])
+			out.conts = append(out.conts, b.conts[i].clone())
+			i++
+		case i == len(b.keys) || b.keys[i] > o.keys[j]:
+			out.keys = append(out.keys, o.keys[j])
+			out.conts = append(out.conts, o.conts[j].clone())
+			j++
+		default:
+			out.keys = append(out.keys, b.keys[i])
+			out.conts = append(out.conts, orContainers(b.conts[i], o.conts[j]))
+			i++
+			j++
+		}
+	}
+	return out
+}
+
+// all yields the values of b in ascending order.
+func (b *bitmap) all() iter.Seq[uint32] {
+	return func(yield func(uint32) bool) {
+		for i, c := range b.conts {
+			hi := uint32(b.keys[i]) << 16
+			for lo := range c.all() {
+				if !yield(hi | uint32(lo)) {
+					return
+				}
+			}
+		}
+	}
+}
+
+func (c *container) add(v uint16) {
+	if c.bits != nil {
+		w, m := v>>6, uint64(1)<<(v&63)
+		if c.bits[w]&m == 0 {
+			c.bits[w] |= m
+			c.n++
+		}
+		return
+	}
+	i, found := slices.BinarySearch(c.array, v)
+	if found {
+		return
+	}
+	c.array = slices.Insert(c.array, i, v)
+	c.n++
+	if c.n > arrayMax {
+		c.toBits()
+	}
+}
+
+func (c *container) remove(v uint16) {
+	if c.bits != nil {
+		w, m := v>>6, uint64(1)<<(v&63)
+		if c.bits[w]&m != 0 {
+			c.bits[w] &^= m
+			c.n--
+			if c.n <= arrayMax {
+				c.toArray()
+			}
+		}
+		return
+	}
+	if i, found := slices.BinarySearch(c.array, v); found {
+		c.array = slices.Delete(c.array, i, i+1)
+		c.n--
+	}
+}
+
+func (c *container) contains(v uint16) bool {
+	if c.bits != nil {
+		return c.bits[v>>6]&(uint64(1)<<(v&63)) != 0
+	}
+	_, found := slices.BinarySearch(c.array, v)
+	return found
+}
+
+func (c *container) toBits() {
+	c.bits = make([]uint64, 1024)
+	for _, v := range c.array {
+		c.bits[v>>6] |= uint64(1) << (v & 63)
+	}
+	c.array = nil
+}
+
+func (c *container) toArray() {
+	c.array = slices.Collect(c.all())
+	c.bits = nil
+}
+
+func (c *container) clone() *container {
+	return &container{array: slices.Clone(c.array), bits: slices.Clone(c.bits), n: c.n}
+}
+
+func (c *container) all() iter.Seq[uint16] {
+	return func(yield func(uint16) bool) {
+		if c.bits == nil {
+			for _, v := range c.array {
+				if !yield(v) {
+					return
+				}
+			}
+			return
+		}
+		for w, word := range c.bits {
+			for word != 0 {
+				t := bits.TrailingZeros64(word)
+				if !yield(uint16(w<<6 + t)) {
+					return
+				}
+				word &= word - 1
+			}
+		}
+	}
+}
+
+func andContainers(a, b *container) *container {
+	switch {
+	case a.bits != nil && b.bits != nil:
+		out := &container{bits: make([]uint64, 1024)}
+		for w := range out.bits {
+			out.bits[w] = a.bits[w] & b.bits[w]
+			out.n += bits.OnesCount64(out.bits[w])
+		}
+		if out.n <= arrayMax {
+			out.toArray()
+		}
+		return out
+	case a.bits != nil:
+		a, b = b, a
+		fallthrough
+	case b.bits != nil:
+		out := &container{}
+		for _, v := range a.array {
+			if b.contains(v) {
+				out.array = append(out.array, v)
+			}
+		}
+		out.n = len(out.array)
+		return out
+	}
+	out := &container{}
+	for i, j := 0, 0; i < len(a.array) && j < len(b.array); {
+		switch {
+		case a.array[i] < b.array[j]:
+			i++
+		case a.array[i] > b.array[j]:
+			j++
+		default:
+			out.array = append(out.array, a.array[i])
+			i++
+			j++
+		}
+	}
+	out.n = len(out.array)
+	return out
+}
+
+func orContainers(a, b *container) *container {
+	if a.bits == nil && b.bits == nil && a.n+b.n <= arrayMax {
+		out := &container{array: make([]uint16, 0, a.n+b.n)}
+		i, j := 0, 0
+		for i < len(a.array) || j < len(b.array) {
+			switch {
+			case j == len(b.array) || i < len(a.array) && a.array[i] < b.array[j]:
+				out.array = append(out.array, a.array[i])
+				i++
+			case i == len(a.array) || a.array[i] > b.array[j]:
+				out.array = append(out.array, b.array[j])
+				j++
+			default:
+				out.array = append(out.array, a.array[i])
+				i++
+				j++
+			}
+		}
+		out.n = len(out.array)
+		return out
+	}
+	out := &container{bits: make([]uint64, 1024)}
+	for _, c := range []*container{a, b} {
+		if c.bits != nil {
+			for w, word := range c.bits {
+				out.bits[w] |= word
+			}
+			continue
+		}
+		for _, v := range c.array {
+			out.bits[v>>6] |= uint64(1) << (v & 63)
+		}
+	}
+	for _, word := range out.bits {
+		out.n += bits.OnesCount64(word)
+	}
+	if out.n <= arrayMax {
+		out.toArray()
+	}
+	return out
+}
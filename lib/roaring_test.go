@@ -0,0 +1,71 @@
+package lib
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+// randomBitmap builds a bitmap and its reference set. Values cluster in a few containers so
+// both array and bitset containers are exercised.
+func randomBitmap(rng *rand.Rand, n int) (*bitmap, map[uint32]bool) {
+	b, ref := &bitmap{}, make(map[uint32]bool)
+	for range n {
+		x := uint32(rng.Intn(3))<<16 | uint32(rng.Intn(12000))
+		b.add(x)
+		ref[x] = true
+	}
+	return b, ref
+}
+
+func checkBitmap(t *testing.T, name string, b *bitmap, ref map[uint32]bool) {
+	t.Helper()
+	if b.len() != len(ref) {
+		t.Fatalf("%s: len %d, want %d", name, b.len(), len(ref))
+	}
+	got := slices.Collect(b.all())
+	if !slices.IsSorted(got) {
+		t.Fatalf("%s: values not ascending", name)
+	}
+	for _, x := range got {
+		if !ref[x] || !b.contains(x) {
+			t.Fatalf("%s: unexpected value %d", name, x)
+		}
+	}
+}
+
+func TestBitmap_AgainstReference(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for _, n := range []int{0, 10, 3000, 20000} {
+		a, ra := randomBitmap(rng, n)
+		b, rb := randomBitmap(rng, n/2+5000)
+		checkBitmap(t, "a", a, ra)
+
+		and, or := map[uint32]bool{}, map[uint32]bool{}
+		for x := range ra {
+			or[x] = true
+			if rb[x] {
+				and[x] = true
+			}
+		}
+		for x := range rb {
+			or[x] = true
+		}
+		checkBitmap(t, "and", a.and(b), and)
+		checkBitmap(t, "or", a.or(b), or)
+
+		// Remove most values so bitset containers shrink back to arrays.
+		for x := range ra {
+			if rng.Intn(10) > 0 {
+				a.remove(x)
+				delete(ra, x)
+			}
+		}
+		checkBitmap(t, "after remove", a, ra)
+		for _, c := range a.conts {
+			if c.bits != nil && c.n <= arrayMax {
+				t.Fatalf("container with %d values should be an array", c.n)
+			}
+		}
+	}
+}
@@ -4,8 +4,11 @@ import (
 	"container/heap"
 	"errors"
 	"fmt"
+	"maps"
 	"math"
-	"sort"
+	"slices"
+	"sync"
+	"time"
 )
 
 // resultHeap keeps the top K results by score. For similarity (higher better), root is min score;
@@ -18,11 +21,33 @@ type resultHeap struct {
 func (h resultHeap) Len() int      { return len(h.results) }
 func (h resultHeap) Swap(i, j int) { h.results[i], h.results[j] = h.results[j], h.results[i] }
 func (h resultHeap) Less(i, j int) bool {
-	if h.lowerIsBetter {
-		return h.results[i].Score > h.results[j].Score // max at root for distance
+	return ranksBefore(h.results[j], h.results[i], h.lowerIsBetter) // worst result at root
+}
+
+// ranksBefore reports whether a ranks ahead of b: better score first, then ascending ID,
+// so results with equal scores come back in the same order on every run.
+func ranksBefore(a, b SimilarityResult, lowerIsBetter bool) bool {
+	if a.Score != b.Score {
+		if lowerIsBetter {
+			return a.Score < b.Score
+		}
+		return a.Score > b.Score
 	}
-	return h.results[i].Score < h.results[j].Score // min at root for similarity
+	return a.ID < b.ID
 }
+
+// offer adds r if the heap holds fewer than keep results or r ranks ahead of the worst.
+// It fixes the heap in place rather than using heap.Push, which would box r.
+func (h *resultHeap) offer(r SimilarityResult, keep int) {
+	if h.Len() < keep {
+		h.results = append(h.results, r)
+		heap.Fix(h, h.Len()-1)
+	} else if ranksBefore(r, h.results[0], h.lowerIsBetter) {
+		h.results[0] = r
+		heap.Fix(h, 0)
+	}
+}
+
 func (h *resultHeap) Push(x any) { h.results = append(h.results, x.(SimilarityResult)) }
 func (h *resultHeap) Pop() any {
 	n := len(h.results)
@@ -47,16 +72,145 @@ func (db *VectorDB) SearchWithFilter(query any, topK int, filter func(*Vector) b
 	if topK <= 0 {
 		topK = 10
 	}
-	return db.searchCore(query, topK, true, filter)
+	return db.searchCore(query, topK, true, &SearchOptions{Filter: filter})
 }
 
-// BatchSearch performs search on multiple queries efficiently
+// SearchWithOptions performs similarity search configured by opts (nil for plain Search).
+func (db *VectorDB) SearchWithOptions(query any, topK int, opts *SearchOptions) (*SearchResult, error) {
+	if topK <= 0 {
+		topK = 10
+	}
+	return db.searchCore(query, topK, true, opts)
+}
+
+// compile validates o and returns its conditions (Where plus Tags) and one predicate combining
+// them with Filter and ExcludeIDs. The predicate is nil when there is nothing to check.
+func (o *SearchOptions) compile() ([]condition, func(*Vector) bool, error) {
+	if o == nil {
+		return nil, nil, nil
+	}
+	conds := make([]condition, 0, len(o.Where)+len(o.Tags))
+	for _, w := range o.Where {
+		c, err := w.compile()
+		if err != nil {
+			return nil, nil, err
+		}
+		conds = append(conds, c)
+	}
+	for key, value := range o.Tags {
+		conds = append(conds, condition{key: key, op: OpEq, operands: []any{value}, tagOnly: true})
+	}
+	if len(o.ExcludeIDs) == 0 && len(conds) == 0 {
+		return nil, o.Filter, nil
+	}
+	exclude := make(map[string]struct{}, len(o.ExcludeIDs))
+	for _, id := range o.ExcludeIDs {
+		exclude[id] = struct{}{}
+	}
+	filter := o.Filter
+	return conds, func(v *Vector) bool {
+		if _, skip := exclude[v.ID]; skip {
+			return false
+		}
+		for i := range conds {
+			if !conds[i].match(v) {
+				return false
+			}
+		}
+		return filter == nil || filter(v)
+	}, nil
+}
+
+// distanceTo returns the function scoring stored vectors against query32 on their first dims
+// dimensions, applying opts.Weights when set. Callers check 0 < dims <= len(query32).
+func (db *VectorDB) distanceTo(query32 []float32, opts *SearchOptions, dims int) (func([]float32) float64, error) {
+	if db.distFunc == MahalanobisDistance {
+		return db.mahalanobisTo(query32, opts, dims)
+	}
+	q := query32[:dims]
+	if opts == nil || opts.Weights == nil {
+		return func(v []float32) float64 { return db.distanceFloat32(q, v[:dims], db.distFunc) }, nil
+	}
+	w := opts.Weights
+	if len(w) != len(query32) {
+		return nil, fmt.Errorf("weights length %d does not match query dimension %d", len(w), len(query32))
+	}
+	for i, x := range w {
+		if x < 0 || math.IsNaN(float64(x)) {
+			return nil, fmt.Errorf("weight %d must be >= 0, got %v", i, x)
+		}
+	}
+	w = w[:dims]
+	return func(v []float32) float64 { return weightedDistance32(q, v[:dims], w, db.distFunc) }, nil
+}
+
+// nearDuplicateEpsilon is how close a vector must be to the source to count as its duplicate in MoreLikeThis.
+const nearDuplicateEpsilon = 1e-6
+
+// MoreLikeThis searches with the stored vector id as the query, as stored (after any
+// Transform), and runs the search hooks and slow log like Search. With excludeSelf, the source
+// and its near-duplicates (cosine similarity within 1e-6 of 1 for CosineSimilarity, otherwise
+// Euclidean distance within 1e-6 of the source's norm) are skipped.
+func (db *VectorDB) MoreLikeThis(id string, topK int, excludeSelf bool) (*SearchResult, error) {
+	if topK <= 0 {
+		topK = 10
+	}
+	db.mu.RLock()
+	src, ok := db.vectors[id]
+	var query []float32
+	if ok {
+		query = db.floatsCopy(src)
+	}
+	db.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("vector with ID %s not found", id)
+	}
+	var filter func(*Vector) bool
+	if excludeSelf {
+		tol := nearDuplicateEpsilon * math.Max(1, norm32(query))
+		var buf []float32
+		filter = func(v *Vector) bool {
+			if v.ID == id {
+				return false
+			}
+			if v.Dimension != len(query) {
+				return true
+			}
+			data := db.floats(v, &buf)
+			if db.distFunc == CosineSimilarity {
+				return DistanceFloat32(query, data, CosineSimilarity) < 1-nearDuplicateEpsilon
+			}
+			return euclidean32(query, data) > tol
+		}
+	}
+	opts := &SearchOptions{Filter: filter}
+	return db.observed(query, topK, opts, func() (*SearchResult, error) {
+		db.mu.RLock()
+		defer db.mu.RUnlock()
+		res, err := db.searchLocked(query, topK, true, opts)
+		if res != nil {
+			res.QueryID = id // before OnSearchDone sees it
+		}
+		return res, err
+	})
+}
+
+// BatchSearch performs search on multiple queries efficiently: from batchMinQueries queries on,
+// they are scored together in one blocked pass over the vectors rather than one scan each.
 func (db *VectorDB) BatchSearch(queries map[string]any, topK ...int) (map[string]*SearchResult, error) {
 	k := 10 // smart default
 	if len(topK) > 0 {
 		k = topK[0]
 	}
 
+	if len(queries) >= batchMinQueries {
+		results, errs := db.searchBatch(queries, k)
+		if len(errs) > 0 {
+			id := slices.Min(slices.Collect(maps.Keys(errs)))
+			return nil, fmt.Errorf("search failed for query %s: %v", id, errs[id])
+		}
+		return results, nil
+	}
 	results := make(map[string]*SearchResult)
 	for queryID, query := range queries {
 		result, err := db.searchCore(query, k, true, nil)
@@ -69,6 +223,64 @@ func (db *VectorDB) BatchSearch(queries map[string]any, topK ...int) (map[string
 	return results, nil
 }
 
+// BatchSearchOptions configures BatchSearchWithOptions. Nil or zero values use defaults.
+type BatchSearchOptions struct {
+	// Concurrency is how many queries run at once. Default 1 runs them one after another,
+	// which is fastest on a single vCPU; raise it only with 2+ vCPUs.
+	Concurrency int
+	Search      *SearchOptions // Applied to every query.
+}
+
+// BatchSearchWithOptions runs every query like SearchWithOptions, on up to opts.Concurrency
+// goroutines. Unlike BatchSearch, a failed query does not fail the batch: results holds the
+// queries that succeeded and errs (nil if none failed) the error of each one that did. Without
+// Search options and concurrency, queries are scored together as in BatchSearch.
+func (db *VectorDB) BatchSearchWithOptions(queries map[string]any, topK int, opts *BatchSearchOptions) (results map[string]*SearchResult, errs map[string]error) {
+	if topK <= 0 {
+		topK = 10
+	}
+	if opts == nil {
+		opts = &BatchSearchOptions{}
+	}
+	if opts.Search == nil && opts.Concurrency <= 1 && len(queries) >= batchMinQueries {
+		return db.searchBatch(queries, topK)
+	}
+	type job struct {
+		id    string
+		query any
+	}
+	jobs := make(chan job)
+	var mu sync.Mutex
+	results = make(map[string]*SearchResult, len(queries))
+	var wg sync.WaitGroup
+	for range min(max(opts.Concurrency, 1), max(len(queries), 1)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				res, err := db.searchCore(j.query, topK, true, opts.Search)
+				mu.Lock()
+				if err != nil {
+					if errs == nil {
+						errs = make(map[string]error)
+					}
+					errs[j.id] = err
+				} else {
+					res.QueryID = j.id
+					results[j.id] = res
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for id, q := range queries {
+		jobs <- job{id, q}
+	}
+	close(jobs)
+	wg.Wait()
+	return results, errs
+}
+
 // SearchMMR performs Maximal Marginal Relevance search. Call with (query, topK) for defaults;
 // pass optional *MMROptions to tune. Results are relevant to the query but diverse from each other.
 func (db *VectorDB) SearchMMR(query any, topK int, opts ...*MMROptions) (*SearchResult, error) {
@@ -157,7 +369,7 @@ func (db *VectorDB) SearchMMRWithScores(query any, topK int, baseScores map[stri
 	for _, r := range candidates.Results {
 		v, ok := db.vectors[r.ID]
 		if ok {
-			candVecs[r.ID] = v.Data
+			candVecs[r.ID] = db.floatsCopy(v)
 		}
 	}
 	db.mu.RUnlock()
@@ -167,12 +379,10 @@ func (db *VectorDB) SearchMMRWithScores(query any, topK int, baseScores map[stri
 
 	// 3. Compute relevance based on scoreMode
 	toRelevance := func(score float64) float64 {
-		switch db.distFunc {
-		case EuclideanDistance, ManhattanDistance:
+		if db.distFunc.lowerIsBetter() {
 			return 1.0 / (1.0 + score)
-		default:
-			return score
 		}
+		return score
 	}
 
 	relevance := make(map[string]float64, len(candidates.Results))
@@ -234,7 +444,7 @@ func (db *VectorDB) searchMMRCore(query any, topK int, lambda float64, ff int) (
 	for _, r := range candidates.Results {
 		v, ok := db.vectors[r.ID]
 		if ok {
-			candVecs[r.ID] = v.Data
+			candVecs[r.ID] = db.floatsCopy(v)
 		}
 	}
 	db.mu.RUnlock()
@@ -243,12 +453,10 @@ func (db *VectorDB) searchMMRCore(query any, topK int, lambda float64, ff int) (
 	}
 
 	toRelevance := func(score float64) float64 {
-		switch db.distFunc {
-		case EuclideanDistance, ManhattanDistance:
+		if db.distFunc.lowerIsBetter() {
 			return 1.0 / (1.0 + score)
-		default:
-			return score
 		}
+		return score
 	}
 
 	relevance := make(map[string]float64, len(candidates.Results))
@@ -271,12 +479,10 @@ func mmrGreedy(
 	distFunc DistanceFunction,
 ) (*SearchResult, error) {
 	toRelevance := func(score float64) float64 {
-		switch distFunc {
-		case EuclideanDistance, ManhattanDistance:
+		if distFunc.lowerIsBetter() {
 			return 1.0 / (1.0 + score)
-		default:
-			return score
 		}
+		return score
 	}
 
 	selected := make([]SimilarityResult, 0, topK)
@@ -306,7 +512,7 @@ func mmrGreedy(
 			}
 
 			mmrScore := lambda*rel - (1.0-lambda)*maxSimToSelected
-			if mmrScore > bestMMR {
+			if mmrScore > bestMMR || mmrScore == bestMMR && id < bestID {
 				bestMMR = mmrScore
 				bestID = id
 			}
@@ -376,6 +582,10 @@ func mmrGreedyCandidates(candidates []MMRCandidate, topK int, lambda float64, di
 			return 1.0 / (1.0 + euclidean32(candidates[i].Embedding, candidates[j].Embedding))
 		case ManhattanDistance:
 			return 1.0 / (1.0 + manhattan32(candidates[i].Embedding, candidates[j].Embedding))
+		case JaccardSimilarity, TanimotoSimilarity, PearsonCorrelation:
+			return DistanceFloat32(candidates[i].Embedding, candidates[j].Embedding, distFunc)
+		case KLDivergence, JensenShannonDistance, MahalanobisDistance:
+			return 1.0 / (1.0 + DistanceFloat32(candidates[i].Embedding, candidates[j].Embedding, distFunc))
 		default:
 			return dotProduct32(candidates[i].Embedding, candidates[j].Embedding)
 		}
@@ -416,8 +626,87 @@ func mmrGreedyCandidates(candidates []MMRCandidate, topK int, lambda float64, di
 	}, nil
 }
 
-// searchCore is the shared backend implementation.
-func (db *VectorDB) searchCore(query any, topK int, includeMetadata bool, filterFunc func(*Vector) bool) (*SearchResult, error) {
+// searchCore is the shared backend implementation. opts may be nil.
+func (db *VectorDB) searchCore(query any, topK int, includeMetadata bool, opts *SearchOptions) (*SearchResult, error) {
+	query32, err := db.queryVector(query)
+	if err != nil {
+		return nil, err
+	}
+	if topK <= 0 {
+		topK = 10 // Default
+	}
+	return db.observed(query32, topK, opts, func() (*SearchResult, error) {
+		db.mu.RLock()
+		defer db.mu.RUnlock()
+		return db.searchLocked(query32, topK, includeMetadata, opts)
+	})
+}
+
+// observed runs search, which takes the read lock itself, between Hooks.OnSearch and
+// OnSearchDone, setting Took and recording it in the slow log.
+func (db *VectorDB) observed(query32 []float32, topK int, opts *SearchOptions, search func() (*SearchResult, error)) (res *SearchResult, err error) {
+	if h := db.hooks.Load(); h != nil {
+		if h.OnSearch != nil {
+			if err := h.OnSearch(query32, topK, opts); err != nil {
+				return nil, err
+			}
+		}
+		if h.OnSearchDone != nil {
+			start := time.Now()
+			defer func() { h.OnSearchDone(res, err, time.Since(start)) }() // after RUnlock
+		}
+	}
+	start := time.Now()
+	defer func() { // runs before OnSearchDone, so it sees Took
+		took := time.Since(start)
+		if res != nil {
+			res.Took = took
+		}
+		if db.slowSearch > 0 && took >= db.slowSearch {
+			db.recordSlow(start, took, topK, res, err)
+		}
+	}()
+	return search()
+}
+
+// deadlineEvery is how many vectors a search with MaxDuration scans between clock reads.
+const deadlineEvery = 64
+
+// stopScore returns the score from which SearchOptions.Epsilon ends the scan, nil without it.
+// rescored reports whether the options change scores after the scan or rank groups, which
+// Epsilon does not support.
+func (db *VectorDB) stopScore(opts *SearchOptions, rescored bool) (*float64, error) {
+	if opts == nil || opts.Epsilon == 0 {
+		return nil, nil
+	}
+	if opts.Epsilon < 0 || math.IsNaN(opts.Epsilon) {
+		return nil, fmt.Errorf("epsilon must be >= 0, got %v", opts.Epsilon)
+	}
+	best, ok := db.distFunc.bestScore()
+	if !ok {
+		return nil, fmt.Errorf("epsilon needs a distance with a best score, not %s", db.distFunc)
+	}
+	if rescored {
+		return nil, errors.New("epsilon cannot be combined with GroupBy, Boosts, Decay, Rescore or RescoreTop")
+	}
+	if db.distFunc.lowerIsBetter() {
+		best += opts.Epsilon
+	} else {
+		best -= opts.Epsilon
+	}
+	return &best, nil
+}
+
+// reaches reports whether score is at least as good as bound.
+func reaches(score, bound float64, lowerIsBetter bool) bool {
+	if lowerIsBetter {
+		return score <= bound
+	}
+	return score >= bound
+}
+
+// queryVector converts query to the float32 vector searches score against.
+func (db *VectorDB) queryVector(query any) ([]float32, error) {
 	query32, err := queryToFloat32(query)
 	if err != nil {
 		return nil, err
@@ -425,33 +714,105 @@ func (db *VectorDB) searchCore(query any, topK int, includeMetadata bool, filter
 	if len(query32) == 0 {
 		return nil, errors.New("query vector cannot be empty")
 	}
-	if topK <= 0 {
-		topK = 10 // Default
+	if query32, err = db.transformInput(query32); err != nil {
+		return nil, err
 	}
+	if err := db.distFunc.checkVector(query32); err != nil {
+		return nil, err
+	}
+	return query32, nil
+}
 
-	db.mu.RLock()
-	defer db.mu.RUnlock()
-
-	if len(db.vectors) == 0 {
+// searchLocked scans the vectors in scope for query32. Callers must hold db.mu.
+func (db *VectorDB) searchLocked(query32 []float32, topK int, includeMetadata bool, opts *SearchOptions) (*SearchResult, error) {
+	if opts != nil {
+		if err := db.checkModel(opts.Model); err != nil {
+			return nil, err
+		}
+	}
+	conds, filterFunc, err := opts.compile()
+	if err != nil {
+		return nil, err
+	}
+	offset, err := opts.offset()
+	if err != nil {
+		return nil, err
+	}
+	rescore, err := opts.scorer(db.distFunc.lowerIsBetter())
+	if err != nil {
+		return nil, err
+	}
+	plan, err := opts.plan()
+	if err != nil {
+		return nil, err
+	}
+	// No more than every stored vector can rank, so the window is capped there; a forged
+	// cursor or a huge Offset must not size the buffers or overflow.
+	n := len(db.vectors)
+	window := n
+	if offset < n && topK < n-offset {
+		window = offset + topK
+	}
+	stages, err := db.pipeline(query32, opts, window)
+	if err != nil {
+		return nil, err
+	}
+	defer stages.release()
+	stages.refine = min(stages.refine, n+1)
+	if opts != nil && opts.OmitMetadata {
+		includeMetadata = false
+	}
+	if offset >= n {
 		return &SearchResult{Results: []SimilarityResult{}}, nil
 	}
 
-	lowerIsBetter := db.distFunc == EuclideanDistance || db.distFunc == ManhattanDistance
+	// Keep the skipped results plus one past the page to know whether another page follows.
+	keep := window + 1
+	lowerIsBetter := db.distFunc.lowerIsBetter()
+	buf := getResults(keep)
+	defer putResults(buf)
 	h := &resultHeap{
-		results:       make([]SimilarityResult, 0, topK+1),
+		results:       *buf,
 		lowerIsBetter: lowerIsBetter,
 	}
+	var groups *groupCollector
+	if opts != nil && opts.GroupBy != "" {
+		groups = newGroupCollector(opts.GroupBy, opts.GroupSize, lowerIsBetter)
+	}
 
-	for _, vector := range db.vectors {
-		if filterFunc != nil && !filterFunc(vector) {
-			continue
+	scope, size, narrowed := db.scopeLocked(opts, conds, plan)
+	if stages.refine > 0 && (plan == PlanScan || plan == PlanAuto && size <= stages.refine) {
+		stages.skipFirstPass(lowerIsBetter) // the shortlist would hold every candidate
+	}
+	stopAt, err := db.stopScore(opts, rescore != nil || groups != nil || stages.refine > 0)
+	if err != nil {
+		return nil, err
+	}
+	var deadline time.Time
+	if opts != nil && opts.MaxDuration != 0 {
+		if opts.MaxDuration < 0 {
+			return nil, fmt.Errorf("max duration must be >= 0, got %v", opts.MaxDuration)
 		}
-		if vector.Dimension != len(query32) {
-			return nil, fmt.Errorf("query vector dimension %d does not match stored vector dimension %d", len(query32), vector.Dimension)
+		deadline = time.Now().Add(opts.MaxDuration)
+	}
+	truncated := false
+
+	// With a refine stage, the scan only shortlists candidates by their cheap score; they are
+	// rescored exactly below.
+	var shortlist *resultHeap
+	if stages.refine > 0 {
+		buf := getResults(stages.refine)
+		defer putResults(buf)
+		shortlist = &resultHeap{
+			results:       *buf,
+			lowerIsBetter: stages.scanLowerIsBetter,
 		}
+	}
 
-		score := db.distanceFloat32(query32, vector.Data, db.distFunc)
-
+	collect := func(vector *Vector, score float64) {
+		if rescore != nil {
+			score = rescore(vector, score, nil)
+		}
 		result := SimilarityResult{
 			ID:    vector.ID,
 			Score: score,
@@ -459,29 +820,100 @@ func (db *VectorDB) searchCore(query any, topK int, includeMetadata bool, filter
 		if includeMetadata {
 			result.Metadata = vector.Metadata
 		}
+		if groups != nil {
+			groups.add(vector, result)
+			return
+		}
+		h.offer(result, keep)
+	}
+
+	var stats SearchStats
+	consider := func(vector *Vector) error {
+		stats.Candidates++
+		if filterFunc != nil && !filterFunc(vector) {
+			return nil
+		}
+		stats.Matched++
+		if vector.Dimension != len(query32) {
+			return fmt.Errorf("query vector dimension %d does not match stored vector dimension %d", len(query32), vector.Dimension)
+		}
+		if shortlist != nil {
+			shortlist.offer(SimilarityResult{ID: vector.ID, Score: stages.scan(vector)}, stages.refine)
+			return nil
+		}
+		collect(vector, stages.scan(vector))
+		return nil
+	}
 
-		if h.Len() < topK {
-			heap.Push(h, result)
-		} else {
-			worst := h.results[0]
-			replace := lowerIsBetter && score < worst.Score || !lowerIsBetter && score > worst.Score
-			if replace {
-				heap.Pop(h)
-				heap.Push(h, result)
+	scanned := 0
+	for vector := range scope {
+		if err := consider(vector); err != nil {
+			return nil, err
+		}
+		if scanned++; opts != nil && opts.progress != nil && scanned%streamEvery == 0 {
+			if err := opts.progress(scanned, h.results); err != nil {
+				return nil, err
 			}
 		}
+		if stopAt != nil && h.Len() == keep && reaches(h.results[0].Score, *stopAt, lowerIsBetter) {
+			stats.Stopped = true
+			break
+		}
+		if !deadline.IsZero() && scanned%deadlineEvery == 0 && time.Now().After(deadline) {
+			truncated = true
+			break
+		}
+	}
+	if shortlist != nil {
+		stats.Rescored = shortlist.Len()
+		for _, r := range shortlist.results {
+			v := db.vectors[r.ID]
+			collect(v, stages.final(v))
+		}
 	}
 
-	results := h.results
-	sort.Slice(results, func(i, j int) bool {
-		if lowerIsBetter {
-			return results[i].Score < results[j].Score
+	var results []SimilarityResult
+	var more bool
+	if groups != nil {
+		results, more = groups.page(offset, topK)
+	} else {
+		results = h.results
+		slices.SortFunc(results, func(a, b SimilarityResult) int {
+			if ranksBefore(a, b, lowerIsBetter) {
+				return -1
+			}
+			return 1
+		})
+		results, more = page(results, offset, topK)
+		results = slices.Clone(results) // h.results goes back to the pool
+	}
+	db.touchResults(results)
+	if opts != nil && opts.IncludeVectors {
+		for i := range results {
+			results[i].Vector = slices.Clone(db.floats(db.vectors[results[i].ID], nil))
 		}
-		return results[i].Score > results[j].Score
-	})
+	}
+	if opts != nil && opts.Explain {
+		db.explain(results, query32, opts, stages.final, rescore)
+	}
+	if includeMetadata && opts != nil && opts.MetadataKeys != nil {
+		for i := range results {
+			results[i].Metadata = results[i].Metadata.project(opts.MetadataKeys)
+		}
+	}
 
-	return &SearchResult{
-		Results: results,
-		Total:   len(results),
-	}, nil
+	res := &SearchResult{
+		Results:   results,
+		Total:     len(results),
+		Truncated: truncated,
+	}
+	if more {
+		res.NextCursor = encodeCursor(offset + topK)
+	}
+	stats.Indexed = narrowed
+	res.stats = stats
+	if opts != nil && opts.Explain {
+		res.Stats = &stats
+	}
+	return res, nil
 }
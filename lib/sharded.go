@@ -0,0 +1,261 @@
+package lib
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+// shardVirtualNodes is how many points each shard has on the hash ring; more points spread IDs
+// more evenly at the cost of a larger ring.
+const shardVirtualNodes = 128
+
+// ShardedVectorDB spreads vectors over several VectorDBs by a consistent hash of their ID and
+// answers searches by querying every shard and merging the results. Each shard is a complete
+// VectorDB that can be snapshotted, warmed or moved on its own, which makes it a building block
+// for datasets beyond one function's memory. It is safe for concurrent use.
+type ShardedVectorDB struct {
+	mu        sync.RWMutex // guards shards, ring and dimension; AddShard takes it for writing
+	shards    []*VectorDB
+	ring      []ringPoint // sorted by hash
+	opts      Options
+	distFunc  DistanceFunction
+	dimension int // shared by every shard; 0 until the first vector unless fixed or flexible
+}
+
+type ringPoint struct {
+	hash  uint64
+	shard int
+}
+
+// NewShardedVectorDB creates n shards, each configured by opts as in NewVectorDBWithOptions.
+func NewShardedVectorDB(n int, opts *Options) *ShardedVectorDB {
+	if n <= 0 {
+		panic("shard count must be > 0")
+	}
+	if opts == nil {
+		opts = &Options{}
+	}
+	s := &ShardedVectorDB{opts: *opts}
+	for range n {
+		s.addShardLocked(NewVectorDBWithOptions(opts))
+	}
+	s.distFunc = s.shards[0].distFunc
+	s.dimension = s.shards[0].dimension
+	return s
+}
+
+// hashKey is FNV-1a finished with the splitmix64 mixer, so IDs and ring points that differ in
+// one character still land far apart.
+func hashKey(key string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	x := h.Sum64()
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
+
+// addShardLocked appends db and its ring points. Callers must hold the write lock.
+func (s *ShardedVectorDB) addShardLocked(db *VectorDB) {
+	i := len(s.shards)
+	s.shards = append(s.shards, db)
+	for v := range shardVirtualNodes {
+		s.ring = append(s.ring, ringPoint{hash: hashKey(fmt.Sprintf("shard-%d#%d", i, v)), shard: i})
+	}
+	slices.SortFunc(s.ring, func(a, b ringPoint) int {
+		if a.hash != b.hash {
+			if a.hash < b.hash {
+				return -1
+			}
+			return 1
+		}
+		return a.shard - b.shard
+	})
+}
+
+// shardLocked returns the index of the shard that owns id. Callers must hold the read lock.
+func (s *ShardedVectorDB) shardLocked(id string) int {
+	h := hashKey(id)
+	i := sort.Search(len(s.ring), func(i int) bool { return s.ring[i].hash >= h })
+	if i == len(s.ring) {
+		i = 0
+	}
+	return s.ring[i].shard
+}
+
+// ShardFor returns the index in Shards of the shard that owns id.
+func (s *ShardedVectorDB) ShardFor(id string) int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.shardLocked(id)
+}
+
+// owner returns the shard that owns id.
+func (s *ShardedVectorDB) owner(id string) *VectorDB {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.shards[s.shardLocked(id)]
+}
+
+// Shards returns the underlying DBs, e.g. to snapshot each one.
+func (s *ShardedVectorDB) Shards() []*VectorDB {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return slices.Clone(s.shards)
+}
+
+// AddShard adds an empty shard configured like the others and moves to it the vectors it now
+// owns, about 1/(n+1) of them; the rest stay where they are. Writes and searches wait while
+// vectors move. It returns the new shard's index.
+//
+// Soft-deleted vectors are not moved: only their old shard's Restore and Vacuum reach them,
+// and one restored there after its ID moved is found by searches but not by Get or Delete.
+// Vacuum the shards first if that matters.
+func (s *ShardedVectorDB) AddShard() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	shard := NewVectorDBWithOptions(&s.opts)
+	s.addShardLocked(shard)
+	i := len(s.shards) - 1
+	if !shard.flexible {
+		shard.dimension = s.dimension // as inferred by the shards already in use
+	}
+	for _, old := range s.shards[:i] {
+		old.mu.Lock()
+		for id, v := range old.vectors {
+			if s.shardLocked(id) == i {
+				shard.copyInLocked(v) // shard is not published until AddShard returns
+				old.removeLocked(v)
+			}
+		}
+		old.unlock()
+	}
+	return i
+}
+
+// Add adds a vector to the shard that owns id, as VectorDB.Add. While the dimension is not yet
+// known, the first vector added fixes it for every shard, as it would for one VectorDB.
+func (s *ShardedVectorDB) Add(id string, data any, metadata ...VectorMetadata) error {
+	s.mu.RLock()
+	inferring := s.dimension == 0 && !s.opts.FlexibleDimensions
+	s.mu.RUnlock()
+	if !inferring {
+		return s.owner(id).Add(id, data, metadata...)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	owner := s.shards[s.shardLocked(id)]
+	if err := owner.Add(id, data, metadata...); err != nil {
+		return err
+	}
+	if s.dimension == 0 {
+		s.dimension = owner.Dimension()
+		for _, shard := range s.shards {
+			shard.mu.Lock()
+			shard.dimension = s.dimension
+			shard.mu.Unlock()
+		}
+	}
+	return nil
+}
+
+// Update updates a vector in the shard that owns id, as VectorDB.Update.
+func (s *ShardedVectorDB) Update(id string, data any, metadata ...VectorMetadata) error {
+	return s.owner(id).Update(id, data, metadata...)
+}
+
+// Get returns a vector from the shard that owns id, as VectorDB.Get.
+func (s *ShardedVectorDB) Get(id string) (*Vector, error) {
+	return s.owner(id).Get(id)
+}
+
+// Delete removes a vector from the shard that owns id, as VectorDB.Delete.
+func (s *ShardedVectorDB) Delete(id string) error {
+	return s.owner(id).Delete(id)
+}
+
+// Size returns the number of vectors over all shards.
+func (s *ShardedVectorDB) Size() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	n := 0
+	for _, shard := range s.shards {
+		n += shard.Size()
+	}
+	return n
+}
+
+// Search is SearchWithOptions with default options.
+func (s *ShardedVectorDB) Search(query any, topK ...int) (*SearchResult, error) {
+	k := 10
+	if len(topK) > 0 {
+		k = topK[0]
+	}
+	return s.SearchWithOptions(query, k, nil)
+}
+
+// SearchWithOptions searches every shard, one after another, and merges their results into
+// the ranking a single DB holding all the vectors would return. Offset and Cursor page over
+// the merged ranking; each shard is asked for offset+topK results, and MaxDuration bounds
+// the shards' scans together. GroupBy and Explain are not supported across shards.
+func (s *ShardedVectorDB) SearchWithOptions(query any, topK int, opts *SearchOptions) (*SearchResult, error) {
+	start := time.Now()
+	if topK <= 0 {
+		topK = 10
+	}
+	offset, err := opts.offset()
+	if err != nil {
+		return nil, err
+	}
+	var shardOpts SearchOptions
+	if opts != nil {
+		if opts.GroupBy != "" || opts.Explain {
+			return nil, errors.New("GroupBy and Explain are not supported across shards")
+		}
+		shardOpts = *opts
+		shardOpts.Offset, shardOpts.Cursor = 0, ""
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var merged []SimilarityResult
+	more, truncated := false, false
+	for i, shard := range s.shards {
+		if opts != nil && opts.MaxDuration > 0 { // the budget is shared by every shard
+			if shardOpts.MaxDuration = opts.MaxDuration - time.Since(start); shardOpts.MaxDuration <= 0 {
+				truncated = true
+				break
+			}
+		}
+		res, err := shard.SearchWithOptions(query, offset+min(topK, math.MaxInt-offset), &shardOpts)
+		if err != nil {
+			return nil, fmt.Errorf("shard %d: %w", i, err)
+		}
+		merged = append(merged, res.Results...)
+		more = more || res.NextCursor != ""
+		truncated = truncated || res.Truncated
+	}
+	lowerIsBetter := s.distFunc.lowerIsBetter()
+	slices.SortFunc(merged, func(a, b SimilarityResult) int {
+		if ranksBefore(a, b, lowerIsBetter) {
+			return -1
+		}
+		return 1
+	})
+	results, pageMore := page(merged, offset, topK)
+	res := &SearchResult{Results: results, Total: len(results), Took: time.Since(start), Truncated: truncated}
+	if more || pageMore {
+		res.NextCursor = encodeCursor(offset + topK)
+	}
+	return res, nil
+}
@@ -0,0 +1,125 @@
+package lib
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func TestShardedVectorDB(t *testing.T) {
+	s := NewShardedVectorDB(4, &Options{Dimension: 2, Distance: EuclideanDistance})
+	single := NewVectorDB(2, EuclideanDistance)
+	for i := range 1000 {
+		id := fmt.Sprint("doc-", i)
+		data := []float32{float32(i % 37), float32(i % 11)}
+		meta := VectorMetadata{Tags: map[string]string{"even": fmt.Sprint(i%2 == 0)}}
+		if err := s.Add(id, data, meta); err != nil {
+			t.Fatal(err)
+		}
+		_ = single.Add(id, data, meta)
+	}
+	if s.Size() != 1000 {
+		t.Fatalf("expected 1000 vectors, got %d", s.Size())
+	}
+	for i, shard := range s.Shards() {
+		if n := shard.Size(); n < 150 || n > 350 {
+			t.Errorf("shard %d holds %d of 1000 vectors", i, n)
+		}
+	}
+	if v, err := s.Get("doc-42"); err != nil || v.Data[0] != 5 {
+		t.Errorf("Get must route to the owning shard: %+v, %v", v, err)
+	}
+	if _, err := s.Shards()[s.ShardFor("doc-42")].Get("doc-42"); err != nil {
+		t.Error("ShardFor must name the owning shard")
+	}
+
+	opts := &SearchOptions{Tags: map[string]string{"even": "true"}}
+	want, _ := single.SearchWithOptions([]float32{3, 4}, 7, opts)
+	got, err := s.SearchWithOptions([]float32{3, 4}, 7, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.EqualFunc(got.Results, want.Results, func(a, b SimilarityResult) bool { return a.ID == b.ID && a.Score == b.Score }) {
+		t.Errorf("merged results differ from a single DB:\n got %v\nwant %v", got.Results, want.Results)
+	}
+	page2, _ := s.SearchWithOptions([]float32{3, 4}, 7, &SearchOptions{Tags: opts.Tags, Cursor: got.NextCursor})
+	want2, _ := single.SearchWithOptions([]float32{3, 4}, 7, &SearchOptions{Tags: opts.Tags, Cursor: want.NextCursor})
+	if page2.Results[0].ID != want2.Results[0].ID || page2.NextCursor == "" {
+		t.Errorf("the second page must continue the merged ranking, got %v", page2.Results)
+	}
+	if _, err := s.SearchWithOptions([]float32{3, 4}, 7, &SearchOptions{GroupBy: "even"}); err == nil {
+		t.Error("GroupBy across shards must be rejected")
+	}
+	if err := s.Delete("doc-42"); err != nil || s.Size() != 999 {
+		t.Errorf("Delete must route to the owning shard: %v", err)
+	}
+}
+
+func TestShardedVectorDB_AddShard(t *testing.T) {
+	s := NewShardedVectorDB(3, &Options{Dimension: 2})
+	before := make(map[string]int)
+	for i := range 3000 {
+		id := fmt.Sprint(i)
+		_ = s.Add(id, []float32{float32(i), 1})
+		before[id] = s.ShardFor(id)
+	}
+	n := s.AddShard()
+	moved := 0
+	for id, shard := range before {
+		now := s.ShardFor(id)
+		if now != shard {
+			moved++
+			if now != n {
+				t.Fatalf("%s moved between old shards", id)
+			}
+		}
+		if _, err := s.Get(id); err != nil {
+			t.Fatalf("%s lost while rebalancing: %v", id, err)
+		}
+	}
+	if s.Size() != 3000 || moved < 500 || moved > 1000 {
+		t.Errorf("expected about a quarter of 3000 vectors to move, moved %d (size %d)", moved, s.Size())
+	}
+}
+
+func TestShardedVectorDB_InferredDimensionShared(t *testing.T) {
+	s := NewShardedVectorDB(4, &Options{})
+	if err := s.Add("a", []float32{1, 2, 3}); err != nil {
+		t.Fatal(err)
+	}
+	for i, shard := range s.Shards() {
+		if d := shard.Dimension(); d != 3 {
+			t.Errorf("shard %d must take the inferred dimension 3, got %d", i, d)
+		}
+	}
+	for i := 0; ; i++ { // an ID another shard owns
+		if id := fmt.Sprint(i); s.ShardFor(id) != s.ShardFor("a") {
+			if err := s.Add(id, []float32{1, 2, 3, 4, 5}); err == nil {
+				t.Error("expected another shard to reject a 5-dim vector, as one VectorDB would")
+			}
+			break
+		}
+	}
+}
+
+func TestShardedVectorDB_AddShardInferredDimension(t *testing.T) {
+	s := NewShardedVectorDB(2, &Options{})
+	for i := range 100 {
+		_ = s.Add(fmt.Sprint(i), []float32{float32(i), 1})
+	}
+	n := s.AddShard()
+	if d := s.shards[n].Dimension(); d != 2 {
+		t.Fatalf("the new shard must take the inferred dimension 2, got %d", d)
+	}
+	for i := 100; ; i++ { // an ID the new shard owns
+		if id := fmt.Sprint(i); s.ShardFor(id) == n {
+			if err := s.Add(id, []float32{1, 2, 3}); err == nil {
+				t.Fatal("expected the new shard to reject a 3-dim vector")
+			}
+			break
+		}
+	}
+	if _, err := s.Search([]float32{1, 1}, 5); err != nil {
+		t.Errorf("search after AddShard: %v", err)
+	}
+}
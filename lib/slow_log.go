@@ -0,0 +1,70 @@
+package lib
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// SlowQueryLogSize is how many of the latest slow searches SlowQueries keeps.
+const SlowQueryLogSize = 100
+
+// SlowQuery is a search that took at least Options.SlowSearch.
+type SlowQuery struct {
+	Start      time.Time
+	Took       time.Duration
+	TopK       int
+	Candidates int   // Vectors visited (see SearchStats).
+	Matched    int   // Candidates that passed every filter.
+	Indexed    bool  // Whether an index or AllowIDs narrowed the scan.
+	Err        error // Non-nil if the search failed.
+}
+
+// Selectivity is the fraction of candidates that passed the filters: 1 for an unfiltered
+// search, lower as filters reject more of what is scanned.
+func (q SlowQuery) Selectivity() float64 {
+	if q.Candidates == 0 {
+		return 1
+	}
+	return float64(q.Matched) / float64(q.Candidates)
+}
+
+// slowLog is a ring of the latest SlowQueries. It has its own lock because searches hold
+// only the DB read lock.
+type slowLog struct {
+	mu      sync.Mutex
+	entries []SlowQuery
+	next    int // oldest entry once entries is full
+}
+
+// SlowQueries returns the latest searches (up to SlowQueryLogSize) that took at least
+// Options.SlowSearch, oldest first.
+func (db *VectorDB) SlowQueries() []SlowQuery {
+	l := &db.slow
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	out := make([]SlowQuery, 0, len(l.entries))
+	out = append(out, l.entries[l.next:]...)
+	return append(out, l.entries[:l.next]...)
+}
+
+// recordSlow logs and keeps a search that took at least Options.SlowSearch. res is nil when
+// the search failed before scanning.
+func (db *VectorDB) recordSlow(start time.Time, took time.Duration, topK int, res *SearchResult, err error) {
+	q := SlowQuery{Start: start, Took: took, TopK: topK, Err: err}
+	if res != nil {
+		q.Candidates, q.Matched, q.Indexed = res.stats.Candidates, res.stats.Matched, res.stats.Indexed
+	}
+	db.log(slog.LevelWarn, "slow search", "took", took, "top_k", topK, "candidates", q.Candidates,
+		"selectivity", q.Selectivity(), "indexed", q.Indexed, "failed", err != nil)
+
+	l := &db.slow
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.entries) < SlowQueryLogSize {
+		l.entries = append(l.entries, q)
+		return
+	}
+	l.entries[l.next] = q
+	l.next = (l.next + 1) % SlowQueryLogSize
+}
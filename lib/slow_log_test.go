@@ -0,0 +1,50 @@
+package lib
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestSlowQueries(t *testing.T) {
+	db := NewVectorDBWithOptions(&Options{Dimension: 2, SlowSearch: time.Nanosecond})
+	for i := range 10 {
+		_ = db.Add(fmt.Sprint(i), []float32{1, float32(i)}, VectorMetadata{Tags: map[string]string{"even": fmt.Sprint(i%2 == 0)}})
+	}
+	res, err := db.SearchWithOptions([]float32{1, 0}, 3, &SearchOptions{Tags: map[string]string{"even": "true"}})
+	if err != nil || res.Took <= 0 {
+		t.Fatalf("expected Took to be set, got %v, %v", res, err)
+	}
+	if res.Stats != nil {
+		t.Error("Stats must stay nil without Explain")
+	}
+	_, _ = db.Search([]float32{1, 0, 0}, 1)
+
+	slow := db.SlowQueries()
+	if len(slow) != 2 {
+		t.Fatalf("expected 2 slow queries, got %d", len(slow))
+	}
+	if q := slow[0]; q.Candidates != 10 || q.Matched != 5 || q.Selectivity() != 0.5 || q.TopK != 3 || q.Err != nil || q.Took != res.Took {
+		t.Errorf("unexpected first entry %+v", q)
+	}
+	if slow[1].Err == nil {
+		t.Error("failed searches must be recorded with their error")
+	}
+
+	for range SlowQueryLogSize {
+		_, _ = db.Search([]float32{1, 0}, 1)
+	}
+	slow = db.SlowQueries()
+	if len(slow) != SlowQueryLogSize || slow[0].Err != nil || slow[0].TopK != 1 {
+		t.Errorf("the log must keep the latest %d, oldest first; got %d starting %+v", SlowQueryLogSize, len(slow), slow[0])
+	}
+	for i := 1; i < len(slow); i++ {
+		if slow[i].Start.Before(slow[i-1].Start) {
+			t.Fatal("entries must be oldest first")
+		}
+	}
+
+	if NewVectorDB(2).SlowQueries() == nil {
+		t.Error("SlowQueries must return an empty, non-nil slice")
+	}
+}
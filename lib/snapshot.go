@@ -0,0 +1,87 @@
+package lib
+
+import "iter"
+
+// Snapshot is a read-only copy of a VectorDB taken at one point in time. Writes to the DB after
+// Snapshot returns are not visible through it, so several queries against one Snapshot see the
+// same data. It is safe for concurrent use.
+type Snapshot struct {
+	db *VectorDB // never written after Snapshot returns
+}
+
+// Snapshot copies the vectors, metadata and indexes, holding only the read lock. Copying is
+// O(n·d) time and memory; vector data is shared only where the DB never rewrites it in place.
+func (db *VectorDB) Snapshot() *Snapshot {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	c := db.emptyCopyLocked()
+	for _, v := range db.byOrd {
+		if v != nil && !v.deleted {
+			c.copyInLocked(v)
+		}
+	}
+	return &Snapshot{db: c}
+}
+
+// emptyCopyLocked returns an empty DB with db's dimension, distance, storage type, covariance,
+// transform and (empty) indexes. Callers must hold the read lock.
+func (db *VectorDB) emptyCopyLocked() *VectorDB {
+	c := &VectorDB{
+		vectors:     make(map[string]*Vector),
+		dimension:   db.dimension,
+		flexible:    db.flexible,
+		distFunc:    db.distFunc,
+		codec:       db.codec,
+		binaryIndex: db.binaryIndex,
+		covChol:     db.covChol, // replaced, never modified, by SetCovariance and FitCovariance
+		limits:      db.limits,
+		autoVacuum:  db.autoVacuum,
+		transform:   db.transform,
+		model:       db.model,
+	}
+	for key := range db.tagIndex {
+		if c.tagIndex == nil {
+			c.tagIndex = make(tagIndex, len(db.tagIndex))
+		}
+		c.tagIndex[key] = make(valueSet)
+	}
+	return c
+}
+
+// copyInLocked stores a copy of v, a vector of another DB, in c. Callers must hold c's write
+// lock, or own c exclusively, and the read lock of v's DB.
+func (c *VectorDB) copyInLocked(v *Vector) {
+	cp := *v // placeLocked copies arena-backed data into c's own arena
+	c.storeLocked(c.vectors, &cp)
+}
+
+// Search is VectorDB.Search over the snapshot.
+func (s *Snapshot) Search(query any, topK ...int) (*SearchResult, error) {
+	return s.db.Search(query, topK...)
+}
+
+// SearchWithOptions is VectorDB.SearchWithOptions over the snapshot.
+func (s *Snapshot) SearchWithOptions(query any, topK int, opts *SearchOptions) (*SearchResult, error) {
+	return s.db.SearchWithOptions(query, topK, opts)
+}
+
+// Get returns a copy of the vector with id as of the snapshot.
+func (s *Snapshot) Get(id string) (*Vector, error) { return s.db.Get(id) }
+
+// Size returns the number of vectors in the snapshot.
+func (s *Snapshot) Size() int { return s.db.Size() }
+
+// IDs returns the IDs in the snapshot in ascending order.
+func (s *Snapshot) IDs() []string { return s.db.IDs() }
+
+// Iterate yields a copy of every vector in ascending ID order.
+func (s *Snapshot) Iterate() iter.Seq[*Vector] {
+	return func(yield func(*Vector) bool) {
+		for _, id := range s.db.IDs() {
+			v, err := s.db.Get(id)
+			if err != nil || !yield(v) {
+				return
+			}
+		}
+	}
+}
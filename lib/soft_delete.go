@@ -0,0 +1,126 @@
+package lib
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// SoftDelete hides a vector from searches, Get and every other read, keeping it restorable.
+// It stays in the indexes, which skip it, and holds its memory until Vacuum, or until
+// Options.AutoVacuum vacuums it with the rest.
+func (db *VectorDB) SoftDelete(id string) error {
+	if err := db.deleteHook(id); err != nil {
+		return err
+	}
+	db.mu.Lock()
+	defer db.unlock()
+	v, ok := db.vectors[id]
+	if !ok {
+		return fmt.Errorf("vector with ID %s not found", id)
+	}
+	delete(db.vectors, id)
+	v.deleted = true
+	if db.trash == nil {
+		db.trash = make(map[string]*Vector)
+	}
+	db.trash[id] = v
+	db.emitLocked(EventDelete, v)
+	if db.autoVacuum > 0 && db.stalenessLocked() > db.autoVacuum {
+		db.vacuumLocked(context.Background(), nil)
+	}
+	return nil
+}
+
+// Restore brings back a vector removed by SoftDelete. It fails if the vector was vacuumed or
+// its ID has since been reused.
+func (db *VectorDB) Restore(id string) error {
+	db.mu.Lock()
+	defer db.unlock()
+	v, ok := db.trash[id]
+	if !ok {
+		return fmt.Errorf("no soft-deleted vector with ID %s", id)
+	}
+	if _, exists := db.vectors[id]; exists {
+		return fmt.Errorf("vector with ID %s already exists", id)
+	}
+	if err := db.checkRoomLocked(1); err != nil {
+		return err
+	}
+	delete(db.trash, id)
+	v.deleted = false
+	db.vectors[id] = v
+	db.indexRemove(v) // indexes created or dropped meanwhile
+	db.indexAdd(v)
+	db.emitLocked(EventAdd, v)
+	return nil
+}
+
+// SoftDeleted returns the number of vectors waiting for Vacuum.
+func (db *VectorDB) SoftDeleted() int {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	return len(db.trash)
+}
+
+// Staleness returns the share of the vectors held in memory and in the indexes that are
+// soft-deleted, in [0, 1]: the work and memory searches waste on them until Vacuum.
+func (db *VectorDB) Staleness() float64 {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	return db.stalenessLocked()
+}
+
+func (db *VectorDB) stalenessLocked() float64 {
+	if len(db.trash) == 0 {
+		return 0
+	}
+	return float64(len(db.trash)) / float64(len(db.trash)+len(db.vectors))
+}
+
+// Vacuum permanently removes soft-deleted vectors from the indexes and frees their memory,
+// returning how many were removed.
+func (db *VectorDB) Vacuum() int {
+	n, _ := db.VacuumContext(context.Background(), nil)
+	return n
+}
+
+// VacuumContext is Vacuum for large backlogs: progress, if not nil, is called every 1000
+// vectors removed and at the end, and a cancelled ctx stops it early, leaving the vectors not
+// yet removed for the next Vacuum. It returns how many were removed.
+func (db *VectorDB) VacuumContext(ctx context.Context, progress ProgressFunc) (int, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	return db.vacuumLocked(ctx, progress)
+}
+
+func (db *VectorDB) vacuumLocked(ctx context.Context, progress ProgressFunc) (int, error) {
+	start := time.Now()
+	total, n := len(db.trash), 0
+	var err error
+	for _, v := range db.trash {
+		if err = ctx.Err(); err != nil {
+			break
+		}
+		db.vacuumOneLocked(v)
+		n++
+		reportProgress(progress, n, total)
+	}
+	if n > 0 {
+		db.logIndexBuild("vacuum", n, start)
+	}
+	return n, err
+}
+
+// vacuumOneLocked permanently removes the soft-deleted vector v. Callers must hold the write
+// lock.
+func (db *VectorDB) vacuumOneLocked(v *Vector) {
+	if _, live := db.vectors[v.ID]; !live {
+		delete(db.history, v.ID)
+	}
+	db.indexRemove(v)
+	db.byOrd[v.ord] = nil
+	db.freeOrds = append(db.freeOrds, v.ord)
+	db.mem.used -= vectorMemory(v)
+	delete(db.trash, v.ID)
+}
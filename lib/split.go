@@ -0,0 +1,64 @@
+package lib
+
+import "errors"
+
+// newPartLocked returns an empty DB for Split and PartitionBy: an emptyCopyLocked that also
+// keeps db's options. Callers must hold the read lock.
+func (db *VectorDB) newPartLocked() *VectorDB {
+	c := db.emptyCopyLocked()
+	c.keepVersions = db.keepVersions
+	c.logger = db.logger
+	c.slowSearch = db.slowSearch
+	c.mem = memoryBudget{max: db.mem.max, onEvict: db.mem.onEvict}
+	c.newID = db.newID
+	return c
+}
+
+// Split copies the vectors into two new DBs: matched, with those filter accepts, and rest,
+// with the others. Both keep db's dimension, distance, storage type, options, covariance and
+// tag indexes; db itself is unchanged. filter gets the stored vector, as for
+// SearchOptions.Filter, and must not call db.
+func (db *VectorDB) Split(filter func(*Vector) bool) (matched, rest *VectorDB) {
+	if filter == nil {
+		panic("split filter cannot be nil")
+	}
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	matched, rest = db.newPartLocked(), db.newPartLocked()
+	for _, v := range db.byOrd {
+		if v == nil || v.deleted {
+			continue
+		}
+		if filter(v) {
+			matched.copyInLocked(v)
+		} else {
+			rest.copyInLocked(v)
+		}
+	}
+	return matched, rest
+}
+
+// PartitionBy copies the vectors into one new DB per value of the tag key, e.g. one per
+// tenant; vectors without the tag go to the "" partition. Each keeps db's configuration as
+// for Split; db itself is unchanged.
+func (db *VectorDB) PartitionBy(tagKey string) (map[string]*VectorDB, error) {
+	if tagKey == "" {
+		return nil, errors.New("tag key cannot be empty")
+	}
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	parts := make(map[string]*VectorDB)
+	for _, v := range db.byOrd {
+		if v == nil || v.deleted {
+			continue
+		}
+		value := v.Metadata.Tags[tagKey]
+		part, ok := parts[value]
+		if !ok {
+			part = db.newPartLocked()
+			parts[value] = part
+		}
+		part.copyInLocked(v)
+	}
+	return parts, nil
+}
@@ -0,0 +1,51 @@
+package lib
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestSplit(t *testing.T) {
+	db := NewVectorDBWithOptions(&Options{Dimension: 2, Distance: EuclideanDistance, Storage: Float16})
+	_ = db.CreateTagIndex("tenant")
+	for i := range 10 {
+		_ = db.Add(fmt.Sprint(i), []float32{float32(i), 0}, VectorMetadata{Tags: map[string]string{"tenant": fmt.Sprint(i % 3)}})
+	}
+	_ = db.SoftDelete("9")
+
+	matched, rest := db.Split(func(v *Vector) bool { return v.Metadata.Tags["tenant"] == "0" })
+	if matched.Size() != 3 || rest.Size() != 6 || db.Size() != 9 {
+		t.Fatalf("expected 3 and 6 vectors with the source unchanged, got %d, %d and %d", matched.Size(), rest.Size(), db.Size())
+	}
+	if matched.distFunc != EuclideanDistance || matched.codec == nil || len(matched.TagIndexes()) != 1 {
+		t.Error("parts must keep the distance, storage type and tag indexes")
+	}
+	if res, _ := rest.SearchWithOptions([]float32{4, 0}, 1, &SearchOptions{Tags: map[string]string{"tenant": "1"}}); res.Results[0].ID != "4" {
+		t.Errorf("unexpected search in a part: %+v", res.Results)
+	}
+	_ = matched.Add("new", []float32{1, 1})
+	if db.Size() != 9 {
+		t.Error("writes to a part must not reach the source")
+	}
+}
+
+func TestPartitionBy(t *testing.T) {
+	db := NewVectorDB(2)
+	for i := range 6 {
+		_ = db.Add(fmt.Sprint(i), []float32{float32(i), 1}, VectorMetadata{Tags: map[string]string{"tenant": fmt.Sprint(i % 2)}})
+	}
+	_ = db.Add("untagged", []float32{1, 1})
+	parts, err := db.PartitionBy("tenant")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(parts) != 3 || parts["0"].Size() != 3 || parts["1"].Size() != 3 || parts[""].Size() != 1 {
+		t.Errorf("unexpected partitions %v", parts)
+	}
+	if v, err := parts["1"].Get("3"); err != nil || v.Data[0] != 3 {
+		t.Errorf("unexpected vector %+v, %v", v, err)
+	}
+	if _, err := db.PartitionBy(""); err == nil {
+		t.Error("an empty tag key must be rejected")
+	}
+}
@@ -1,24 +1,32 @@
 package lib
 
+import (
+	"slices"
+	"time"
+)
+
 // GetStats returns database statistics.
 // It snapshots under RLock then computes stats outside the lock to reduce lock hold time.
 func (db *VectorDB) GetStats() map[string]any {
 	db.mu.RLock()
 	totalVectors := len(db.vectors)
 	totalDimensions := 0
+	var memoryUsage int64 // vector data in its storage type + per-vector overhead
 	for _, vector := range db.vectors {
 		totalDimensions += vector.Dimension
+		memoryUsage += vectorMemory(vector)
 	}
 	distFunc := db.distFunc
 	dimension := db.dimension
+	softDeleted := len(db.trash)
+	staleness := db.stalenessLocked()
+	evicted := db.mem.evicted
 	db.mu.RUnlock()
 
 	avgDimensions := 0.0
 	if totalVectors > 0 {
 		avgDimensions = float64(totalDimensions) / float64(totalVectors)
 	}
-	// float32: 4 bytes per dimension + per-vector overhead
-	memoryUsage := int64(totalDimensions)*4 + int64(totalVectors)*256
 
 	return map[string]any{
 		"total_vectors":     totalVectors,
@@ -27,5 +35,126 @@ func (db *VectorDB) GetStats() map[string]any {
 		"memory_usage_kb":   memoryUsage / 1024,
 		"distance_function": distFunc.String(),
 		"dimension":         dimension,
+		"soft_deleted":      softDeleted,
+		"staleness":         staleness,
+		"evicted":           evicted,
+	}
+}
+
+// StatsOptions selects the histograms GetStatsWithOptions adds to GetStats. Zero values add none.
+type StatsOptions struct {
+	TagCardinality bool          // "tag_cardinality": distinct values per tag key, a map[string]int.
+	NormBuckets    int           // "norm_histogram": a Histogram of vector norms with this many buckets.
+	CreatedBucket  time.Duration // "created_histogram": vectors per CreatedAt bucket of this width, a []TimeBucket.
+	// GroupBy, if set, adds "groups": a map[string]GroupStats keyed by each vector's value of
+	// this tag, e.g. "tenant", with "" for vectors without it, as PartitionBy splits them.
+	GroupBy string
+}
+
+// GroupStats totals the vectors sharing one value of StatsOptions.GroupBy, for capacity
+// planning before splitting a DB with PartitionBy or enforcing a Quota.
+type GroupStats struct {
+	Vectors     int   // Live vectors.
+	SoftDeleted int   // Vectors removed with SoftDelete and not yet vacuumed.
+	MemoryBytes int64 // Vector data in its storage type plus per-vector overhead, as memory_usage_kb.
+}
+
+// Histogram counts values in equal-width buckets: bucket i covers
+// [Min + i·w, Min + (i+1)·w) with w = (Max-Min)/len(Counts), the last one including Max.
+type Histogram struct {
+	Min    float64
+	Max    float64
+	Counts []int
+}
+
+// TimeBucket counts the vectors created in [Start, Start+width).
+type TimeBucket struct {
+	Start time.Time
+	Count int
+}
+
+// GetStatsWithOptions returns GetStats plus the histograms opts asks for, to watch a corpus's
+// health and drift: a tag whose cardinality jumps, norms that shift after an embedding model
+// change, or a gap in ingestion. Norms are computed from the vector data, O(n·d).
+func (db *VectorDB) GetStatsWithOptions(opts *StatsOptions) map[string]any {
+	stats := db.GetStats()
+	if opts == nil {
+		return stats
+	}
+	if opts.NormBuckets < 0 || opts.CreatedBucket < 0 {
+		panic("norm buckets and created bucket must be >= 0")
+	}
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	if opts.TagCardinality {
+		values := make(map[string]map[string]struct{})
+		for _, v := range db.vectors {
+			for k, t := range v.Metadata.Tags {
+				if values[k] == nil {
+					values[k] = make(map[string]struct{})
+				}
+				values[k][t] = struct{}{}
+			}
+		}
+		cardinality := make(map[string]int, len(values))
+		for k, set := range values {
+			cardinality[k] = len(set)
+		}
+		stats["tag_cardinality"] = cardinality
+	}
+	if opts.NormBuckets > 0 {
+		norms := make([]float64, 0, len(db.vectors))
+		var buf []float32
+		for _, v := range db.vectors {
+			norms = append(norms, norm32(db.floats(v, &buf)))
+		}
+		stats["norm_histogram"] = histogram(norms, opts.NormBuckets)
+	}
+	if opts.CreatedBucket > 0 {
+		width := int64(max(opts.CreatedBucket/time.Second, 1))
+		counts := make(map[int64]int)
+		for _, v := range db.vectors {
+			counts[v.Metadata.CreatedAt-v.Metadata.CreatedAt%width]++
+		}
+		buckets := make([]TimeBucket, 0, len(counts))
+		for start, n := range counts {
+			buckets = append(buckets, TimeBucket{Start: time.Unix(start, 0).UTC(), Count: n})
+		}
+		slices.SortFunc(buckets, func(a, b TimeBucket) int { return a.Start.Compare(b.Start) })
+		stats["created_histogram"] = buckets
+	}
+	if opts.GroupBy != "" {
+		groups := make(map[string]GroupStats)
+		for _, v := range db.vectors {
+			g := groups[v.Metadata.Tags[opts.GroupBy]]
+			g.Vectors++
+			g.MemoryBytes += vectorMemory(v)
+			groups[v.Metadata.Tags[opts.GroupBy]] = g
+		}
+		for _, v := range db.trash {
+			g := groups[v.Metadata.Tags[opts.GroupBy]]
+			g.SoftDeleted++
+			g.MemoryBytes += vectorMemory(v)
+			groups[v.Metadata.Tags[opts.GroupBy]] = g
+		}
+		stats["groups"] = groups
+	}
+	return stats
+}
+
+// histogram counts values in n equal-width buckets between their min and max.
+func histogram(values []float64, n int) Histogram {
+	h := Histogram{Counts: make([]int, n)}
+	if len(values) == 0 {
+		return h
+	}
+	h.Min, h.Max = slices.Min(values), slices.Max(values)
+	for _, x := range values {
+		i := 0
+		if h.Max > h.Min {
+			i = min(int((x-h.Min)/(h.Max-h.Min)*float64(n)), n-1)
+		}
+		h.Counts[i]++
 	}
+	return h
 }
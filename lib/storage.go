@@ -0,0 +1,294 @@
+package lib
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"slices"
+)
+
+// codec packs vectors into a reduced-precision VectorType (Options.Storage).
+type codec interface {
+	encode(v []float32) []byte
+	decode(dst []float32, src []byte) // len(dst) is the vector's dimension
+	check(v []float32) error          // rejects values the type cannot hold exactly
+}
+
+// intCodec is a codec whose vectors can be scored without converting them to floats.
+type intCodec interface {
+	codec
+	// kernel scores packed vectors against the packed query q exactly in integer arithmetic,
+	// or returns nil when df has no integer kernel.
+	kernel(q []byte, df DistanceFunction) func(v []byte) float64
+}
+
+func codecFor(t VectorType) (codec, error) {
+	switch t {
+	case Float32:
+		return nil, nil
+	case Float16:
+		return float16Codec{}, nil
+	case BFloat16:
+		return bfloat16Codec{}, nil
+	case Int8:
+		return intStorage[int8]{}, nil
+	case Uint8:
+		return intStorage[uint8]{}, nil
+	}
+	return nil, fmt.Errorf("unknown storage type %d", t)
+}
+
+// packLocked moves v.Data into the DB's storage type, if it has one. Callers must hold the
+// write lock or own v exclusively.
+func (db *VectorDB) packLocked(v *Vector) {
+	if db.codec != nil && v.Data != nil {
+		v.packed = db.codec.encode(v.Data)
+		v.Data = nil
+	}
+}
+
+// checkVector rejects vectors the distance function cannot score or the storage type cannot hold.
+func (db *VectorDB) checkVector(v []float32) error {
+	if err := db.checkLength(v); err != nil {
+		return err
+	}
+	if err := db.distFunc.checkVector(v); err != nil {
+		return err
+	}
+	if db.codec != nil {
+		return db.codec.check(v)
+	}
+	return nil
+}
+
+// intKernel returns an integer-domain scorer for query32 when the DB stores Int8 or Uint8, the
+// query fits that type, and opts scores whole unweighted vectors; otherwise nil.
+func (db *VectorDB) intKernel(query32 []float32, opts *SearchOptions) func(v []byte) float64 {
+	ic, ok := db.codec.(intCodec)
+	if !ok || opts != nil && (opts.Weights != nil || opts.Dimensions != 0 && opts.Dimensions != len(query32)) {
+		return nil
+	}
+	if ic.check(query32) != nil {
+		return nil
+	}
+	return ic.kernel(ic.encode(query32), db.distFunc)
+}
+
+// floats returns v's components: v.Data itself, or its packed form decoded into *buf (grown as
+// needed; nil buf allocates). The result must not be modified. Callers must hold db.mu.
+func (db *VectorDB) floats(v *Vector, buf *[]float32) []float32 {
+	if v.packed == nil {
+		return v.Data
+	}
+	var out []float32
+	if buf != nil && cap(*buf) >= v.Dimension {
+		out = (*buf)[:v.Dimension]
+	} else {
+		out = make([]float32, v.Dimension)
+		if buf != nil {
+			*buf = out
+		}
+	}
+	db.codec.decode(out, v.packed)
+	return out
+}
+
+// floatsCopy returns v's components in a new slice, for use after db.mu is released: arena-backed
+// Data is rewritten in place by later writes. Callers must hold db.mu.
+func (db *VectorDB) floatsCopy(v *Vector) []float32 {
+	if v.packed == nil {
+		return slices.Clone(v.Data)
+	}
+	return db.floats(v, nil)
+}
+
+// float16Codec stores IEEE 754 half-precision values, 2 bytes per dimension. Values beyond
+// ±65504 become ±Inf and precision is about 3 decimal digits.
+type float16Codec struct{}
+
+func (float16Codec) encode(v []float32) []byte {
+	out := make([]byte, 2*len(v))
+	for i, x := range v {
+		binary.LittleEndian.PutUint16(out[2*i:], float32ToHalf(x))
+	}
+	return out
+}
+
+func (float16Codec) decode(dst []float32, src []byte) {
+	for i := range dst {
+		dst[i] = halfToFloat32(binary.LittleEndian.Uint16(src[2*i:]))
+	}
+}
+
+func (float16Codec) check([]float32) error { return nil }
+
+// bfloat16Codec stores the top 16 bits of each float32 (rounded), 2 bytes per dimension.
+type bfloat16Codec struct{}
+
+func (bfloat16Codec) encode(v []float32) []byte {
+	out := make([]byte, 2*len(v))
+	for i, x := range v {
+		binary.LittleEndian.PutUint16(out[2*i:], float32ToBFloat16(x))
+	}
+	return out
+}
+
+func (bfloat16Codec) decode(dst []float32, src []byte) {
+	for i := range dst {
+		dst[i] = math.Float32frombits(uint32(binary.LittleEndian.Uint16(src[2*i:])) << 16)
+	}
+}
+
+func (bfloat16Codec) check([]float32) error { return nil }
+
+// intStorage stores each component as one T, 1 byte per dimension. Values must be integers in
+// T's range; they are stored and scored exactly.
+type intStorage[T int8 | uint8] struct{}
+
+func (intStorage[T]) encode(v []float32) []byte {
+	out := make([]byte, len(v))
+	for i, x := range v {
+		out[i] = byte(T(x))
+	}
+	return out
+}
+
+func (intStorage[T]) decode(dst []float32, src []byte) {
+	for i := range dst {
+		dst[i] = float32(T(src[i]))
+	}
+}
+
+func (intStorage[T]) check(v []float32) error {
+	for i, x := range v {
+		if float32(T(x)) != x { // out of range, fractional or NaN
+			return fmt.Errorf("component %d is %v, which is not a %T", i, x, T(0))
+		}
+	}
+	return nil
+}
+
+func (intStorage[T]) kernel(q []byte, df DistanceFunction) func(v []byte) float64 {
+	switch df {
+	case DotProduct:
+		return func(v []byte) float64 { return float64(dotInts[T](q, v)) }
+	case CosineSimilarity:
+		nq := math.Sqrt(float64(dotInts[T](q, q)))
+		return func(v []byte) float64 {
+			nv := math.Sqrt(float64(dotInts[T](v, v)))
+			if nq == 0 || nv == 0 {
+				return 0
+			}
+			return float64(dotInts[T](q, v)) / (nq * nv)
+		}
+	case EuclideanDistance:
+		return func(v []byte) float64 {
+			var sum int64
+			for i := range q {
+				d := int64(T(q[i])) - int64(T(v[i]))
+				sum += d * d
+			}
+			return math.Sqrt(float64(sum))
+		}
+	case ManhattanDistance:
+		return func(v []byte) float64 {
+			var sum int64
+			for i := range q {
+				d := int64(T(q[i])) - int64(T(v[i]))
+				sum += max(d, -d)
+			}
+			return float64(sum)
+		}
+	}
+	return nil
+}
+
+// dotInts is the dot product of a and b read as T. It cannot overflow below 2^32 dimensions.
+func dotInts[T int8 | uint8](a, b []byte) int64 {
+	var sum int64
+	for i := range a {
+		sum += int64(T(a[i])) * int64(T(b[i]))
+	}
+	return sum
+}
+
+// Float32ToBFloat16 converts v to bfloat16 bit patterns, rounding to nearest even.
+func Float32ToBFloat16(v []float32) []uint16 {
+	out := make([]uint16, len(v))
+	for i, x := range v {
+		out[i] = float32ToBFloat16(x)
+	}
+	return out
+}
+
+// BFloat16ToFloat32 converts bfloat16 bit patterns, such as a model's raw bf16 output, to
+// float32 exactly.
+func BFloat16ToFloat32(v []uint16) []float32 {
+	out := make([]float32, len(v))
+	for i, h := range v {
+		out[i] = math.Float32frombits(uint32(h) << 16)
+	}
+	return out
+}
+
+func float32ToBFloat16(f float32) uint16 {
+	b := math.Float32bits(f)
+	if b&0x7fffffff > 0x7f800000 { // NaN: keep it quiet rather than rounding to Inf
+		return uint16(b>>16) | 0x40
+	}
+	b += 0x7fff + (b>>16)&1
+	return uint16(b >> 16)
+}
+
+// float32ToHalf rounds f to the nearest half-precision value, ties to even.
+func float32ToHalf(f float32) uint16 {
+	b := math.Float32bits(f)
+	sign := uint16(b>>16) & 0x8000
+	exp := int32(b>>23&0xff) - 127 + 15
+	mant := b & 0x7fffff
+	switch {
+	case b&0x7fffffff > 0x7f800000: // NaN
+		return sign | 0x7e00
+	case exp >= 0x1f: // overflow and Inf
+		return sign | 0x7c00
+	case exp <= 0: // subnormal half, or zero
+		if exp < -10 {
+			return sign
+		}
+		mant |= 0x800000
+		shift := uint32(14 - exp)
+		half := mant >> shift
+		rem, mid := mant&(1<<shift-1), uint32(1)<<(shift-1)
+		if rem > mid || rem == mid && half&1 == 1 {
+			half++
+		}
+		return sign | uint16(half)
+	}
+	half := uint32(exp)<<10 | mant>>13
+	if rem := mant & 0x1fff; rem > 0x1000 || rem == 0x1000 && half&1 == 1 {
+		half++ // a carry into the exponent is still correct, up to Inf
+	}
+	return sign | uint16(half)
+}
+
+// halfToFloat32 converts a half-precision value exactly.
+func halfToFloat32(h uint16) float32 {
+	sign := uint32(h&0x8000) << 16
+	exp := uint32(h>>10) & 0x1f
+	mant := uint32(h & 0x3ff)
+	switch exp {
+	case 0:
+		if mant == 0 {
+			return math.Float32frombits(sign)
+		}
+		e := uint32(127 - 15 + 1)
+		for mant&0x400 == 0 {
+			mant <<= 1
+			e--
+		}
+		return math.Float32frombits(sign | e<<23 | (mant&0x3ff)<<13)
+	case 0x1f:
+		return math.Float32frombits(sign | 0x7f800000 | mant<<13)
+	}
+	return math.Float32frombits(sign | (exp+127-15)<<23 | mant<<13)
+}
@@ -0,0 +1,138 @@
+package lib
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFloat16_RoundTrip(t *testing.T) {
+	for _, tc := range []struct {
+		in, want float32
+	}{
+		{0, 0},
+		{1, 1},
+		{-2.5, -2.5},
+		{65504, 65504},                // largest half
+		{70000, float32(math.Inf(1))}, // overflow
+		{float32(math.Inf(-1)), float32(math.Inf(-1))},
+		{5.960464477539063e-08, 5.960464477539063e-08}, // smallest subnormal
+		{1e-9, 0},                    // underflow
+		{1.0009765625, 1.0009765625}, // 1 + 2^-10 is exact
+		{1.00048828125, 1},           // 1 + 2^-11 ties to even (1)
+		{1.00146484375, 1.001953125}, // 1 + 3·2^-11 ties to even (1 + 2^-9)
+	} {
+		if got := halfToFloat32(float32ToHalf(tc.in)); got != tc.want {
+			t.Errorf("round trip of %v = %v, want %v", tc.in, got, tc.want)
+		}
+	}
+	if got := halfToFloat32(float32ToHalf(float32(math.NaN()))); !math.IsNaN(float64(got)) {
+		t.Errorf("NaN round trip = %v", got)
+	}
+	// Every half converts to a float32 that converts back to the same half.
+	for h := range 1 << 16 {
+		f := halfToFloat32(uint16(h))
+		if f != f {
+			continue
+		}
+		if back := float32ToHalf(f); back != uint16(h) {
+			t.Fatalf("half %#04x -> %v -> %#04x", h, f, back)
+		}
+	}
+}
+
+func TestFloat16_Storage(t *testing.T) {
+	db := NewVectorDBWithOptions(&Options{Storage: Float16})
+	_ = db.Add("a", []float32{0.1, 0.2, 0.3})
+	_ = db.Add("b", []float32{-0.3, 0.2, 0.1})
+	_ = db.Update("b", []float32{0.3, 0.2, 0.1})
+	if v := db.vectors["a"]; v.Data != nil || len(v.packed) != 6 {
+		t.Fatalf("expected 6 packed bytes and nil Data, got %v / %d bytes", v.Data, len(v.packed))
+	}
+	v, _ := db.Get("b")
+	if len(v.Data) != 3 || math.Abs(float64(v.Data[0])-0.3) > 1e-3 {
+		t.Errorf("Get must decode the vector, got %v", v.Data)
+	}
+	res, err := db.SearchWithOptions([]float32{0.1, 0.2, 0.3}, 2, &SearchOptions{IncludeVectors: true, Explain: true})
+	if err != nil {
+		t.Fatalf("search failed: %v", err)
+	}
+	if res.Results[0].ID != "a" || math.Abs(res.Results[0].Score-1) > 1e-3 || len(res.Results[0].Vector) != 3 {
+		t.Errorf("expected a first with score ~1 and its vector, got %+v", res.Results[0])
+	}
+	if mlt, _ := db.MoreLikeThis("a", 1, true); len(mlt.Results) != 1 || mlt.Results[0].ID != "b" {
+		t.Errorf("MoreLikeThis on packed storage: got %+v", mlt.Results)
+	}
+}
+
+func TestBFloat16_Conversion(t *testing.T) {
+	in := []float32{0, 1, -2, 3.14159, 1e30, float32(math.Inf(1))}
+	out := BFloat16ToFloat32(Float32ToBFloat16(in))
+	for i := range in {
+		if rel := math.Abs(float64(out[i]-in[i])) / math.Max(1, math.Abs(float64(in[i]))); rel > 1.0/256 && !math.IsInf(float64(in[i]), 0) {
+			t.Errorf("%v -> %v: relative error %v", in[i], out[i], rel)
+		}
+	}
+	if out[5] != in[5] || out[3] != 3.140625 {
+		t.Errorf("expected exact Inf and 3.140625, got %v", out)
+	}
+	if h := Float32ToBFloat16([]float32{float32(math.NaN())})[0]; !math.IsNaN(float64(BFloat16ToFloat32([]uint16{h})[0])) {
+		t.Errorf("NaN must stay NaN, got %#04x", h)
+	}
+
+	db := NewVectorDBWithOptions(&Options{Storage: BFloat16})
+	_ = db.Add("a", []float32{1, 2, 3})
+	if v, _ := db.Get("a"); v.Data[2] != 3 || len(db.vectors["a"].packed) != 6 {
+		t.Errorf("bfloat16 storage: got %v", v.Data)
+	}
+}
+
+func TestInt8_Storage(t *testing.T) {
+	for _, df := range []DistanceFunction{CosineSimilarity, DotProduct, EuclideanDistance, ManhattanDistance} {
+		db := NewVectorDBWithOptions(&Options{Distance: df, Storage: Int8})
+		_ = db.Add("a", []int8{-128, 5, 127})
+		_ = db.Add("b", []float32{3, -4, 0})
+		if v := db.vectors["a"]; v.Data != nil || len(v.packed) != 3 {
+			t.Fatalf("expected 3 packed bytes and nil Data, got %v / %d bytes", v.Data, len(v.packed))
+		}
+		query := []int8{1, -2, 3}
+		res, err := db.Search(query, 2)
+		if err != nil {
+			t.Fatalf("%v: search failed: %v", df, err)
+		}
+		for _, r := range res.Results {
+			v, _ := db.Get(r.ID)
+			want := DistanceFloat32([]float32{1, -2, 3}, v.Data, df)
+			if math.Abs(r.Score-want) > 1e-12 {
+				t.Errorf("%v: integer kernel scored %s %v, float path %v", df, r.ID, r.Score, want)
+			}
+		}
+	}
+
+	db := NewVectorDBWithOptions(&Options{Storage: Int8})
+	for _, bad := range [][]float32{{0.5, 1}, {128, 1}, {float32(math.NaN()), 1}} {
+		if err := db.Add("x", bad); err == nil {
+			t.Errorf("Int8 storage must reject %v", bad)
+		}
+	}
+	// A fractional query falls back to decoding.
+	_ = db.Add("a", []int8{1, 1})
+	if res, err := db.Search([]float32{0.5, 0.5}, 1); err != nil || math.Abs(res.Results[0].Score-1) > 1e-9 {
+		t.Errorf("fractional query: got %+v, %v", res, err)
+	}
+}
+
+func TestUint8_Storage(t *testing.T) {
+	db := NewVectorDBWithOptions(&Options{Distance: EuclideanDistance, Storage: Uint8})
+	_ = db.Add("a", []uint8{255, 0, 10})
+	_ = db.Add("b", []uint8{0, 0, 10})
+	if err := db.Add("c", []int8{-1, 0, 0}); err == nil {
+		t.Error("Uint8 storage must reject negative components")
+	}
+	res, err := db.Search([]uint8{250, 0, 10}, 2)
+	if err != nil || res.Results[0].ID != "a" || res.Results[0].Score != 5 || res.Results[1].Score != 250 {
+		t.Fatalf("expected a at 5 then b at 250, got %+v, %v", res, err)
+	}
+	if v, _ := db.Get("a"); v.Data[0] != 255 {
+		t.Errorf("Get must decode 255 exactly, got %v", v.Data)
+	}
+}
@@ -0,0 +1,18 @@
+package lib
+
+// VectorStore is the API shared by VectorDB and ShardedVectorDB, so application code can move
+// between one DB and several shards without edits. Methods behave as on VectorDB.
+type VectorStore interface {
+	Add(id string, data any, metadata ...VectorMetadata) error
+	Update(id string, data any, metadata ...VectorMetadata) error
+	Get(id string) (*Vector, error)
+	Delete(id string) error
+	Size() int
+	Search(query any, topK ...int) (*SearchResult, error)
+	SearchWithOptions(query any, topK int, opts *SearchOptions) (*SearchResult, error)
+}
+
+var (
+	_ VectorStore = (*VectorDB)(nil)
+	_ VectorStore = (*ShardedVectorDB)(nil)
+)
@@ -0,0 +1,68 @@
+package lib
+
+import (
+	"context"
+	"slices"
+)
+
+// streamEvery is how many vectors SearchStream scans between partial updates.
+const streamEvery = 4096
+
+// StreamUpdate is a partial or final answer from SearchStream.
+type StreamUpdate struct {
+	Results []SimilarityResult // Best results among the vectors scanned so far, best first.
+	Scanned int                // Vectors scanned so far.
+	Total   int                // Vectors the search scans in all.
+	Final   bool               // Set on the last update, whose Results are the search's.
+	Err     error              // Set on the final update when the search failed or ctx ended.
+}
+
+// SearchStream runs Search(query, k) in the background and sends the best k results found so
+// far every few thousand vectors scanned, then the final results, on the returned channel,
+// which is closed after the final update. An HTTP handler can flush the partial results while
+// the scan goes on. Partial updates are sent while the scan holds the read lock, so one is
+// dropped when the previous has not been received yet rather than stall writers. Cancel ctx
+// to stop the scan, or when no longer reading; the final update may then not be sent.
+func (db *VectorDB) SearchStream(ctx context.Context, query any, k int) <-chan StreamUpdate {
+	if k <= 0 {
+		k = 10
+	}
+	ch := make(chan StreamUpdate, 1)
+	go func() {
+		defer close(ch)
+		total := db.Size()
+		lowerIsBetter := db.distFunc.lowerIsBetter()
+		opts := &SearchOptions{progress: func(scanned int, best []SimilarityResult) error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			results := slices.Clone(best)
+			slices.SortFunc(results, func(a, b SimilarityResult) int {
+				if ranksBefore(a, b, lowerIsBetter) {
+					return -1
+				}
+				return 1
+			})
+			select {
+			case ch <- StreamUpdate{Results: results[:min(k, len(results))], Scanned: scanned, Total: max(total, scanned)}:
+			default:
+			}
+			return nil
+		}}
+		final := StreamUpdate{Final: true}
+		if err := ctx.Err(); err != nil {
+			final.Err = err
+		} else if res, err := db.searchCore(query, k, true, opts); err != nil {
+			final.Err = err
+		} else {
+			final.Results = res.Results
+			final.Scanned = res.stats.Candidates
+			final.Total = res.stats.Candidates
+		}
+		select {
+		case ch <- final:
+		case <-ctx.Done():
+		}
+	}()
+	return ch
+}
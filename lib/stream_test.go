@@ -0,0 +1,53 @@
+package lib
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+func TestSearchStream(t *testing.T) {
+	db := NewVectorDB(2, EuclideanDistance)
+	for i := range 3*streamEvery + 10 {
+		_ = db.Add(fmt.Sprint("v", i), []float32{float32(i), 1})
+	}
+	query := []float32{5000, 1}
+	want, _ := db.Search(query, 3)
+
+	var updates []StreamUpdate
+	for u := range db.SearchStream(context.Background(), query, 3) {
+		updates = append(updates, u)
+	}
+	if len(updates) < 2 {
+		t.Fatalf("expected a partial and a final update, got %+v", updates)
+	}
+	prev := 0
+	for _, u := range updates[:len(updates)-1] {
+		if u.Final || u.Err != nil || len(u.Results) != 3 || u.Scanned <= prev || u.Total != db.Size() {
+			t.Errorf("unexpected partial update %+v", u)
+		}
+		prev = u.Scanned
+	}
+	final := updates[len(updates)-1]
+	if !final.Final || final.Err != nil || final.Scanned != db.Size() || len(final.Results) != 3 {
+		t.Fatalf("unexpected final update %+v", final)
+	}
+	for i, r := range final.Results {
+		if r.ID != want.Results[i].ID || r.Score != want.Results[i].Score {
+			t.Errorf("final result %d is %+v, want %+v", i, r, want.Results[i])
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for u := range db.SearchStream(ctx, query, 3) {
+		if u.Final && u.Err == nil {
+			t.Error("a cancelled stream must not succeed")
+		}
+	}
+	for u := range db.SearchStream(context.Background(), []float32{1}, 3) {
+		if !u.Final || u.Err == nil {
+			t.Errorf("a bad query must end the stream with an error, got %+v", u)
+		}
+	}
+}
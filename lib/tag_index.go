@@ -0,0 +1,258 @@
+package lib
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"iter"
+	"slices"
+	"sort"
+	"time"
+)
+
+// tagIndex is an inverted index over the metadata keys registered with CreateTagIndex:
+// key -> value -> bitmap of vector ordinals.
+type tagIndex map[string]valueSet
+
+// CreateTagIndex maintains an inverted index over metadata key, covering both the tag and the
+// attribute of that name, so SearchOptions.Tags and Where filters on key scan only matching
+// vectors. Building it is O(n); creating an existing index is a no-op.
+func (db *VectorDB) CreateTagIndex(key string) error {
+	return db.CreateTagIndexContext(context.Background(), key, nil)
+}
+
+// CreateTagIndexContext is CreateTagIndex for large collections: progress, if not nil, is
+// called every 1000 vectors indexed and at the end, and a cancelled ctx stops the build
+// without creating the index. The write lock is held throughout.
+func (db *VectorDB) CreateTagIndexContext(ctx context.Context, key string, progress ProgressFunc) error {
+	if key == "" {
+		return errors.New("tag key cannot be empty")
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if _, ok := db.tagIndex[key]; ok {
+		return nil
+	}
+	start := time.Now()
+	values := make(valueSet)
+	n := 0
+	for _, v := range db.vectors {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		for value := range indexedValues(v, key) {
+			values.add(value, v.ord)
+		}
+		n++
+		reportProgress(progress, n, len(db.vectors))
+	}
+	if db.tagIndex == nil {
+		db.tagIndex = make(tagIndex)
+	}
+	db.tagIndex[key] = values
+	db.logIndexBuild("tag", len(db.vectors), start, "key", key, "values", len(values))
+	return nil
+}
+
+// DropTagIndex removes the index over key, if any.
+func (db *VectorDB) DropTagIndex(key string) {
+	db.mu.Lock()
+	delete(db.tagIndex, key)
+	db.mu.Unlock()
+}
+
+// TagIndexes returns the indexed keys in ascending order.
+func (db *VectorDB) TagIndexes() []string {
+	db.mu.RLock()
+	keys := make([]string, 0, len(db.tagIndex))
+	for k := range db.tagIndex {
+		keys = append(keys, k)
+	}
+	db.mu.RUnlock()
+	sort.Strings(keys)
+	return keys
+}
+
+// storeLocked puts v into m (db.vectors or a copy being built), reusing the ordinal of the
+// vector it replaces, and updates the indexes. Callers must hold the write lock.
+func (db *VectorDB) storeLocked(m map[string]*Vector, v *Vector) {
+	op := EventAdd
+	if old, ok := m[v.ID]; ok {
+		op = EventUpdate
+		db.indexRemove(old)
+		db.mem.used -= vectorMemory(old)
+		v.ord = old.ord
+	} else if n := len(db.freeOrds); n > 0 {
+		v.ord = db.freeOrds[n-1]
+		db.freeOrds = db.freeOrds[:n-1]
+	} else {
+		v.ord = uint32(len(db.byOrd))
+		db.byOrd = append(db.byOrd, nil)
+	}
+	db.byOrd[v.ord] = v
+	db.placeLocked(v)
+	db.accountLocked(v)
+	m[v.ID] = v
+	db.indexAdd(v)
+	db.emitLocked(op, v)
+}
+
+// removeLocked deletes v and releases its ordinal. Callers must hold the write lock.
+func (db *VectorDB) removeLocked(v *Vector) {
+	db.indexRemove(v)
+	delete(db.vectors, v.ID)
+	delete(db.history, v.ID)
+	db.byOrd[v.ord] = nil
+	db.freeOrds = append(db.freeOrds, v.ord)
+	db.mem.used -= vectorMemory(v)
+	db.emitLocked(EventDelete, v)
+}
+
+// indexAdd records v in every index. Callers must hold the write lock.
+func (db *VectorDB) indexAdd(v *Vector) {
+	if db.binaryIndex {
+		v.code = signCode(db.floats(v, nil))
+	}
+	for key, values := range db.tagIndex {
+		for value := range indexedValues(v, key) {
+			values.add(value, v.ord)
+		}
+	}
+}
+
+// indexRemove forgets v's metadata in every index. Callers must hold the write lock.
+func (db *VectorDB) indexRemove(v *Vector) {
+	for key, values := range db.tagIndex {
+		for value := range indexedValues(v, key) {
+			values.remove(value, v.ord)
+		}
+	}
+}
+
+// indexReset empties every index, keeping the registered keys.
+func (db *VectorDB) indexReset() {
+	for key := range db.tagIndex {
+		db.tagIndex[key] = make(valueSet)
+	}
+}
+
+// indexedValues yields the tag value and the attribute value(s) v has under key.
+// Attribute values are canonical (see normalizeValue); []string yields each element.
+func indexedValues(v *Vector, key string) iter.Seq[any] {
+	return func(yield func(any) bool) {
+		if t, ok := v.Metadata.Tags[key]; ok && !yield(t) {
+			return
+		}
+		a, ok := v.Metadata.Attributes[key]
+		if !ok {
+			return
+		}
+		if list, ok := a.([]string); ok {
+			for _, s := range list {
+				if !yield(s) {
+					return
+				}
+			}
+			return
+		}
+		yield(a)
+	}
+}
+
+// valueSet maps a canonical metadata value to the ordinals of the vectors carrying it.
+type valueSet map[any]*bitmap
+
+func (vs valueSet) add(value any, ord uint32) {
+	b, ok := vs[value]
+	if !ok {
+		b = &bitmap{}
+		vs[value] = b
+	}
+	b.add(ord)
+}
+
+func (vs valueSet) remove(value any, ord uint32) {
+	if b, ok := vs[value]; ok {
+		b.remove(ord)
+		if len(b.keys) == 0 {
+			delete(vs, value)
+		}
+	}
+}
+
+// postings returns the ordinals whose indexed values can satisfy c: a direct lookup for Eq and
+// In, a pass over the distinct values otherwise. The result may be shared with the index.
+func (vs valueSet) postings(c *condition) *bitmap {
+	var out *bitmap
+	union := func(b *bitmap) {
+		if out == nil {
+			out = b
+		} else {
+			out = out.or(b)
+		}
+	}
+	if operands, ok := c.equalityOperands(); ok {
+		for _, o := range operands {
+			if b, ok := vs[o]; ok {
+				union(b)
+			}
+		}
+	} else {
+		for value, b := range vs {
+			if c.matchValue(value) {
+				union(b)
+			}
+		}
+	}
+	if out == nil {
+		return &bitmap{}
+	}
+	return out
+}
+
+// scopeLocked yields the vectors a search must consider and how many there are at most.
+// Posting lists of the indexed conds plan uses and AllowIDs are intersected as bitmaps,
+// smallest first, before any vector is scored; without either every vector is scanned in
+// ordinal (arena) order and narrowed is false. Yielded vectors still have to pass the compiled
+// filter. Callers must hold db.mu.
+func (db *VectorDB) scopeLocked(opts *SearchOptions, conds []condition, plan Plan) (scope iter.Seq[*Vector], size int, narrowed bool) {
+	var sets []*bitmap
+	for i := range conds {
+		if values, ok := db.tagIndex[conds[i].key]; ok && db.useIndex(plan, values, &conds[i]) {
+			sets = append(sets, values.postings(&conds[i]))
+		}
+	}
+	if opts != nil && opts.AllowIDs != nil {
+		allow := &bitmap{}
+		for _, id := range opts.AllowIDs {
+			if v, ok := db.vectors[id]; ok {
+				allow.add(v.ord)
+			}
+		}
+		sets = append(sets, allow)
+	}
+	if len(sets) == 0 {
+		return func(yield func(*Vector) bool) {
+			for _, v := range db.byOrd {
+				if v != nil && !v.deleted && !yield(v) {
+					return
+				}
+			}
+		}, len(db.vectors), false
+	}
+	slices.SortFunc(sets, func(a, b *bitmap) int { return cmp.Compare(a.len(), b.len()) })
+	ords := sets[0]
+	for _, b := range sets[1:] {
+		if len(ords.keys) == 0 {
+			break
+		}
+		ords = ords.and(b)
+	}
+	return func(yield func(*Vector) bool) {
+		for ord := range ords.all() {
+			if v := db.byOrd[ord]; !v.deleted && !yield(v) {
+				return
+			}
+		}
+	}, ords.len(), true
+}
@@ -0,0 +1,149 @@
+package lib
+
+import (
+	"fmt"
+	"testing"
+)
+
+func tagged(category string) VectorMetadata {
+	return VectorMetadata{Tags: map[string]string{"category": category}}
+}
+
+func TestTagIndex_NarrowsScan(t *testing.T) {
+	db := NewVectorDB(2)
+	for i := range 100 {
+		cat := "other"
+		if i%10 == 0 {
+			cat = "food"
+		}
+		_ = db.Add(fmt.Sprintf("v%d", i), []float32{1, float32(i)}, tagged(cat))
+	}
+	if err := db.CreateTagIndex("category"); err != nil {
+		t.Fatalf("CreateTagIndex failed: %v", err)
+	}
+
+	calls := 0
+	res, err := db.SearchWithOptions([]float32{1, 0}, 100, &SearchOptions{
+		Tags:   map[string]string{"category": "food"},
+		Filter: func(*Vector) bool { calls++; return true },
+	})
+	if err != nil {
+		t.Fatalf("search failed: %v", err)
+	}
+	if res.Total != 10 || calls != 10 {
+		t.Errorf("expected 10 results from 10 candidates, got %d results, %d filter calls", res.Total, calls)
+	}
+
+	res, _ = db.SearchWithOptions([]float32{1, 0}, 100, &SearchOptions{Tags: map[string]string{"category": "none"}})
+	if res.Total != 0 {
+		t.Errorf("unknown value must match nothing, got %d", res.Total)
+	}
+}
+
+func TestTagIndex_TracksMutations(t *testing.T) {
+	db := NewVectorDB(2)
+	_ = db.CreateTagIndex("category")
+	_ = db.Add("a", []float32{1, 0}, tagged("food"))
+	_ = db.Add("b", []float32{0, 1}, tagged("food"))
+	_ = db.BatchAdd(map[string]any{"c": []float32{1, 1}}, map[string]VectorMetadata{"c": tagged("food")})
+	_ = db.Update("a", []float32{1, 0}, tagged("drink"))
+	_ = db.Add("b", []float32{0, 1}, tagged("drink")) // overwrite
+	_ = db.Delete("c")
+
+	search := func(cat string) int {
+		res, err := db.SearchWithOptions([]float32{1, 0}, 10, &SearchOptions{Tags: map[string]string{"category": cat}})
+		if err != nil {
+			t.Fatalf("search failed: %v", err)
+		}
+		return res.Total
+	}
+	if n := search("food"); n != 0 {
+		t.Errorf("food: got %d, want 0", n)
+	}
+	if n := search("drink"); n != 2 {
+		t.Errorf("drink: got %d, want 2", n)
+	}
+	if _, ok := db.tagIndex["category"]["food"]; ok {
+		t.Error("empty posting lists should be removed")
+	}
+
+	db.Clear()
+	_ = db.Add("d", []float32{1, 0}, tagged("drink"))
+	if n := search("drink"); n != 1 {
+		t.Errorf("after Clear: got %d, want 1", n)
+	}
+}
+
+func TestTagIndex_WithAllowIDsAndUnindexedKeys(t *testing.T) {
+	db := NewVectorDB(2)
+	_ = db.Add("a", []float32{1, 0}, VectorMetadata{Tags: map[string]string{"category": "food", "lang": "en"}})
+	_ = db.Add("b", []float32{1, 0}, VectorMetadata{Tags: map[string]string{"category": "food", "lang": "fr"}})
+	_ = db.Add("c", []float32{1, 0}, VectorMetadata{Tags: map[string]string{"category": "food", "lang": "en"}})
+	_ = db.CreateTagIndex("category")
+
+	res, err := db.SearchWithOptions([]float32{1, 0}, 10, &SearchOptions{
+		Tags:     map[string]string{"category": "food", "lang": "en"},
+		AllowIDs: []string{"a", "b"},
+	})
+	if err != nil {
+		t.Fatalf("search failed: %v", err)
+	}
+	if res.Total != 1 || res.Results[0].ID != "a" {
+		t.Errorf("expected only a, got %+v", res.Results)
+	}
+
+	db.DropTagIndex("category")
+	if keys := db.TagIndexes(); len(keys) != 0 {
+		t.Errorf("TagIndexes after drop = %v", keys)
+	}
+	res, _ = db.SearchWithOptions([]float32{1, 0}, 10, &SearchOptions{Tags: map[string]string{"lang": "en"}})
+	if res.Total != 2 {
+		t.Errorf("unindexed Tags filter: got %d, want 2", res.Total)
+	}
+	if err := db.CreateTagIndex(""); err == nil {
+		t.Error("expected empty key to fail")
+	}
+}
+
+func TestTagIndex_IntersectsBitmaps(t *testing.T) {
+	db := NewVectorDB(2)
+	_ = db.CreateTagIndex("category")
+	_ = db.CreateTagIndex("year")
+	for i := range 10000 {
+		meta := VectorMetadata{
+			Tags:       map[string]string{"category": fmt.Sprint(i % 7)},
+			Attributes: map[string]any{"year": 2000 + i%25},
+		}
+		_ = db.Add(fmt.Sprintf("v%d", i), []float32{1, float32(i)}, meta)
+	}
+	// Free some ordinals and reuse them with different metadata.
+	for i := 0; i < 10000; i += 3 {
+		_ = db.Delete(fmt.Sprintf("v%d", i))
+	}
+	for i := range 100 {
+		_ = db.Add(fmt.Sprintf("new%d", i), []float32{1, 0}, VectorMetadata{
+			Tags:       map[string]string{"category": "3"},
+			Attributes: map[string]any{"year": 2024},
+		})
+	}
+
+	want := 0
+	for _, id := range db.IDs() {
+		v, _ := db.Get(id)
+		if v.Metadata.Tags["category"] == "3" && v.Metadata.Attributes["year"].(float64) >= 2020 {
+			want++
+		}
+	}
+	calls := 0
+	res, err := db.SearchWithOptions([]float32{1, 0}, 100000, &SearchOptions{
+		Tags:   map[string]string{"category": "3"},
+		Where:  []Condition{Gte("year", 2020)},
+		Filter: func(*Vector) bool { calls++; return true },
+	})
+	if err != nil {
+		t.Fatalf("search failed: %v", err)
+	}
+	if res.Total != want || calls != want {
+		t.Errorf("got %d results after %d filter calls, want %d", res.Total, calls, want)
+	}
+}
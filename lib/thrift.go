@@ -0,0 +1,278 @@
+package lib
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Thrift compact protocol type IDs (used by Parquet file and page metadata).
+const (
+	thriftBoolTrue  = 1
+	thriftBoolFalse = 2
+	thriftByte      = 3
+	thriftI16       = 4
+	thriftI32       = 5
+	thriftI64       = 6
+	thriftDouble    = 7
+	thriftBinary    = 8
+	thriftList      = 9
+	thriftSet       = 10
+	thriftMap       = 11
+	thriftStructT   = 12
+)
+
+// thriftStruct is a decoded compact-protocol struct keyed by field ID.
+// Integers decode to int64, binary to []byte, lists to []any.
+type thriftStruct map[int16]any
+
+func (s thriftStruct) int(id int16) (int64, bool) {
+	v, ok := s[id].(int64)
+	return v, ok
+}
+
+func (s thriftStruct) str(id int16) string {
+	b, _ := s[id].([]byte)
+	return string(b)
+}
+
+func (s thriftStruct) list(id int16) []any {
+	l, _ := s[id].([]any)
+	return l
+}
+
+func (s thriftStruct) strct(id int16) thriftStruct {
+	v, _ := s[id].(thriftStruct)
+	return v
+}
+
+// thriftReader decodes compact-protocol values from a byte slice.
+type thriftReader struct {
+	b   []byte
+	pos int
+}
+
+var errThriftShort = errors.New("thrift: unexpected end of data")
+
+func (r *thriftReader) byte() (byte, error) {
+	if r.pos >= len(r.b) {
+		return 0, errThriftShort
+	}
+	c := r.b[r.pos]
+	r.pos++
+	return c, nil
+}
+
+func (r *thriftReader) varint() (uint64, error) {
+	v, n := binary.Uvarint(r.b[r.pos:])
+	if n <= 0 {
+		return 0, errThriftShort
+	}
+	r.pos += n
+	return v, nil
+}
+
+func (r *thriftReader) zigzag() (int64, error) {
+	u, err := r.varint()
+	if err != nil {
+		return 0, err
+	}
+	return int64(u>>1) ^ -int64(u&1), nil
+}
+
+func (r *thriftReader) readStruct() (thriftStruct, error) {
+	s := thriftStruct{}
+	var last int16
+	for {
+		h, err := r.byte()
+		if err != nil {
+			return nil, err
+		}
+		if h == 0 {
+			return s, nil
+		}
+		id := last + int16(h>>4)
+		if h>>4 == 0 {
+			v, err := r.zigzag()
+			if err != nil {
+				return nil, err
+			}
+			id = int16(v)
+		}
+		last = id
+		switch typ := h & 0x0f; typ {
+		case thriftBoolTrue:
+			s[id] = true
+		case thriftBoolFalse:
+			s[id] = false
+		default:
+			v, err := r.readValue(typ)
+			if err != nil {
+				return nil, err
+			}
+			s[id] = v
+		}
+	}
+}
+
+func (r *thriftReader) readValue(typ byte) (any, error) {
+	switch typ {
+	case thriftBoolTrue, thriftBoolFalse:
+		// Inside collections booleans are a single byte.
+		c, err := r.byte()
+		return c == thriftBoolTrue, err
+	case thriftByte:
+		c, err := r.byte()
+		return int64(int8(c)), err
+	case thriftI16, thriftI32, thriftI64:
+		return r.zigzag()
+	case thriftDouble:
+		if r.pos+8 > len(r.b) {
+			return nil, errThriftShort
+		}
+		v := math.Float64frombits(binary.LittleEndian.Uint64(r.b[r.pos:]))
+		r.pos += 8
+		return v, nil
+	case thriftBinary:
+		n, err := r.varint()
+		if err != nil {
+			return nil, err
+		}
+		if n > uint64(len(r.b)-r.pos) {
+			return nil, errThriftShort
+		}
+		v := r.b[r.pos : r.pos+int(n)]
+		r.pos += int(n)
+		return v, nil
+	case thriftList, thriftSet:
+		h, err := r.byte()
+		if err != nil {
+			return nil, err
+		}
+		n := uint64(h >> 4)
+		if n == 15 {
+			if n, err = r.varint(); err != nil {
+				return nil, err
+			}
+		}
+		if n > uint64(len(r.b)-r.pos) {
+			return nil, errThriftShort
+		}
+		out := make([]any, n)
+		for i := range out {
+			if out[i], err = r.readValue(h & 0x0f); err != nil {
+				return nil, err
+			}
+		}
+		return out, nil
+	case thriftMap:
+		n, err := r.varint()
+		if err != nil || n == 0 {
+			return []any{}, err
+		}
+		if n > uint64(len(r.b)-r.pos) {
+			return nil, errThriftShort
+		}
+		kv, err := r.byte()
+		if err != nil {
+			return nil, err
+		}
+		out := make([]any, 0, 2*n)
+		for i := uint64(0); i < n; i++ {
+			k, err := r.readValue(kv >> 4)
+			if err != nil {
+				return nil, err
+			}
+			v, err := r.readValue(kv & 0x0f)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, k, v)
+		}
+		return out, nil
+	case thriftStructT:
+		return r.readStruct()
+	default:
+		return nil, fmt.Errorf("thrift: unknown compact type %d", typ)
+	}
+}
+
+// thriftWriter encodes compact-protocol structs. Field IDs must be written in
+// ascending order within each struct.
+type thriftWriter struct {
+	buf  bytes.Buffer
+	last []int16
+}
+
+func newThriftWriter() *thriftWriter {
+	return &thriftWriter{last: []int16{0}}
+}
+
+func (w *thriftWriter) varint(v uint64) {
+	var b [binary.MaxVarintLen64]byte
+	w.buf.Write(b[:binary.PutUvarint(b[:], v)])
+}
+
+func (w *thriftWriter) zigzag(v int64) { w.varint(uint64(v<<1) ^ uint64(v>>63)) }
+
+func (w *thriftWriter) field(id int16, typ byte) {
+	top := len(w.last) - 1
+	if d := id - w.last[top]; d > 0 && d <= 15 {
+		w.buf.WriteByte(byte(d)<<4 | typ)
+	} else {
+		w.buf.WriteByte(typ)
+		w.zigzag(int64(id))
+	}
+	w.last[top] = id
+}
+
+func (w *thriftWriter) i32(id int16, v int32) {
+	w.field(id, thriftI32)
+	w.zigzag(int64(v))
+}
+
+func (w *thriftWriter) i64(id int16, v int64) {
+	w.field(id, thriftI64)
+	w.zigzag(v)
+}
+
+func (w *thriftWriter) str(id int16, s string) {
+	w.field(id, thriftBinary)
+	w.rawStr(s)
+}
+
+func (w *thriftWriter) rawStr(s string) {
+	w.varint(uint64(len(s)))
+	w.buf.WriteString(s)
+}
+
+func (w *thriftWriter) listBegin(id int16, elemType byte, n int) {
+	w.field(id, thriftList)
+	if n < 15 {
+		w.buf.WriteByte(byte(n)<<4 | elemType)
+		return
+	}
+	w.buf.WriteByte(0xf0 | elemType)
+	w.varint(uint64(n))
+}
+
+// structBegin opens a nested struct; pass id < 0 for a list element.
+func (w *thriftWriter) structBegin(id int16) {
+	if id >= 0 {
+		w.field(id, thriftStructT)
+	}
+	w.last = append(w.last, 0)
+}
+
+func (w *thriftWriter) structEnd() {
+	w.buf.WriteByte(0)
+	w.last = w.last[:len(w.last)-1]
+}
+
+// bytes terminates the top-level struct and returns the encoding.
+func (w *thriftWriter) bytes() []byte {
+	w.buf.WriteByte(0)
+	return w.buf.Bytes()
+}
@@ -0,0 +1,71 @@
+package lib
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Transform maps vectors into a smaller space, e.g. a PCA fitted with FitPCA or a
+// RandomProjection. A DB with a
+// Transform (Options.Transform, or one returned by Transformed) applies it to every vector
+// written and every query, so callers keep passing full-size embeddings.
+type Transform interface {
+	// Dimensions returns the input and output vector lengths.
+	Dimensions() (in, out int)
+	// Apply writes the transform of v, of length in, to dst, of length out.
+	Apply(dst, v []float32)
+}
+
+// transformInput maps a written vector or query through the DB's Transform. Vectors that
+// already have the output dimension, such as those returned by Get or Centroid, pass as
+// they are.
+func (db *VectorDB) transformInput(v []float32) ([]float32, error) {
+	if db.transform == nil {
+		return v, nil
+	}
+	in, out := db.transform.Dimensions()
+	switch len(v) {
+	case in:
+		dst := make([]float32, out)
+		db.transform.Apply(dst, v)
+		return dst, nil
+	case out:
+		return v, nil
+	}
+	return nil, fmt.Errorf("vector dimension %d matches neither the transform input %d nor its output %d", len(v), in, out)
+}
+
+// Transformed copies every vector through t into a new DB that keeps applying t to writes
+// and queries. The new DB keeps db's distance, storage type, options and tag indexes; its
+// dimension is t's output, and a MahalanobisDistance covariance must be fitted again. db
+// itself is unchanged. It fails if db already has a Transform or holds a vector whose
+// dimension is not t's input.
+func (db *VectorDB) Transformed(t Transform) (*VectorDB, error) {
+	if t == nil {
+		panic("transform cannot be nil")
+	}
+	in, out := t.Dimensions()
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	if db.transform != nil {
+		return nil, errors.New("the DB is already transformed")
+	}
+	c := db.newPartLocked()
+	c.dimension, c.covChol, c.transform = out, nil, t
+	var buf []float32
+	for _, v := range db.byOrd {
+		if v == nil || v.deleted {
+			continue
+		}
+		if v.Dimension != in {
+			return nil, fmt.Errorf("vector %s dimension %d does not match the transform input %d", v.ID, v.Dimension, in)
+		}
+		data := make([]float32, out)
+		t.Apply(data, db.floats(v, &buf))
+		if err := c.checkVector(data); err != nil {
+			return nil, fmt.Errorf("vector %s: %w", v.ID, err)
+		}
+		c.storeLocked(c.vectors, &Vector{ID: v.ID, Data: data, Metadata: v.Metadata, Dimension: out})
+	}
+	return c, nil
+}
@@ -3,36 +3,60 @@ package lib
 import (
 	"fmt"
 	"math"
+	"time"
 )
 
-// copyFloat32Slice copies []float32 and returns (copy, dimension, error). Rejects non-float32.
+// copyFloat32Slice copies []float32, or converts []int8 and []uint8 (e.g. pre-quantized
+// embeddings), and returns (copy, dimension, error). Rejects other types.
 func copyFloat32Slice(data any) ([]float32, int, error) {
-	v, ok := data.([]float32)
-	if !ok {
-		return nil, 0, fmt.Errorf("unsupported vector type: %T (use []float32)", data)
+	var c []float32
+	switch v := data.(type) {
+	case []float32:
+		c = append([]float32(nil), v...)
+	case []int8:
+		c = intsToFloat32(v)
+	case []uint8:
+		c = intsToFloat32(v)
+	default:
+		return nil, 0, fmt.Errorf("unsupported vector type: %T (use []float32, []int8 or []uint8)", data)
 	}
-	if len(v) == 0 {
+	if len(c) == 0 {
 		return nil, 0, nil
 	}
-	c := make([]float32, len(v))
-	copy(c, v)
 	return c, len(c), nil
 }
 
-// queryToFloat32 validates and returns the query as []float32.
+// queryToFloat32 validates and returns the query as []float32, converting []int8 and []uint8.
 func queryToFloat32(query any) ([]float32, error) {
-	v, ok := query.([]float32)
-	if !ok {
-		return nil, fmt.Errorf("unsupported query type: %T (use []float32)", query)
+	switch v := query.(type) {
+	case []float32:
+		return v, nil
+	case []int8:
+		return intsToFloat32(v), nil
+	case []uint8:
+		return intsToFloat32(v), nil
 	}
-	return v, nil
+	return nil, fmt.Errorf("unsupported query type: %T (use []float32, []int8 or []uint8)", query)
 }
 
-// VectorType is the scalar type for vector storage. Only Float32 is supported.
+func intsToFloat32[T int8 | uint8](v []T) []float32 {
+	out := make([]float32, len(v))
+	for i, x := range v {
+		out[i] = float32(x)
+	}
+	return out
+}
+
+// VectorType is the scalar type for vector storage (Options.Storage). Vectors are always
+// added and returned as []float32; other types are packed on insert and decoded on read.
 type VectorType int
 
 const (
-	Float32 VectorType = iota
+	Float32  VectorType = iota
+	Float16             // IEEE half precision: half the memory, about 3 significant digits.
+	BFloat16            // bfloat16: half the memory, float32 range, about 2 significant digits.
+	Int8                // Integers in [-128, 127], a quarter of the memory, scored in integer arithmetic.
+	Uint8               // Integers in [0, 255], a quarter of the memory, scored in integer arithmetic.
 )
 
 // DistanceFunction represents different distance/similarity metrics
@@ -43,14 +67,34 @@ const (
 	DotProduct
 	EuclideanDistance
 	ManhattanDistance
+	// JaccardSimilarity is Σmin(a,b) / Σmax(a,b): set overlap for 0/1 vectors (fingerprints,
+	// tag sets), weighted overlap for other non-negative vectors.
+	JaccardSimilarity
+	// TanimotoSimilarity is a·b / (|a|² + |b|² - a·b); equal to Jaccard on 0/1 vectors.
+	TanimotoSimilarity
+	// PearsonCorrelation is the cosine of the mean-centred vectors, in [-1, 1]: invariant to
+	// offset and scale, as with user rating vectors.
+	PearsonCorrelation
+	// KLDivergence is Σ q·ln(q/v) of query distribution q from stored distribution v (lower is
+	// better; +Inf where v is 0 but q is not). Vectors must be probability distributions.
+	KLDivergence
+	// JensenShannonDistance is the square root of the base-2 Jensen-Shannon divergence, a
+	// symmetric metric in [0, 1] (lower is better). Vectors must be probability distributions.
+	JensenShannonDistance
+	// MahalanobisDistance is sqrt((a-b)ᵀ Σ⁻¹ (a-b)) for the covariance Σ set by FitCovariance or
+	// SetCovariance (lower is better): distance in units of the data's spread, for anomaly
+	// detection. Scoring is O(d²) per vector. DistanceFloat32 has no covariance and uses Σ = I.
+	MahalanobisDistance
 )
 
-// VectorMetadata holds additional information about vectors
+// VectorMetadata holds additional information about vectors.
+// Attributes holds typed values: string, bool, numbers (stored as float64), time.Time and []string.
 type VectorMetadata struct {
-	CreatedAt int64             `json:"created_at,omitempty"`
-	UpdatedAt int64             `json:"updated_at,omitempty"`
-	Tags      map[string]string `json:"tags,omitempty"`
-	Score     float64           `json:"score,omitempty"` // Internal use
+	CreatedAt  int64             `json:"created_at,omitempty"`
+	UpdatedAt  int64             `json:"updated_at,omitempty"`
+	Tags       map[string]string `json:"tags,omitempty"`
+	Attributes map[string]any    `json:"attributes,omitempty"`
+	Score      float64           `json:"score,omitempty"` // Internal use
 }
 
 // ValidationResult holds the result of vector validation
@@ -72,20 +116,97 @@ type Vector struct {
 	Data      []float32
 	Metadata  VectorMetadata
 	Dimension int
+	ord       uint32   // position in VectorDB.byOrd
+	code      []uint64 // sign bits of Data while the binary index is enabled
+	packed    []byte   // Data in the DB's storage type when it is not Float32; Data is then nil
+	deleted   bool     // soft-deleted: still in byOrd and the indexes until Vacuum
 }
 
 // SimilarityResult holds the result of a similarity search
 type SimilarityResult struct {
-	ID       string
-	Score    float64
-	Metadata VectorMetadata
+	ID          string
+	Score       float64
+	Metadata    VectorMetadata
+	Group       string       // Group value when searching with SearchOptions.GroupBy
+	Vector      []float32    // Copy of the stored vector with SearchOptions.IncludeVectors
+	Explanation *Explanation // Scoring detail with SearchOptions.Explain
 }
 
 // SearchResult contains the search results with scores
 type SearchResult struct {
-	QueryID string
-	Results []SimilarityResult
-	Total   int
+	QueryID    string
+	Results    []SimilarityResult
+	Total      int
+	NextCursor string        // Set when another page follows; pass as SearchOptions.Cursor.
+	Stats      *SearchStats  // Set with SearchOptions.Explain.
+	Took       time.Duration // Search latency, including waiting for the DB lock.
+	Truncated  bool          // Set when SearchOptions.MaxDuration ended the scan early.
+
+	stats SearchStats // Always collected; Stats exposes it with Explain.
+}
+
+// SearchOptions configures SearchWithOptions. Nil or zero values use defaults.
+type SearchOptions struct {
+	Filter     func(*Vector) bool // Optional predicate, as in SearchWithFilter.
+	ExcludeIDs []string           // IDs skipped at scoring time (e.g. already shown, or the seeds).
+	AllowIDs   []string           // If non-nil, only these IDs are searched; unknown IDs are ignored.
+	Tags       map[string]string  // Exact tag matches; keys with a tag index (CreateTagIndex) skip the scan.
+	Where      []Condition        // Metadata conditions (Eq, Gt, In, Prefix, ...), all of which must hold; indexed keys skip the scan.
+	GroupBy    string             // Collapse results sharing this metadata key (attribute, else tag); topK then counts groups.
+	GroupSize  int                // Best results kept per group with GroupBy. Default 1.
+
+	// Weights scales each query dimension's contribution to the distance (len must match the
+	// query; values must be >= 0). Useful for emphasising features without re-embedding.
+	Weights []float32
+
+	// Two-stage search: a cheap first pass over every candidate, then exact rescoring of the
+	// best RescoreTop. Dimensions scores only that many leading dimensions (0 means all), which
+	// suits MRL embeddings whose prefixes are embeddings themselves; Binary ranks by Hamming
+	// distance between sign bits (needs CreateBinaryIndex). Without RescoreTop, truncated
+	// scores are final and Binary rescores DefaultBinaryRescore × (Offset+topK) candidates.
+	Dimensions int
+	Binary     bool
+	RescoreTop int
+
+	// Epsilon stops the scan as soon as the worst result kept is within Epsilon of the best
+	// score the distance can give (1 for similarities, 0 for distances), so no vector left
+	// unscanned could beat it by more: e.g. 0.05 with CosineSimilarity stops once topK
+	// results reach 0.95. Results are then approximate, within Epsilon of the exact ones,
+	// in exchange for bounded latency on near-duplicate-rich data. Not supported with
+	// DotProduct, GroupBy, Boosts, Decay, Rescore or RescoreTop. Default 0 scans everything.
+	Epsilon float64
+
+	// MaxDuration bounds the scan: once it has run this long, the search returns the best
+	// results among the vectors scored so far with SearchResult.Truncated set, rather than
+	// risk the function's timeout. Default 0 scans everything.
+	MaxDuration time.Duration
+
+	// Plan overrides how candidates are found: PlanAuto (default) decides per query whether
+	// tag indexes and a two-stage first pass pay off, PlanScan never uses them and PlanIndex
+	// always does.
+	Plan Plan
+
+	// Model names the embedding model that produced the query; with Options.Model set, a
+	// different one fails the search. Default "" is not checked.
+	Model string
+
+	// Scoring: Boosts apply in order to each score, then Decay, then Rescore (if set) gets the final say.
+	Boosts  []Boost
+	Decay   *Decay
+	Rescore func(v *Vector, score float64) float64
+
+	// Pagination: skip Offset ranked results (groups with GroupBy), or resume from
+	// SearchResult.NextCursor of the previous page. Cursor wins when both are set.
+	Offset int
+	Cursor string
+
+	// Projection: by default each result carries the vector's full metadata.
+	OmitMetadata   bool     // Return IDs and scores only.
+	MetadataKeys   []string // If non-nil, keep only these tag and attribute keys (timestamps are always kept).
+	IncludeVectors bool     // Copy each result's stored vector into SimilarityResult.Vector.
+	Explain        bool     // Attach scoring detail to each result and SearchStats to the response.
+
+	progress func(scanned int, best []SimilarityResult) error // SearchStream; see streamEvery
 }
 
 // MMROptions configures Maximal Marginal Relevance search. Nil or zero values use defaults.
@@ -126,11 +247,44 @@ func (df DistanceFunction) String() string {
 		return "euclidean_distance"
 	case ManhattanDistance:
 		return "manhattan_distance"
+	case JaccardSimilarity:
+		return "jaccard_similarity"
+	case TanimotoSimilarity:
+		return "tanimoto_similarity"
+	case PearsonCorrelation:
+		return "pearson_correlation"
+	case KLDivergence:
+		return "kl_divergence"
+	case JensenShannonDistance:
+		return "jensen_shannon_distance"
+	case MahalanobisDistance:
+		return "mahalanobis_distance"
 	default:
 		return "unknown"
 	}
 }
 
+// lowerIsBetter reports whether df is a distance, ranked ascending, rather than a similarity.
+func (df DistanceFunction) lowerIsBetter() bool {
+	switch df {
+	case EuclideanDistance, ManhattanDistance, KLDivergence, JensenShannonDistance, MahalanobisDistance:
+		return true
+	}
+	return false
+}
+
+// bestScore returns the best score df can give: 0 for distances and 1 for similarities, or false
+// for DotProduct, which is unbounded.
+func (df DistanceFunction) bestScore() (float64, bool) {
+	switch {
+	case df == DotProduct:
+		return 0, false
+	case df.lowerIsBetter():
+		return 0, true
+	}
+	return 1, true
+}
+
 // NormalizeVector normalizes a float32 vector to unit length.
 func NormalizeVector(data []float32) []float32 {
 	if len(data) == 0 {
@@ -1,10 +1,14 @@
 package lib
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"log/slog"
 	"maps"
+	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -12,50 +16,254 @@ import (
 type VectorDB struct {
 	mu        sync.RWMutex
 	vectors   map[string]*Vector
-	dimension int
+	dimension int  // 0 until the first vector unless fixed at construction
+	flexible  bool // never infer dimension (Options.FlexibleDimensions)
 	distFunc  DistanceFunction
+	codec     codec // nil for Float32 storage
+	embedder  Embedder
+	tagIndex  tagIndex  // nil until CreateTagIndex
+	byOrd     []*Vector // vector by ordinal (nil when free); ordinals are what indexes store
+	freeOrds  []uint32  // ordinals released by Delete, reused first
+
+	dataArena   arena[float32] // Vector.Data by ordinal once the dimension is fixed
+	packedArena arena[byte]    // Vector.packed by ordinal once the dimension is fixed
+
+	binaryIndex bool      // keep Vector.code (CreateBinaryIndex)
+	covChol     []float64 // Cholesky factor of the covariance for MahalanobisDistance
+
+	trash      map[string]*Vector // soft-deleted vectors by ID; they keep their ordinals until Vacuum
+	autoVacuum float64            // Options.AutoVacuum
+
+	keepVersions int                  // Options.KeepVersions
+	history      map[string][]*Vector // previous versions by ID, newest first
+
+	events eventLog              // change stream for Subscribe
+	hooks  atomic.Pointer[Hooks] // SetHooks
+
+	logger     *slog.Logger  // Options.Logger; nil logs nothing
+	slowSearch time.Duration // Options.SlowSearch
+	slow       slowLog       // SlowQueries
+
+	mem    memoryBudget // Options.MaxMemoryBytes
+	limits limits       // Options.MaxVectors, MaxDimension and MaxMetadataBytes
+
+	transform Transform // Options.Transform; nil stores vectors as given
+	model     string    // Options.Model; "" accepts any
+
+	tokens tokenSet    // AddIdempotent tokens
+	newID  IDGenerator // Options.IDGenerator; nil uses NewUUIDv7
+
+	bgMu          sync.Mutex                 // guards autoSnapshots
+	autoSnapshots map[*AutoSnapshot]struct{} // running StartAutoSnapshot loops, stopped by Close
 }
 
 // NewVectorDB creates a new vector database
 // dimension: vector dimension (e.g., 384 for OpenAI, 1536 for text-embedding-ada-002)
 //
-//	use 0 for no dimension validation (flexible dimensions)
+//	use 0 to take the dimension from the first vector added
 //
 // distanceFunc: optional distance function (defaults to CosineSimilarity if not provided)
 func NewVectorDB(dimension int, distanceFunc ...DistanceFunction) *VectorDB {
 	if dimension < 0 {
-		panic("dimension must be >= 0 (use 0 for no validation)")
+		panic("dimension must be >= 0 (use 0 to infer it)")
 	}
 
 	df := CosineSimilarity // smart default for embeddings
 	if len(distanceFunc) > 0 {
 		df = distanceFunc[0]
 	}
+	return NewVectorDBWithOptions(&Options{Dimension: dimension, Distance: df})
+}
+
+// Options configures NewVectorDBWithOptions. Nil or zero values use defaults.
+type Options struct {
+	Dimension int              // Vector dimension; 0 takes it from the first vector added.
+	Distance  DistanceFunction // Default CosineSimilarity.
+
+	// FlexibleDimensions keeps a 0 Dimension unset, so vectors of any length can be stored.
+	// Searches then fail on stored vectors whose dimension differs from the query.
+	FlexibleDimensions bool
+
+	// Storage is the scalar type vectors are kept in. Default Float32. With any other type,
+	// the *Vector passed to SearchOptions.Filter and Rescore has nil Data; use Get to read it.
+	Storage VectorType
+
+	// Logger receives slow searches and rejected writes (Warn), imports and exports (Info, or
+	// Error when they fail) and index builds (Info). Default nil logs nothing.
+	Logger *slog.Logger
+
+	// SlowSearch is the latency from which a search is logged as slow and kept for
+	// SlowQueries. Default 0 records none.
+	SlowSearch time.Duration
+
+	// KeepVersions is how many previous versions of each vector Update retains for GetVersion
+	// and RevertTo. Default 0 keeps none.
+	KeepVersions int
+
+	// MaxMemoryBytes bounds the estimated vector memory (memory_usage_kb in GetStats). A write
+	// that exceeds it evicts the least recently used vectors (last added, updated, read by Get
+	// or returned by a search) until usage is back under 90% of it. Default 0 is unbounded.
+	MaxMemoryBytes int64
+
+	// OnEvict receives a copy of each vector evicted by MaxMemoryBytes, after the write lock
+	// is released, e.g. to spill it to S3 or DynamoDB. Evictions also emit EventDelete.
+	OnEvict func(v *Vector)
 
+	// IDGenerator makes the IDs of vectors stored with AddAuto. Default NewUUIDv7.
+	IDGenerator IDGenerator
+
+	// Caps that stop a runaway ingestion job before it exhausts the function's memory. A
+	// write that would exceed one fails with a *QuotaError naming it and stores nothing (or,
+	// for BatchAddPartial, skips the vectors that do not fit). Default 0 is unlimited.
+	MaxVectors       int // Vectors stored, not counting soft-deleted ones.
+	MaxDimension     int // Length of any one vector.
+	MaxMetadataBytes int // Tags and attributes of one vector: key and string lengths, 8 bytes per other value.
+
+	// AutoVacuum is the Staleness (share of held vectors that are soft-deleted) above which
+	// SoftDelete vacuums every soft-deleted vector, so a long-lived DB need not schedule
+	// Vacuum. Vacuumed vectors can no longer be restored. Default 0 never vacuums on its own.
+	AutoVacuum float64
+
+	// Transform, e.g. a PCA fitted with FitPCA, is applied to every vector written and every
+	// query; Dimension is then its output dimension. See also Transformed.
+	Transform Transform
+
+	// Model names the embedding model, and so the space, of the stored vectors, e.g.
+	// "text-embedding-3-small/1536". Writes whose ModelTag tag names another model, and
+	// searches whose SearchOptions.Model does, fail rather than mix or compare vectors from
+	// different spaces. Untagged writes and queries are accepted. Default "" checks nothing.
+	Model string
+}
+
+// NewVectorDBWithOptions creates a new vector database configured by opts.
+func NewVectorDBWithOptions(opts *Options) *VectorDB {
+	if opts == nil {
+		opts = &Options{}
+	}
+	if opts.Dimension < 0 {
+		panic("dimension must be >= 0 (use 0 to infer it)")
+	}
+	if opts.KeepVersions < 0 {
+		panic("keep versions must be >= 0")
+	}
+	if opts.MaxMemoryBytes < 0 {
+		panic("max memory bytes must be >= 0")
+	}
+	if opts.MaxVectors < 0 || opts.MaxDimension < 0 || opts.MaxMetadataBytes < 0 {
+		panic("max vectors, dimension and metadata bytes must be >= 0")
+	}
+	if opts.AutoVacuum < 0 || opts.AutoVacuum >= 1 {
+		panic("auto vacuum must be in [0, 1)")
+	}
+	dimension := opts.Dimension
+	if opts.Transform != nil {
+		_, out := opts.Transform.Dimensions()
+		if dimension != 0 && dimension != out {
+			panic("dimension must be 0 or the transform output dimension")
+		}
+		dimension = out
+	}
+	c, err := codecFor(opts.Storage)
+	if err != nil {
+		panic(err.Error())
+	}
 	return &VectorDB{
 		vectors:   make(map[string]*Vector),
 		dimension: dimension,
-		distFunc:  df,
+		flexible:  opts.FlexibleDimensions,
+		distFunc:  opts.Distance,
+		codec:     c,
+
+		keepVersions: opts.KeepVersions,
+		logger:       opts.Logger,
+		slowSearch:   opts.SlowSearch,
+		autoVacuum:   opts.AutoVacuum,
+
+		mem:       memoryBudget{max: opts.MaxMemoryBytes, onEvict: opts.OnEvict},
+		newID:     opts.IDGenerator,
+		limits:    limits{vectors: opts.MaxVectors, dimension: opts.MaxDimension, metadataBytes: opts.MaxMetadataBytes},
+		transform: opts.Transform,
+		model:     opts.Model,
 	}
 }
 
+// Dimension returns the vector dimension, or 0 while it is not yet known (or never will be,
+// with Options.FlexibleDimensions).
+func (db *VectorDB) Dimension() int {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	return db.dimension
+}
+
+// checkDimensionLocked validates dim, first fixing the DB dimension to it if it is still
+// unknown. Callers must hold the write lock and must not fail after calling it.
+func (db *VectorDB) checkDimensionLocked(dim int) error {
+	if db.dimension == 0 && !db.flexible {
+		db.dimension = dim
+	}
+	if db.dimension > 0 && dim != db.dimension {
+		return fmt.Errorf("vector dimension %d does not match expected %d", dim, db.dimension)
+	}
+	return nil
+}
+
 // Add adds a vector to the database. data must be []float32 (matches embedding APIs).
 func (db *VectorDB) Add(id string, data any, metadata ...VectorMetadata) error {
+	_, err := db.add("", id, data, metadata, false)
+	return err
+}
+
+// add implements Add, AddIdempotent and AddAuto; token "" is not checked or recorded. With
+// create, an existing ID is an error rather than replaced.
+func (db *VectorDB) add(token, id string, data any, metadata []VectorMetadata, create bool) (applied bool, err error) {
+	defer db.logRejected("add", id, &err)
 	if id == "" {
-		return errors.New("vector ID cannot be empty")
+		return false, errors.New("vector ID cannot be empty")
+	}
+	if token != "" && db.tokenSeen(token) { // skip the hooks of a retry
+		return false, nil
 	}
 	vec, dim, err := copyFloat32Slice(data)
 	if err != nil {
-		return err
+		return false, err
 	}
+	if vec, metadata, err = db.writeHook(false, id, vec, metadata); err != nil {
+		return false, err
+	}
+	dim = len(vec)
 	if dim == 0 {
-		return errors.New("vector data cannot be empty")
+		return false, errors.New("vector data cannot be empty")
 	}
-	if db.dimension > 0 && dim != db.dimension {
-		return fmt.Errorf("vector dimension %d does not match expected %d", dim, db.dimension)
+	if err := db.checkVector(vec); err != nil {
+		return false, err
+	}
+	if len(metadata) > 0 {
+		meta := metadata[0] // normalize a copy; the caller's slice is left alone
+		if meta.Attributes, err = normalizeAttributes(meta.Attributes); err != nil {
+			return false, err
+		}
+		if err := db.checkMetadata(meta); err != nil {
+			return false, err
+		}
+		metadata = []VectorMetadata{meta}
 	}
 	db.mu.Lock()
-	defer db.mu.Unlock()
+	defer db.unlock()
+	if token != "" && db.tokens.has(token) {
+		return false, nil
+	}
+	_, exists := db.vectors[id]
+	if create && exists {
+		return false, fmt.Errorf("vector with ID %s already exists", id)
+	}
+	if !exists {
+		if err := db.checkRoomLocked(1); err != nil {
+			return false, err
+		}
+	}
+	if err := db.checkDimensionLocked(dim); err != nil {
+		return false, err
+	}
 	now := time.Now().Unix()
 	vector := &Vector{ID: id, Data: vec, Dimension: dim}
 	if len(metadata) > 0 {
@@ -65,8 +273,9 @@ func (db *VectorDB) Add(id string, data any, metadata ...VectorMetadata) error {
 	} else {
 		vector.Metadata = VectorMetadata{CreatedAt: now, UpdatedAt: now}
 	}
-	db.vectors[id] = vector
-	return nil
+	db.storeLocked(db.vectors, vector)
+	db.tokens.add(token)
+	return true, nil
 }
 
 // Get retrieves a vector by ID
@@ -79,8 +288,9 @@ func (db *VectorDB) Get(id string) (*Vector, error) {
 		return nil, fmt.Errorf("vector with ID %s not found", id)
 	}
 
+	db.touch(vector)
 	dataCopy := make([]float32, vector.Dimension)
-	copy(dataCopy, vector.Data)
+	copy(dataCopy, db.floats(vector, nil))
 	return &Vector{
 		ID:        vector.ID,
 		Data:      dataCopy,
@@ -89,8 +299,20 @@ func (db *VectorDB) Get(id string) (*Vector, error) {
 	}, nil
 }
 
+// GetMetadata returns the metadata of a vector without copying its data.
+func (db *VectorDB) GetMetadata(id string) (VectorMetadata, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	vector, exists := db.vectors[id]
+	if !exists {
+		return VectorMetadata{}, fmt.Errorf("vector with ID %s not found", id)
+	}
+	return vector.Metadata, nil
+}
+
 // Update updates an existing vector. data must be []float32.
-func (db *VectorDB) Update(id string, data any, metadata ...VectorMetadata) error {
+func (db *VectorDB) Update(id string, data any, metadata ...VectorMetadata) (err error) {
+	defer db.logRejected("update", id, &err)
 	if id == "" {
 		return errors.New("vector ID cannot be empty")
 	}
@@ -98,37 +320,72 @@ func (db *VectorDB) Update(id string, data any, metadata ...VectorMetadata) erro
 	if err != nil {
 		return err
 	}
-	if db.dimension > 0 && dim != db.dimension {
-		return fmt.Errorf("vector dimension %d does not match expected %d", dim, db.dimension)
+	if vec, metadata, err = db.writeHook(true, id, vec, metadata); err != nil {
+		return err
+	}
+	dim = len(vec)
+	if err := db.checkVector(vec); err != nil {
+		return err
+	}
+	if len(metadata) > 0 {
+		meta := metadata[0] // normalize a copy; the caller's slice is left alone
+		if meta.Attributes, err = normalizeAttributes(meta.Attributes); err != nil {
+			return err
+		}
+		if err := db.checkMetadata(meta); err != nil {
+			return err
+		}
+		metadata = []VectorMetadata{meta}
 	}
 	db.mu.Lock()
-	defer db.mu.Unlock()
+	defer db.unlock()
 	vector, exists := db.vectors[id]
 	if !exists {
 		return fmt.Errorf("vector with ID %s not found", id)
 	}
-	vector.Data = vec
-	vector.Dimension = dim
-	now := time.Now().Unix()
+	if err := db.checkDimensionLocked(dim); err != nil {
+		return err
+	}
 	if len(metadata) > 0 {
-		vector.Metadata = metadata[0]
-		vector.Metadata.UpdatedAt = now
+		db.updateLocked(vector, vec, &metadata[0])
 	} else {
-		vector.Metadata.UpdatedAt = now
+		db.updateLocked(vector, vec, nil)
 	}
 	return nil
 }
 
+// updateLocked replaces vector's data, and its metadata when meta is not nil, keeping the old
+// version if the DB retains history. Callers must hold the write lock and have checked vec.
+func (db *VectorDB) updateLocked(vector *Vector, vec []float32, meta *VectorMetadata) {
+	db.recordVersionLocked(vector)
+	db.indexRemove(vector)
+	db.mem.used -= vectorMemory(vector)
+	vector.Data = vec
+	vector.Dimension = len(vec)
+	db.placeLocked(vector)
+	db.accountLocked(vector)
+	if meta != nil {
+		vector.Metadata = *meta
+	}
+	vector.Metadata.UpdatedAt = time.Now().Unix()
+	db.indexAdd(vector)
+	db.emitLocked(EventUpdate, vector)
+}
+
 // Delete removes a vector from the database
 func (db *VectorDB) Delete(id string) error {
+	if err := db.deleteHook(id); err != nil {
+		return err
+	}
 	db.mu.Lock()
-	defer db.mu.Unlock()
+	defer db.unlock()
 
-	if _, exists := db.vectors[id]; !exists {
+	vector, exists := db.vectors[id]
+	if !exists {
 		return fmt.Errorf("vector with ID %s not found", id)
 	}
 
-	delete(db.vectors, id)
+	db.removeLocked(vector)
 	return nil
 }
 
@@ -139,55 +396,187 @@ func (db *VectorDB) Size() int {
 	return len(db.vectors)
 }
 
+// IDs returns the IDs of all stored vectors in ascending order
+func (db *VectorDB) IDs() []string {
+	db.mu.RLock()
+	ids := make([]string, 0, len(db.vectors))
+	for id := range db.vectors {
+		ids = append(ids, id)
+	}
+	db.mu.RUnlock()
+	sort.Strings(ids)
+	return ids
+}
+
 // Clear removes all vectors from the database
 func (db *VectorDB) Clear() {
 	db.mu.Lock()
-	defer db.mu.Unlock()
+	defer db.unlock()
 	db.vectors = make(map[string]*Vector)
+	db.byOrd, db.freeOrds, db.trash, db.history = nil, nil, nil, nil
+	db.dataArena, db.packedArena = arena[float32]{}, arena[byte]{}
+	db.mem.used, db.mem.lastUsed = 0, nil
+	db.indexReset()
+	db.emitLocked(EventClear, nil)
 }
 
 // BatchAdd adds multiple vectors efficiently in a single operation.
 // New vectors are built outside the lock; the write lock is held only for the map merge,
 // so tail latencies for concurrent readers are not raised by long write lock duration.
 func (db *VectorDB) BatchAdd(vectors map[string]any, metadata map[string]VectorMetadata) error {
+	return db.BatchAddContext(context.Background(), vectors, metadata, nil)
+}
+
+// BatchAddContext is BatchAdd for large batches: progress, if not nil, is called every 1000
+// vectors validated and once they are all stored, and a cancelled ctx stops the batch before
+// anything is stored.
+func (db *VectorDB) BatchAddContext(ctx context.Context, vectors map[string]any, metadata map[string]VectorMetadata, progress ProgressFunc) (err error) {
+	defer db.logRejected("batch_add", "", &err)
 	if len(vectors) == 0 {
 		return errors.New("no vectors provided")
 	}
 
 	now := time.Now().Unix()
 	batchMap := make(map[string]*Vector, len(vectors))
-
 	for id, data := range vectors {
-		if id == "" {
-			return errors.New("vector ID cannot be empty")
+		if err := ctx.Err(); err != nil {
+			return err
 		}
-		vec, dim, err := copyFloat32Slice(data)
+		vector, err := db.batchVector(id, data, metadata, now)
 		if err != nil {
-			return fmt.Errorf("unsupported vector type for %s: %T (use []float32)", id, data)
-		}
-		if db.dimension > 0 && dim != db.dimension {
-			return fmt.Errorf("vector %s dimension %d does not match expected %d", id, dim, db.dimension)
+			return err
 		}
-		vector := &Vector{
-			ID:        id,
-			Data:      vec,
-			Dimension: dim,
-			Metadata:  VectorMetadata{CreatedAt: now, UpdatedAt: now},
+		batchMap[id] = vector
+		if len(batchMap) < len(vectors) {
+			reportProgress(progress, len(batchMap), len(vectors))
 		}
-		if meta, exists := metadata[id]; exists {
-			vector.Metadata = meta
-			vector.Metadata.CreatedAt = now
-			vector.Metadata.UpdatedAt = now
+	}
+	if err := db.mergeBatch(batchMap, nil); err != nil {
+		return err
+	}
+	reportProgress(progress, len(vectors), len(vectors))
+	return nil
+}
+
+// BatchAddPartial is BatchAdd for ingestion pipelines: it stores every valid vector in one
+// merge and returns the error of each one that was rejected, by ID (nil if all were stored).
+// When the DB dimension is still unknown it is taken from the most common dimension in the
+// batch (the smallest on a tie). err is set only when vectors is empty.
+func (db *VectorDB) BatchAddPartial(vectors map[string]any, metadata map[string]VectorMetadata) (failed map[string]error, err error) {
+	defer db.logRejected("batch_add_partial", "", &err)
+	if len(vectors) == 0 {
+		return nil, errors.New("no vectors provided")
+	}
+	failed = make(map[string]error)
+	now := time.Now().Unix()
+	batchMap := make(map[string]*Vector, len(vectors))
+	for id, data := range vectors {
+		vector, err := db.batchVector(id, data, metadata, now)
+		if err != nil {
+			failed[id] = err
+			continue
 		}
 		batchMap[id] = vector
 	}
+	if len(batchMap) > 0 {
+		_ = db.mergeBatch(batchMap, failed) // never fails with a failed map
+	}
+	if len(failed) == 0 {
+		return nil, nil
+	}
+	db.log(slog.LevelWarn, "write rejected", "op", "batch_add_partial", "failed", len(failed), "stored", len(vectors)-len(failed))
+	return failed, nil
+}
 
+// batchVector validates one BatchAdd entry and builds its vector.
+func (db *VectorDB) batchVector(id string, data any, metadata map[string]VectorMetadata, now int64) (*Vector, error) {
+	if id == "" {
+		return nil, errors.New("vector ID cannot be empty")
+	}
+	vec, _, err := copyFloat32Slice(data)
+	if err != nil {
+		return nil, fmt.Errorf("unsupported vector type for %s: %T (use []float32, []int8 or []uint8)", id, data)
+	}
+	var metas []VectorMetadata
+	if meta, exists := metadata[id]; exists {
+		metas = []VectorMetadata{meta}
+	}
+	if vec, metas, err = db.writeHook(false, id, vec, metas); err != nil {
+		return nil, fmt.Errorf("vector %s: %w", id, err)
+	}
+	if err := db.checkVector(vec); err != nil {
+		return nil, fmt.Errorf("vector %s: %w", id, err)
+	}
+	vector := &Vector{
+		ID:        id,
+		Data:      vec,
+		Dimension: len(vec),
+		Metadata:  VectorMetadata{CreatedAt: now, UpdatedAt: now},
+	}
+	if len(metas) > 0 {
+		meta := metas[0]
+		if meta.Attributes, err = normalizeAttributes(meta.Attributes); err != nil {
+			return nil, fmt.Errorf("vector %s: %w", id, err)
+		}
+		if err := db.checkMetadata(meta); err != nil {
+			return nil, fmt.Errorf("vector %s: %w", id, err)
+		}
+		vector.Metadata = meta
+		vector.Metadata.CreatedAt = now
+		vector.Metadata.UpdatedAt = now
+	}
+	return vector, nil
+}
+
+// mergeBatch checks the batch against the DB dimension (fixing it from the batch if still
+// unknown) and swaps in a copy of the vector map with batch merged on top. Nothing is stored
+// unless every vector fits, except with a non-nil failed map, which receives the vectors that
+// do not fit while the others are stored. Only the dimension check and map merge run under
+// the write lock. Options.MaxVectors is enforced the same way.
+func (db *VectorDB) mergeBatch(batch map[string]*Vector, failed map[string]error) error {
 	db.mu.Lock()
-	newMap := make(map[string]*Vector, len(db.vectors)+len(batchMap))
+	defer db.unlock()
+	dim := db.dimension
+	if dim == 0 && !db.flexible && failed != nil {
+		dim = commonDimension(batch)
+	}
+	for id, v := range batch {
+		if dim == 0 && !db.flexible {
+			dim = v.Dimension
+		}
+		if dim > 0 && v.Dimension != dim {
+			err := fmt.Errorf("vector %s dimension %d does not match expected %d", id, v.Dimension, dim)
+			if failed == nil {
+				return err
+			}
+			failed[id] = err
+			delete(batch, id)
+		}
+	}
+	if err := db.checkBatchRoomLocked(batch, failed); err != nil {
+		return err
+	}
+	db.dimension = dim
+	newMap := make(map[string]*Vector, len(db.vectors)+len(batch))
 	maps.Copy(newMap, db.vectors)
-	maps.Copy(newMap, batchMap)
+	for _, v := range batch {
+		db.storeLocked(newMap, v)
+	}
 	db.vectors = newMap
-	db.mu.Unlock()
-
 	return nil
 }
+
+// commonDimension returns the most frequent dimension in batch, the smallest on a tie.
+func commonDimension(batch map[string]*Vector) int {
+	counts := make(map[int]int)
+	for _, v := range batch {
+		counts[v.Dimension]++
+	}
+	best := 0
+	for d, n := range counts {
+		if n > counts[best] || n == counts[best] && d < best {
+			best = d
+		}
+	}
+	return best
+}
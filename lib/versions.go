@@ -0,0 +1,82 @@
+package lib
+
+import (
+	"fmt"
+	"slices"
+)
+
+// recordVersionLocked keeps a copy of vector as its newest previous version, dropping the
+// oldest beyond Options.KeepVersions. Callers must hold the write lock.
+func (db *VectorDB) recordVersionLocked(vector *Vector) {
+	if db.keepVersions == 0 {
+		return
+	}
+	old := &Vector{
+		ID:        vector.ID,
+		Data:      slices.Clone(db.floats(vector, nil)), // the arena slot is about to be reused
+		Metadata:  vector.Metadata,
+		Dimension: vector.Dimension,
+	}
+	if db.history == nil {
+		db.history = make(map[string][]*Vector)
+	}
+	versions := append([]*Vector{old}, db.history[vector.ID]...)
+	db.history[vector.ID] = versions[:min(len(versions), db.keepVersions)]
+}
+
+// GetVersion returns a copy of a vector n updates ago: 0 is the current version (as Get),
+// 1 the one before the last Update, up to Options.KeepVersions.
+func (db *VectorDB) GetVersion(id string, n int) (*Vector, error) {
+	if n == 0 {
+		return db.Get(id)
+	}
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	if _, exists := db.vectors[id]; !exists {
+		return nil, fmt.Errorf("vector with ID %s not found", id)
+	}
+	v, err := db.versionLocked(id, n)
+	if err != nil {
+		return nil, err
+	}
+	cp := *v
+	cp.Data = slices.Clone(v.Data)
+	return &cp, nil
+}
+
+// Versions returns how many previous versions of a vector are retained.
+func (db *VectorDB) Versions(id string) int {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	return len(db.history[id])
+}
+
+// RevertTo makes version n (as in GetVersion) current again, data and metadata. The revert is
+// itself an update, so the version it replaces becomes version 1.
+func (db *VectorDB) RevertTo(id string, n int) error {
+	db.mu.Lock()
+	defer db.unlock()
+	vector, exists := db.vectors[id]
+	if !exists {
+		return fmt.Errorf("vector with ID %s not found", id)
+	}
+	v, err := db.versionLocked(id, n)
+	if err != nil {
+		return err
+	}
+	if err := db.checkDimensionLocked(v.Dimension); err != nil {
+		return err
+	}
+	meta := v.Metadata
+	db.updateLocked(vector, slices.Clone(v.Data), &meta)
+	return nil
+}
+
+// versionLocked returns the stored version n >= 1 of id. Callers must hold db.mu.
+func (db *VectorDB) versionLocked(id string, n int) (*Vector, error) {
+	versions := db.history[id]
+	if n < 1 || n > len(versions) {
+		return nil, fmt.Errorf("vector %s has no version %d (%d retained)", id, n, len(versions))
+	}
+	return versions[n-1], nil
+}
@@ -0,0 +1,86 @@
+package lib
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/fs"
+	"math"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// pageSize is the stride at which Warmup reads vector memory.
+const pageSize = 4096
+
+// Warmup prepares the DB for its first request after a cold start, typically from a Lambda
+// init handler:
+//   - with a non-nil storage, it loads the stored snapshot (see SaveSnapshot); a storage
+//     without a snapshot yet is not an error;
+//   - with MahalanobisDistance and no covariance set, it fits one (FitCovariance);
+//   - it reads every page of vector data on all CPUs, so the first search does not pay for
+//     page faults.
+//
+// Tag and binary indexes are maintained as vectors are loaded and need no rebuild.
+func (db *VectorDB) Warmup(ctx context.Context, storage SnapshotStorage) error {
+	if storage != nil {
+		data, err := storage.ReadSnapshot(ctx)
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+		case err != nil:
+			return err
+		case len(data) > 0:
+			if err := db.ImportParquet(bytes.NewReader(data), int64(len(data)), nil); err != nil {
+				return err
+			}
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	db.mu.RLock()
+	fit := db.distFunc == MahalanobisDistance && db.covChol == nil && len(db.vectors) >= 2
+	db.mu.RUnlock()
+	if fit {
+		if err := db.FitCovariance(); err != nil {
+			return err
+		}
+	}
+
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	return pretouch(ctx, db.dataArena.chunks, db.packedArena.chunks)
+}
+
+// pretouch reads one element per page of every chunk, spreading the chunks over GOMAXPROCS
+// goroutines.
+func pretouch(ctx context.Context, data [][]float32, packed [][]byte) error {
+	n := len(data) + len(packed)
+	var next atomic.Int64
+	var sink atomic.Uint64
+	var wg sync.WaitGroup
+	for range min(runtime.GOMAXPROCS(0), n) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var sum uint64
+			for i := int(next.Add(1) - 1); i < n && ctx.Err() == nil; i = int(next.Add(1) - 1) {
+				if i < len(data) {
+					for j := 0; j < len(data[i]); j += pageSize / 4 {
+						sum += uint64(math.Float32bits(data[i][j]))
+					}
+				} else {
+					c := packed[i-len(data)]
+					for j := 0; j < len(c); j += pageSize {
+						sum += uint64(c[j])
+					}
+				}
+			}
+			sink.Add(sum)
+		}()
+	}
+	wg.Wait()
+	return ctx.Err()
+}
@@ -0,0 +1,77 @@
+package lib
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestWarmup(t *testing.T) {
+	src := NewVectorDB(3, MahalanobisDistance)
+	for i := range 2000 { // several arena chunks
+		_ = src.Add(fmt.Sprint(i), []float32{float32(i), float32(i % 7), float32(i % 3)}, VectorMetadata{Tags: map[string]string{"odd": fmt.Sprint(i%2 == 1)}})
+	}
+	storage := &memStorage{}
+	if err := src.SaveSnapshot(context.Background(), storage); err != nil {
+		t.Fatal(err)
+	}
+
+	db := NewVectorDB(3, MahalanobisDistance)
+	if err := db.CreateTagIndex("odd"); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Warmup(context.Background(), storage); err != nil {
+		t.Fatal(err)
+	}
+	if db.Size() != 2000 || db.covChol == nil {
+		t.Fatalf("Warmup must load the snapshot and fit the covariance, got %d vectors", db.Size())
+	}
+	res, err := db.SearchWithOptions([]float32{10, 3, 1}, 1, &SearchOptions{Tags: map[string]string{"odd": "false"}})
+	if err != nil || res.Results[0].ID != "10" {
+		t.Errorf("unexpected search after Warmup: %+v, %v", res, err)
+	}
+
+	if err := NewVectorDB(3).Warmup(context.Background(), &memStorage{}); err != nil {
+		t.Errorf("a storage without a snapshot must not fail Warmup: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := db.Warmup(ctx, nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestWarmup_KeepsMetadata(t *testing.T) {
+	src := NewVectorDB(2)
+	when := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	_ = src.Add("a", []float32{1, 0}, VectorMetadata{
+		Tags:       map[string]string{"lang": "en"},
+		Attributes: map[string]any{"year": 2020, "published": when},
+	})
+	_ = src.Add("b", []float32{0, 1})
+	src.vectors["a"].Metadata.CreatedAt = 1_000_000 // as if added long ago
+	src.vectors["a"].Metadata.UpdatedAt = 2_000_000
+	storage := &memStorage{}
+	if err := src.SaveSnapshot(context.Background(), storage); err != nil {
+		t.Fatal(err)
+	}
+
+	db := NewVectorDB(2)
+	if err := db.Warmup(context.Background(), storage); err != nil {
+		t.Fatal(err)
+	}
+	a, _ := db.GetMetadata("a")
+	if a.CreatedAt != 1_000_000 || a.UpdatedAt != 2_000_000 {
+		t.Errorf("timestamps must survive a snapshot, got %d and %d", a.CreatedAt, a.UpdatedAt)
+	}
+	if a.Attributes["year"] != 2020.0 || a.Attributes["published"] != when || a.Tags["lang"] != "en" {
+		t.Errorf("attributes must survive a snapshot, got %+v", a)
+	}
+	b, _ := db.GetMetadata("b")
+	want, _ := src.GetMetadata("b")
+	if b.CreatedAt != want.CreatedAt || b.Attributes != nil {
+		t.Errorf("unexpected metadata for b: %+v", b)
+	}
+}
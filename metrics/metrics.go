@@ -0,0 +1,166 @@
+// Package metrics exposes a VectorDB's activity in the Prometheus text exposition format:
+// write counters, search latency and candidate histograms, vector count and memory. It uses
+// the standard library only; a Collector is an http.Handler that Prometheus can scrape
+// directly, so no client_golang registry is needed.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	serverlessVector "github.com/takara-ai/serverlessVector/v2"
+)
+
+// DefaultLatencyBuckets are the search duration histogram bounds, in seconds.
+var DefaultLatencyBuckets = []float64{0.0001, 0.00025, 0.0005, 0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1}
+
+// DefaultCandidateBuckets are the bounds of the histogram of vectors scanned per search.
+var DefaultCandidateBuckets = []float64{10, 100, 1000, 10000, 100000, 1000000}
+
+// Options configures a Collector. Nil or zero values use defaults.
+type Options struct {
+	Namespace        string    // Metric name prefix. Default "serverless_vector".
+	LatencyBuckets   []float64 // Default DefaultLatencyBuckets.
+	CandidateBuckets []float64 // Default DefaultCandidateBuckets.
+}
+
+// Collector counts a VectorDB's changes through Subscribe and its searches through
+// ObserveSearch. It is safe for concurrent use.
+type Collector struct {
+	db     *serverlessVector.VectorDB
+	ns     string
+	cancel func()
+
+	mu           sync.Mutex
+	writes       map[serverlessVector.EventOp]uint64
+	searches     uint64
+	searchErrors uint64
+	latency      histogram
+	candidates   histogram
+}
+
+// New starts counting db's changes. Searches are counted once ObserveSearch is installed as
+// the db's Hooks.OnSearchDone (or called from your own hook).
+func New(db *serverlessVector.VectorDB, opts *Options) *Collector {
+	if opts == nil {
+		opts = &Options{}
+	}
+	c := &Collector{
+		db:         db,
+		ns:         opts.Namespace,
+		writes:     make(map[serverlessVector.EventOp]uint64),
+		latency:    newHistogram(opts.LatencyBuckets, DefaultLatencyBuckets),
+		candidates: newHistogram(opts.CandidateBuckets, DefaultCandidateBuckets),
+	}
+	if c.ns == "" {
+		c.ns = "serverless_vector"
+	}
+	c.cancel = db.Subscribe(func(e serverlessVector.Event) {
+		c.mu.Lock()
+		c.writes[e.Op]++
+		c.mu.Unlock()
+	})
+	return c
+}
+
+// ObserveSearch records one search. Its signature matches Hooks.OnSearchDone. Candidate
+// counts are taken from res.Stats, which searches fill in with SearchOptions.Explain.
+func (c *Collector) ObserveSearch(res *serverlessVector.SearchResult, err error, took time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.searches++
+	if err != nil {
+		c.searchErrors++
+	}
+	c.latency.observe(took.Seconds())
+	if res != nil && res.Stats != nil {
+		c.candidates.observe(float64(res.Stats.Candidates))
+	}
+}
+
+// Close stops counting changes.
+func (c *Collector) Close() {
+	c.cancel()
+}
+
+// ServeHTTP writes the metrics in the Prometheus text format.
+func (c *Collector) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_ = c.Write(w)
+}
+
+// Write writes the metrics in the Prometheus text format.
+func (c *Collector) Write(w io.Writer) error {
+	stats := c.db.GetStats() // before c.mu; it takes the DB lock
+	bw := bufio.NewWriter(w)
+	c.mu.Lock()
+	for _, m := range []struct {
+		name, help string
+		op         serverlessVector.EventOp
+	}{
+		{"adds_total", "Vectors added.", serverlessVector.EventAdd},
+		{"updates_total", "Vectors replaced or updated.", serverlessVector.EventUpdate},
+		{"deletes_total", "Vectors deleted.", serverlessVector.EventDelete},
+		{"clears_total", "Calls to Clear.", serverlessVector.EventClear},
+	} {
+		c.metric(bw, m.name, "counter", m.help, float64(c.writes[m.op]))
+	}
+	c.metric(bw, "searches_total", "counter", "Searches run.", float64(c.searches))
+	c.metric(bw, "search_errors_total", "counter", "Searches that returned an error.", float64(c.searchErrors))
+	c.latency.write(bw, c.ns+"_search_duration_seconds", "Search latency.")
+	c.candidates.write(bw, c.ns+"_search_candidates", "Vectors considered per explained search.")
+	c.mu.Unlock()
+	c.metric(bw, "vectors", "gauge", "Vectors stored.", float64(stats["total_vectors"].(int)))
+	c.metric(bw, "memory_bytes", "gauge", "Estimated memory used by vectors.", float64(stats["memory_usage_kb"].(int64)*1024))
+	return bw.Flush()
+}
+
+func (c *Collector) metric(w *bufio.Writer, name, kind, help string, v float64) {
+	name = c.ns + "_" + name
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n%s %s\n", name, help, name, kind, name, formatFloat(v))
+}
+
+// histogram is a Prometheus histogram: counts per upper bound, plus sum and count.
+type histogram struct {
+	bounds []float64
+	counts []uint64 // counts[i] observations <= bounds[i] and > bounds[i-1]
+	sum    float64
+	count  uint64
+}
+
+func newHistogram(bounds, defaults []float64) histogram {
+	if len(bounds) == 0 {
+		bounds = defaults
+	}
+	return histogram{bounds: bounds, counts: make([]uint64, len(bounds))}
+}
+
+func (h *histogram) observe(v float64) {
+	for i, b := range h.bounds {
+		if v <= b {
+			h.counts[i]++
+			break
+		}
+	}
+	h.sum += v
+	h.count++
+}
+
+func (h *histogram) write(w *bufio.Writer, name, help string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s histogram\n", name, help, name)
+	var cum uint64
+	for i, b := range h.bounds {
+		cum += h.counts[i]
+		fmt.Fprintf(w, "%s_bucket{le=%q} %d\n", name, formatFloat(b), cum)
+	}
+	fmt.Fprintf(w, "%s_bucket{le=\"+Inf\"} %d\n%s_sum %s\n%s_count %d\n", name, h.count, name, formatFloat(h.sum), name, h.count)
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
@@ -0,0 +1,56 @@
+package metrics
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	serverlessVector "github.com/takara-ai/serverlessVector/v2"
+)
+
+func TestCollector(t *testing.T) {
+	db := serverlessVector.NewVectorDB(2)
+	c := New(db, &Options{Namespace: "vec", LatencyBuckets: []float64{0.001, 0.01}})
+	db.SetHooks(&serverlessVector.Hooks{OnSearchDone: c.ObserveSearch})
+
+	_ = db.Add("a", []float32{1, 0})
+	_ = db.Add("b", []float32{0, 1})
+	_ = db.Add("a", []float32{1, 1})
+	_ = db.Delete("b")
+	_, _ = db.SearchWithOptions([]float32{1, 0}, 1, &serverlessVector.SearchOptions{Explain: true})
+	_, _ = db.Search([]float32{1, 0, 0}, 1)
+	c.ObserveSearch(nil, nil, 5*time.Millisecond)
+
+	rec := httptest.NewRecorder()
+	c.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body := rec.Body.String()
+	for _, want := range []string{
+		"# TYPE vec_adds_total counter\nvec_adds_total 2\n",
+		"vec_updates_total 1\n",
+		"vec_deletes_total 1\n",
+		"vec_searches_total 3\n",
+		"vec_search_errors_total 1\n",
+		`vec_search_duration_seconds_bucket{le="0.01"} 3` + "\n",
+		`vec_search_duration_seconds_bucket{le="+Inf"} 3` + "\n",
+		"vec_search_duration_seconds_count 3\n",
+		`vec_search_candidates_bucket{le="10"} 1` + "\n",
+		"vec_search_candidates_count 1\n",
+		"# TYPE vec_vectors gauge\nvec_vectors 1\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("missing %q in:\n%s", want, body)
+		}
+	}
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("unexpected content type %q", ct)
+	}
+
+	c.Close()
+	_ = db.Add("c", []float32{1, 0})
+	var sb strings.Builder
+	_ = c.Write(&sb)
+	if !strings.Contains(sb.String(), "vec_adds_total 2\n") {
+		t.Error("Close must stop counting changes")
+	}
+}
@@ -0,0 +1,286 @@
+// Package pgsync keeps a VectorDB in sync with a Postgres table that has a pgvector column,
+// so the in-memory DB can serve as a fast cache over a durable SQL source of truth.
+// It uses database/sql only; register a Postgres driver (pgx, lib/pq) in your program.
+package pgsync
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	serverlessVector "github.com/takara-ai/serverlessVector/v2"
+)
+
+// Config describes the source table. Table is required; other fields have defaults.
+type Config struct {
+	Table         string        // Table name, optionally schema-qualified ("public.items").
+	IDColumn      string        // Primary key column. Default "id".
+	VectorColumn  string        // pgvector column. Default "embedding".
+	TagColumns    []string      // Columns loaded into Metadata.Tags (cast to text).
+	UpdatedColumn string        // Optional timestamp column; enables incremental Sync.
+	Overlap       time.Duration // How far behind the watermark Sync re-reads. Default 5s.
+	OnError       func(error)   // Optional; receives Sync errors from Run.
+}
+
+// defaultOverlap is the default Config.Overlap.
+const defaultOverlap = 5 * time.Second
+
+// Syncer loads and refreshes a VectorDB from a Postgres table.
+type Syncer struct {
+	sqlDB *sql.DB
+	vdb   *serverlessVector.VectorDB
+	cfg   Config
+
+	mu        sync.Mutex
+	watermark time.Time // latest UpdatedColumn value seen
+}
+
+// New returns a Syncer for cfg. It does not touch the database until Load or Sync.
+func New(sqlDB *sql.DB, vdb *serverlessVector.VectorDB, cfg Config) (*Syncer, error) {
+	if sqlDB == nil || vdb == nil {
+		return nil, errors.New("pgsync: sql.DB and VectorDB are required")
+	}
+	if cfg.Table == "" {
+		return nil, errors.New("pgsync: table name is required")
+	}
+	if cfg.IDColumn == "" {
+		cfg.IDColumn = "id"
+	}
+	if cfg.VectorColumn == "" {
+		cfg.VectorColumn = "embedding"
+	}
+	if cfg.Overlap <= 0 {
+		cfg.Overlap = defaultOverlap
+	}
+	return &Syncer{sqlDB: sqlDB, vdb: vdb, cfg: cfg}, nil
+}
+
+// Load reads the whole table into the VectorDB and removes vectors whose rows no longer exist.
+// It returns the number of rows loaded.
+func (s *Syncer) Load(ctx context.Context) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	seen, err := s.fetch(ctx, false)
+	if err != nil {
+		return 0, err
+	}
+	for _, id := range s.vdb.IDs() {
+		if _, ok := seen[id]; !ok {
+			_ = s.vdb.Delete(id)
+		}
+	}
+	return len(seen), nil
+}
+
+// Sync applies rows changed since the last Load or Sync. Without an UpdatedColumn it
+// falls back to a full Load. Deleted rows are only noticed by Load (or by Delete).
+//
+// Sync reads rows whose UpdatedColumn is at or after the latest value it has seen minus
+// Config.Overlap, so rows that share the latest timestamp, or commit late with a slightly
+// older one (Postgres now() is the transaction start), are not skipped. Re-reading a row
+// is harmless since it is upserted. A row committed more than Overlap behind the
+// watermark is only picked up by the next Load, so set Overlap above your longest
+// writing transaction. The count includes re-read rows.
+func (s *Syncer) Sync(ctx context.Context) (int, error) {
+	if s.cfg.UpdatedColumn == "" {
+		return s.Load(ctx)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	seen, err := s.fetch(ctx, true)
+	return len(seen), err
+}
+
+// Run calls Sync every interval until ctx is done. Sync errors go to Config.OnError
+// and do not stop the loop.
+func (s *Syncer) Run(ctx context.Context, interval time.Duration) error {
+	if interval <= 0 {
+		return errors.New("pgsync: interval must be positive")
+	}
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-t.C:
+			if _, err := s.Sync(ctx); err != nil && s.cfg.OnError != nil {
+				s.cfg.OnError(err)
+			}
+		}
+	}
+}
+
+// Upsert writes the vector to Postgres, then to the VectorDB (write-through).
+// The table needs a unique constraint on the ID column.
+func (s *Syncer) Upsert(ctx context.Context, id string, data []float32, tags map[string]string) error {
+	cols := []string{quoteIdent(s.cfg.IDColumn), quoteIdent(s.cfg.VectorColumn)}
+	vals := []string{"$1", "$2::vector"}
+	args := []any{id, FormatVector(data)}
+	for _, c := range s.cfg.TagColumns {
+		args = append(args, nullString(tags, c))
+		cols = append(cols, quoteIdent(c))
+		vals = append(vals, "$"+strconv.Itoa(len(args)))
+	}
+	sets := make([]string, 0, len(cols))
+	for _, c := range cols[1:] {
+		sets = append(sets, c+" = EXCLUDED."+c)
+	}
+	if s.cfg.UpdatedColumn != "" {
+		sets = append(sets, quoteIdent(s.cfg.UpdatedColumn)+" = now()")
+	}
+	q := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s) ON CONFLICT (%s) DO UPDATE SET %s",
+		quoteIdent(s.cfg.Table), strings.Join(cols, ", "), strings.Join(vals, ", "),
+		cols[0], strings.Join(sets, ", "))
+	if _, err := s.sqlDB.ExecContext(ctx, q, args...); err != nil {
+		return fmt.Errorf("pgsync: upsert %s: %w", id, err)
+	}
+	return s.vdb.Add(id, data, serverlessVector.VectorMetadata{Tags: tags})
+}
+
+// Delete removes the row from Postgres, then the vector from the VectorDB.
+func (s *Syncer) Delete(ctx context.Context, id string) error {
+	q := fmt.Sprintf("DELETE FROM %s WHERE %s = $1", quoteIdent(s.cfg.Table), quoteIdent(s.cfg.IDColumn))
+	if _, err := s.sqlDB.ExecContext(ctx, q, id); err != nil {
+		return fmt.Errorf("pgsync: delete %s: %w", id, err)
+	}
+	_ = s.vdb.Delete(id) // not cached yet is fine
+	return nil
+}
+
+// fetch reads rows (all, or changed since the watermark) and stores them in one batch.
+// Callers hold s.mu.
+func (s *Syncer) fetch(ctx context.Context, incremental bool) (map[string]struct{}, error) {
+	cols := []string{quoteIdent(s.cfg.IDColumn) + "::text", quoteIdent(s.cfg.VectorColumn) + "::text"}
+	for _, c := range s.cfg.TagColumns {
+		cols = append(cols, quoteIdent(c)+"::text")
+	}
+	if s.cfg.UpdatedColumn != "" {
+		cols = append(cols, quoteIdent(s.cfg.UpdatedColumn))
+	}
+	q := fmt.Sprintf("SELECT %s FROM %s", strings.Join(cols, ", "), quoteIdent(s.cfg.Table))
+	var args []any
+	if incremental {
+		since := s.watermark
+		if !since.IsZero() {
+			since = since.Add(-s.cfg.Overlap)
+		}
+		q += fmt.Sprintf(" WHERE %s >= $1", quoteIdent(s.cfg.UpdatedColumn))
+		args = append(args, since)
+	}
+	rows, err := s.sqlDB.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("pgsync: query: %w", err)
+	}
+	defer rows.Close()
+
+	vectors := make(map[string]any)
+	metadata := make(map[string]serverlessVector.VectorMetadata)
+	watermark := s.watermark
+	dest := make([]any, len(cols))
+	var id, vec string
+	tagVals := make([]sql.NullString, len(s.cfg.TagColumns))
+	var updated sql.NullTime
+	dest[0], dest[1] = &id, &vec
+	for i := range tagVals {
+		dest[2+i] = &tagVals[i]
+	}
+	if s.cfg.UpdatedColumn != "" {
+		dest[len(dest)-1] = &updated
+	}
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("pgsync: scan: %w", err)
+		}
+		data, err := ParseVector(vec)
+		if err != nil {
+			return nil, fmt.Errorf("pgsync: row %s: %w", id, err)
+		}
+		vectors[id] = data
+		var tags map[string]string
+		for i, c := range s.cfg.TagColumns {
+			if tagVals[i].Valid {
+				if tags == nil {
+					tags = make(map[string]string, len(s.cfg.TagColumns))
+				}
+				tags[c] = tagVals[i].String
+			}
+		}
+		if tags != nil {
+			metadata[id] = serverlessVector.VectorMetadata{Tags: tags}
+		}
+		if updated.Valid && updated.Time.After(watermark) {
+			watermark = updated.Time
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("pgsync: rows: %w", err)
+	}
+
+	seen := make(map[string]struct{}, len(vectors))
+	for id := range vectors {
+		seen[id] = struct{}{}
+	}
+	if len(vectors) > 0 {
+		if err := s.vdb.BatchAdd(vectors, metadata); err != nil {
+			return nil, fmt.Errorf("pgsync: %w", err)
+		}
+	}
+	s.watermark = watermark
+	return seen, nil
+}
+
+// ParseVector parses pgvector's text form, e.g. "[1,2.5,-3]".
+func ParseVector(s string) ([]float32, error) {
+	s = strings.TrimSpace(s)
+	if len(s) < 2 || s[0] != '[' || s[len(s)-1] != ']' {
+		return nil, fmt.Errorf("invalid pgvector literal %q", s)
+	}
+	body := strings.TrimSpace(s[1 : len(s)-1])
+	if body == "" {
+		return []float32{}, nil
+	}
+	parts := strings.Split(body, ",")
+	out := make([]float32, len(parts))
+	for i, p := range parts {
+		f, err := strconv.ParseFloat(strings.TrimSpace(p), 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pgvector element %q", p)
+		}
+		out[i] = float32(f)
+	}
+	return out, nil
+}
+
+// FormatVector renders data in pgvector's text form for use with a ::vector cast.
+func FormatVector(data []float32) string {
+	var b strings.Builder
+	b.WriteByte('[')
+	for i, x := range data {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(strconv.FormatFloat(float64(x), 'g', -1, 32))
+	}
+	b.WriteByte(']')
+	return b.String()
+}
+
+// quoteIdent quotes a possibly schema-qualified identifier.
+func quoteIdent(name string) string {
+	parts := strings.Split(name, ".")
+	for i, p := range parts {
+		parts[i] = `"` + strings.ReplaceAll(p, `"`, `""`) + `"`
+	}
+	return strings.Join(parts, ".")
+}
+
+func nullString(tags map[string]string, key string) sql.NullString {
+	v, ok := tags[key]
+	return sql.NullString{String: v, Valid: ok}
+}
@@ -0,0 +1,233 @@
+package pgsync
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	serverlessVector "github.com/takara-ai/serverlessVector/v2"
+)
+
+// fakeRow is one row of the fake table: id, vector text, category tag, updated time.
+type fakeRow struct {
+	id, vec, category string
+	updated           time.Time
+}
+
+// fakeTable is shared by every connection of the fake driver.
+type fakeTable struct {
+	mu    sync.Mutex
+	rows  map[string]fakeRow
+	execs []string
+}
+
+var table = &fakeTable{rows: map[string]fakeRow{}}
+
+func init() { sql.Register("pgsyncfake", fakeDriver{}) }
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(string) (driver.Conn, error) { return fakeConn{}, nil }
+
+type fakeConn struct{}
+
+func (fakeConn) Prepare(string) (driver.Stmt, error) { return nil, driver.ErrSkip }
+func (fakeConn) Close() error                        { return nil }
+func (fakeConn) Begin() (driver.Tx, error)           { return nil, driver.ErrSkip }
+
+func (fakeConn) QueryContext(_ context.Context, q string, args []driver.NamedValue) (driver.Rows, error) {
+	table.mu.Lock()
+	defer table.mu.Unlock()
+	var since time.Time
+	if len(args) > 0 {
+		since = args[0].Value.(time.Time)
+	}
+	r := &fakeRows{}
+	for _, row := range table.rows {
+		if !row.updated.Before(since) {
+			var cat any
+			if row.category != "" {
+				cat = row.category
+			}
+			r.data = append(r.data, []driver.Value{row.id, row.vec, cat, row.updated})
+		}
+	}
+	return r, nil
+}
+
+func (fakeConn) ExecContext(_ context.Context, q string, args []driver.NamedValue) (driver.Result, error) {
+	table.mu.Lock()
+	defer table.mu.Unlock()
+	table.execs = append(table.execs, q)
+	id := args[0].Value.(string)
+	if strings.HasPrefix(q, "DELETE") {
+		delete(table.rows, id)
+		return driver.RowsAffected(1), nil
+	}
+	cat, _ := args[2].Value.(string)
+	table.rows[id] = fakeRow{id: id, vec: args[1].Value.(string), category: cat, updated: time.Now()}
+	return driver.RowsAffected(1), nil
+}
+
+type fakeRows struct {
+	data [][]driver.Value
+	pos  int
+}
+
+func (r *fakeRows) Columns() []string { return []string{"id", "embedding", "category", "updated_at"} }
+func (r *fakeRows) Close() error      { return nil }
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.pos >= len(r.data) {
+		return io.EOF
+	}
+	copy(dest, r.data[r.pos])
+	r.pos++
+	return nil
+}
+
+func newSyncer(t *testing.T) (*Syncer, *serverlessVector.VectorDB) {
+	t.Helper()
+	table.mu.Lock()
+	table.rows = map[string]fakeRow{
+		"a": {id: "a", vec: "[1,0,0]", category: "x", updated: time.Unix(100, 0)},
+		"b": {id: "b", vec: "[0, 1, 0]", updated: time.Unix(200, 0)},
+	}
+	table.execs = nil
+	table.mu.Unlock()
+	sqlDB, err := sql.Open("pgsyncfake", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+	vdb := serverlessVector.NewVectorDB(3)
+	s, err := New(sqlDB, vdb, Config{Table: "public.items", TagColumns: []string{"category"}, UpdatedColumn: "updated_at"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s, vdb
+}
+
+func TestLoad(t *testing.T) {
+	s, vdb := newSyncer(t)
+	_ = vdb.Add("stale", []float32{1, 1, 1})
+	n, err := s.Load(context.Background())
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if n != 2 || vdb.Size() != 2 {
+		t.Fatalf("expected 2 rows loaded and cached, got n=%d size=%d", n, vdb.Size())
+	}
+	a, _ := vdb.Get("a")
+	if a.Data[0] != 1 || a.Metadata.Tags["category"] != "x" {
+		t.Errorf("unexpected vector a: %+v", a)
+	}
+	if _, err := vdb.Get("stale"); err == nil {
+		t.Error("Load must remove vectors whose rows are gone")
+	}
+}
+
+func TestSync_Incremental(t *testing.T) {
+	s, vdb := newSyncer(t)
+	ctx := context.Background()
+	if _, err := s.Load(ctx); err != nil {
+		t.Fatal(err)
+	}
+	table.mu.Lock()
+	table.rows["c"] = fakeRow{id: "c", vec: "[0,0,1]", updated: time.Unix(300, 0)}
+	table.mu.Unlock()
+	n, err := s.Sync(ctx)
+	if err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	if n != 2 { // c, and b re-read within Overlap of the watermark
+		t.Errorf("expected the changed row and b, got %d", n)
+	}
+	if vdb.Size() != 3 {
+		t.Errorf("expected 3 vectors after sync, got %d", vdb.Size())
+	}
+}
+
+func TestSync_Overlap(t *testing.T) {
+	s, vdb := newSyncer(t)
+	ctx := context.Background()
+	if _, err := s.Load(ctx); err != nil { // watermark is b's 200s
+		t.Fatal(err)
+	}
+	table.mu.Lock()
+	table.rows["same"] = fakeRow{id: "same", vec: "[1,1,0]", updated: time.Unix(200, 0)}
+	table.rows["late"] = fakeRow{id: "late", vec: "[0,1,1]", updated: time.Unix(198, 0)}
+	table.rows["old"] = fakeRow{id: "old", vec: "[1,0,1]", updated: time.Unix(150, 0)}
+	table.mu.Unlock()
+	if _, err := s.Sync(ctx); err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	for _, id := range []string{"same", "late"} {
+		if _, err := vdb.Get(id); err != nil {
+			t.Errorf("Sync must pick up %s, committed at or within Overlap of the watermark", id)
+		}
+	}
+	if _, err := vdb.Get("old"); err == nil {
+		t.Error("Sync must not re-read rows further back than Overlap")
+	}
+}
+
+func TestUpsertAndDelete_WriteThrough(t *testing.T) {
+	s, vdb := newSyncer(t)
+	ctx := context.Background()
+	if err := s.Upsert(ctx, "d", []float32{0.5, 0, 0}, map[string]string{"category": "y"}); err != nil {
+		t.Fatalf("Upsert failed: %v", err)
+	}
+	table.mu.Lock()
+	row := table.rows["d"]
+	q := table.execs[len(table.execs)-1]
+	table.mu.Unlock()
+	if row.vec != "[0.5,0,0]" || row.category != "y" {
+		t.Errorf("unexpected stored row: %+v", row)
+	}
+	if !strings.Contains(q, `INSERT INTO "public"."items"`) || !strings.Contains(q, `ON CONFLICT ("id")`) {
+		t.Errorf("unexpected upsert SQL: %s", q)
+	}
+	if _, err := vdb.Get("d"); err != nil {
+		t.Errorf("Upsert must also write to the VectorDB: %v", err)
+	}
+
+	if err := s.Delete(ctx, "d"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, err := vdb.Get("d"); err == nil {
+		t.Error("Delete must remove the cached vector")
+	}
+}
+
+func TestRun_StopsOnCancel(t *testing.T) {
+	s, vdb := newSyncer(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+	if err := s.Run(ctx, 5*time.Millisecond); err == nil {
+		t.Error("Run must return the context error")
+	}
+	if vdb.Size() != 2 {
+		t.Errorf("Run must have synced the table, size=%d", vdb.Size())
+	}
+}
+
+func TestParseFormatVector(t *testing.T) {
+	v, err := ParseVector(" [1, -2.5,3e-1] ")
+	if err != nil || len(v) != 3 || v[1] != -2.5 {
+		t.Fatalf("unexpected parse result %v %v", v, err)
+	}
+	if FormatVector(v) != "[1,-2.5,0.3]" {
+		t.Errorf("unexpected format %s", FormatVector(v))
+	}
+	if _, err := ParseVector("1,2"); err == nil {
+		t.Error("expected missing brackets to fail")
+	}
+	if _, err := ParseVector("[1,x]"); err == nil {
+		t.Error("expected bad element to fail")
+	}
+}
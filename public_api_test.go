@@ -3,8 +3,15 @@
 package serverlessVector
 
 import (
+	"encoding/base64"
+	"errors"
+	"fmt"
 	"math"
+	"runtime"
+	"slices"
+	"sync"
 	"testing"
+	"time"
 )
 
 // --- NewVectorDB API ---
@@ -87,6 +94,39 @@ func TestAPI_Add_RejectsFloat64(t *testing.T) {
 	}
 }
 
+func TestAPI_NewVectorDB_InfersDimension(t *testing.T) {
+	db := NewVectorDB(0)
+	if db.Dimension() != 0 {
+		t.Fatalf("Dimension before any Add = %d, want 0", db.Dimension())
+	}
+	if err := db.Add("a", []float32{1, 2, 3}); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if db.Dimension() != 3 {
+		t.Errorf("Dimension after first Add = %d, want 3", db.Dimension())
+	}
+	if err := db.Add("b", []float32{1, 2}); err == nil {
+		t.Error("Add with a different dimension must return error")
+	}
+	if err := db.Update("a", []float32{1, 2}); err == nil {
+		t.Error("Update with a different dimension must return error")
+	}
+
+	batch := NewVectorDB(0)
+	if err := batch.BatchAdd(map[string]any{"a": []float32{1, 2}, "b": []float32{1, 2, 3}}, nil); err == nil {
+		t.Error("BatchAdd with mixed dimensions must return error")
+	}
+	if batch.Size() != 0 || batch.Dimension() != 0 {
+		t.Errorf("failed BatchAdd must store nothing, got size %d dimension %d", batch.Size(), batch.Dimension())
+	}
+
+	flex := NewVectorDBWithOptions(&Options{FlexibleDimensions: true})
+	_ = flex.Add("a", []float32{1, 2, 3})
+	if err := flex.Add("b", []float32{1, 2}); err != nil || flex.Dimension() != 0 {
+		t.Errorf("FlexibleDimensions must accept any length: %v, dimension %d", err, flex.Dimension())
+	}
+}
+
 func TestAPI_Add_EmptyData(t *testing.T) {
 	db := NewVectorDB(0)
 	err := db.Add("id", []float32{})
@@ -205,6 +245,52 @@ func TestAPI_Update_Success(t *testing.T) {
 	}
 }
 
+func TestAPI_Update_KeepVersions(t *testing.T) {
+	db := NewVectorDBWithOptions(&Options{Dimension: 2, KeepVersions: 2})
+	_ = db.Add("a", []float32{1, 0}, VectorMetadata{Tags: map[string]string{"model": "v1"}})
+	_ = db.Update("a", []float32{2, 0}, VectorMetadata{Tags: map[string]string{"model": "v2"}})
+	_ = db.Update("a", []float32{3, 0}, VectorMetadata{Tags: map[string]string{"model": "v3"}})
+	_ = db.Update("a", []float32{4, 0})
+	if n := db.Versions("a"); n != 2 {
+		t.Fatalf("expected 2 retained versions, got %d", n)
+	}
+	for n, want := range []float32{4, 3, 2} {
+		v, err := db.GetVersion("a", n)
+		if err != nil || v.Data[0] != want {
+			t.Errorf("version %d: got %v, %v; want first component %v", n, v, err, want)
+		}
+	}
+	if _, err := db.GetVersion("a", 3); err == nil {
+		t.Error("versions beyond KeepVersions must be dropped")
+	}
+
+	if err := db.RevertTo("a", 2); err != nil {
+		t.Fatalf("RevertTo failed: %v", err)
+	}
+	v, _ := db.Get("a")
+	if v.Data[0] != 2 || v.Metadata.Tags["model"] != "v2" {
+		t.Errorf("expected data and metadata of version 2, got %v %v", v.Data, v.Metadata.Tags)
+	}
+	if prev, _ := db.GetVersion("a", 1); prev.Data[0] != 4 {
+		t.Errorf("the reverted-from version must become version 1, got %v", prev.Data)
+	}
+	if res, _ := db.SearchWithOptions([]float32{1, 0}, 1, &SearchOptions{Tags: map[string]string{"model": "v2"}}); len(res.Results) != 1 {
+		t.Errorf("the revert must update filters, got %+v", res.Results)
+	}
+
+	_ = db.Delete("a")
+	_ = db.Add("a", []float32{1, 1})
+	if n := db.Versions("a"); n != 0 {
+		t.Errorf("Delete must drop the history, got %d versions", n)
+	}
+	plain := NewVectorDB(2)
+	_ = plain.Add("x", []float32{1, 0})
+	_ = plain.Update("x", []float32{0, 1})
+	if plain.Versions("x") != 0 {
+		t.Error("history is off by default")
+	}
+}
+
 func TestAPI_Update_RejectsWrongDimension(t *testing.T) {
 	db := NewVectorDB(3)
 	_ = db.Add("a", []float32{1, 2, 3})
@@ -242,6 +328,214 @@ func TestAPI_Delete_Success(t *testing.T) {
 
 // --- Size / Clear API ---
 
+func TestAPI_SoftDelete(t *testing.T) {
+	db := NewVectorDB(2)
+	_ = db.Add("a", []float32{1, 0}, VectorMetadata{Tags: map[string]string{"k": "x"}})
+	_ = db.Add("b", []float32{0, 1}, VectorMetadata{Tags: map[string]string{"k": "x"}})
+	_ = db.CreateTagIndex("k")
+	if err := db.SoftDelete("a"); err != nil {
+		t.Fatalf("SoftDelete failed: %v", err)
+	}
+	if db.Size() != 1 || db.SoftDeleted() != 1 {
+		t.Fatalf("expected 1 live and 1 soft-deleted, got %d and %d", db.Size(), db.SoftDeleted())
+	}
+	if _, err := db.Get("a"); err == nil {
+		t.Error("Get must not see a soft-deleted vector")
+	}
+	for _, opts := range []*SearchOptions{nil, {Tags: map[string]string{"k": "x"}}} {
+		res, _ := db.SearchWithOptions([]float32{1, 0}, 5, opts)
+		if len(res.Results) != 1 || res.Results[0].ID != "b" {
+			t.Errorf("search must skip the soft-deleted vector, got %+v", res.Results)
+		}
+	}
+
+	if err := db.Restore("a"); err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+	if res, _ := db.Search([]float32{1, 0}, 1); res.Results[0].ID != "a" {
+		t.Errorf("restored vector must be searchable, got %+v", res.Results)
+	}
+	if err := db.Restore("a"); err == nil {
+		t.Error("Restore of a live vector must fail")
+	}
+
+	_ = db.SoftDelete("a")
+	_ = db.Add("a", []float32{0, 1})
+	if err := db.Restore("a"); err == nil {
+		t.Error("Restore must fail once the ID is reused")
+	}
+	if n := db.Vacuum(); n != 1 || db.SoftDeleted() != 0 {
+		t.Errorf("Vacuum must remove 1 vector, got %d", n)
+	}
+	res, _ := db.SearchWithOptions([]float32{1, 0}, 5, &SearchOptions{Tags: map[string]string{"k": "x"}})
+	if len(res.Results) != 1 || res.Results[0].ID != "b" {
+		t.Errorf("the vacuumed vector must leave the tag index, got %+v", res.Results)
+	}
+	if err := db.Restore("a"); err == nil {
+		t.Error("Restore after Vacuum must fail")
+	}
+}
+
+func TestAPI_AutoVacuum(t *testing.T) {
+	db := NewVectorDBWithOptions(&Options{Dimension: 2, AutoVacuum: 0.3})
+	for i := range 10 {
+		_ = db.Add(fmt.Sprint("v", i), []float32{1, float32(i)})
+	}
+	for i := range 3 {
+		_ = db.SoftDelete(fmt.Sprint("v", i))
+	}
+	if db.SoftDeleted() != 3 || math.Abs(db.Staleness()-0.3) > 1e-9 || db.GetStats()["staleness"] != db.Staleness() {
+		t.Fatalf("expected 3 soft-deleted at staleness 0.3, got %d at %v", db.SoftDeleted(), db.Staleness())
+	}
+	_ = db.SoftDelete("v3")
+	if db.SoftDeleted() != 0 || db.Staleness() != 0 || db.Size() != 6 {
+		t.Errorf("passing AutoVacuum must vacuum every soft-deleted vector, got %d left", db.SoftDeleted())
+	}
+	if err := db.Restore("v3"); err == nil {
+		t.Error("Restore after an automatic Vacuum must fail")
+	}
+}
+
+func TestAPI_Subscribe(t *testing.T) {
+	db := NewVectorDB(2)
+	var got []string
+	cancel := db.Subscribe(func(e Event) {
+		s := fmt.Sprintf("%d %v %s", e.Seq, e.Op, e.ID)
+		if e.Vector != nil {
+			s += fmt.Sprint(" ", e.Vector.Data)
+		}
+		got = append(got, s)
+		if e.Seq == 3 {
+			_ = db.Update("b", []float32{0, 2}) // delivered after this event, not inside it
+		}
+	})
+	_ = db.Add("a", []float32{1, 0})
+	_ = db.BatchAdd(map[string]any{"a": []float32{2, 0}}, nil)
+	_ = db.Add("b", []float32{0, 1})
+	_ = db.Delete("a")
+	_ = db.SoftDelete("b")
+	_ = db.Restore("b")
+	db.Clear()
+	_ = db.Add("x", []float32{1, 1}, VectorMetadata{Tags: map[string]string{"k": "v"}})
+	cancel()
+	_ = db.Add("y", []float32{1, 1})
+
+	want := []string{
+		"1 add a [1 0]",
+		"2 update a [2 0]",
+		"3 add b [0 1]",
+		"4 update b [0 2]",
+		"5 delete a",
+		"6 delete b",
+		"7 add b [0 2]",
+		"8 clear ",
+		"9 add x [1 1]",
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("events:\n got %q\nwant %q", got, want)
+	}
+}
+
+func TestAPI_Subscribe_Concurrent(t *testing.T) {
+	db := NewVectorDB(2)
+	var last uint64
+	var n int
+	db.Subscribe(func(e Event) {
+		if e.Seq != last+1 {
+			t.Errorf("event %d delivered after %d", e.Seq, last)
+		}
+		last = e.Seq
+		n++
+	})
+	var wg sync.WaitGroup
+	for g := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 50 {
+				_ = db.Add(fmt.Sprint(g, "-", i), []float32{1, float32(i)})
+			}
+		}()
+	}
+	wg.Wait()
+	if n != 400 {
+		t.Errorf("expected 400 events, got %d", n)
+	}
+}
+
+func TestAPI_SetHooks(t *testing.T) {
+	db := NewVectorDB(2)
+	var searches []int
+	var deleted []string
+	db.SetHooks(&Hooks{
+		OnAdd: func(v *Vector) error {
+			if v.ID == "bad" {
+				return errors.New("rejected")
+			}
+			v.Metadata.Attributes = map[string]any{"source": "hook", "n": 1} // enrichment
+			return nil
+		},
+		OnUpdate: func(v *Vector) error {
+			v.Metadata.Tags = map[string]string{"updated": "yes"}
+			return nil
+		},
+		OnDelete: func(id string) error {
+			if id == "keep" {
+				return errors.New("protected")
+			}
+			deleted = append(deleted, id)
+			return nil
+		},
+		OnSearch: func(query []float32, topK int, opts *SearchOptions) error {
+			if len(query) > 0 && query[0] < 0 {
+				return errors.New("negative query")
+			}
+			return nil
+		},
+		OnSearchDone: func(res *SearchResult, err error, took time.Duration) {
+			if err == nil {
+				searches = append(searches, res.Total)
+			}
+		},
+	})
+
+	if err := db.Add("bad", []float32{1, 0}); err == nil || db.Size() != 0 {
+		t.Fatal("OnAdd must be able to reject a write")
+	}
+	if err := db.BatchAdd(map[string]any{"a": []float32{1, 0}, "bad": []float32{0, 1}}, nil); err == nil || db.Size() != 0 {
+		t.Fatal("OnAdd rejecting one vector must reject the batch")
+	}
+	_ = db.Add("a", []float32{1, 0}, VectorMetadata{Tags: map[string]string{"t": "1"}})
+	_ = db.Add("keep", []float32{0, 1})
+	meta, _ := db.GetMetadata("a")
+	if meta.Attributes["source"] != "hook" || meta.Attributes["n"] != float64(1) || meta.Tags["t"] != "1" {
+		t.Errorf("OnAdd enrichment must be stored and normalized, got %+v", meta)
+	}
+	_ = db.Update("a", []float32{1, 1})
+	if meta, _ := db.GetMetadata("a"); meta.Tags["updated"] != "yes" || meta.Attributes["source"] != "hook" {
+		t.Errorf("OnUpdate must see and extend the current metadata, got %+v", meta)
+	}
+	if err := db.Delete("keep"); err == nil || db.Size() != 2 {
+		t.Error("OnDelete must be able to veto a delete")
+	}
+	_ = db.Delete("a")
+	if len(deleted) != 1 || deleted[0] != "a" {
+		t.Errorf("OnDelete calls: %v", deleted)
+	}
+	if _, err := db.Search([]float32{-1, 0}, 1); err == nil {
+		t.Error("OnSearch must be able to fail a search")
+	}
+	_, _ = db.Search([]float32{1, 0}, 5)
+	if len(searches) != 1 || searches[0] != 1 {
+		t.Errorf("OnSearchDone calls: %v", searches)
+	}
+
+	db.SetHooks(nil)
+	if err := db.Add("bad", []float32{1, 0}); err != nil {
+		t.Errorf("SetHooks(nil) must remove the hooks: %v", err)
+	}
+}
+
 func TestAPI_Size(t *testing.T) {
 	db := NewVectorDB(2)
 	if db.Size() != 0 {
@@ -267,6 +561,52 @@ func TestAPI_Clear(t *testing.T) {
 	}
 }
 
+func TestAPI_IDs_Sorted(t *testing.T) {
+	db := NewVectorDB(2)
+	if ids := db.IDs(); len(ids) != 0 {
+		t.Errorf("empty db IDs() must be empty, got %v", ids)
+	}
+	_ = db.Add("b", []float32{1, 0})
+	_ = db.Add("a", []float32{0, 1})
+	ids := db.IDs()
+	if len(ids) != 2 || ids[0] != "a" || ids[1] != "b" {
+		t.Errorf("IDs() must return sorted IDs, got %v", ids)
+	}
+}
+
+func TestAPI_Snapshot(t *testing.T) {
+	db := NewVectorDB(2)
+	_ = db.Add("a", []float32{1, 0}, VectorMetadata{Tags: map[string]string{"v": "1"}})
+	_ = db.Add("b", []float32{0, 1})
+	_ = db.CreateTagIndex("v")
+	snap := db.Snapshot()
+
+	_ = db.Update("a", []float32{0, 1})
+	_ = db.Delete("b")
+	_ = db.Add("c", []float32{1, 0})
+
+	if snap.Size() != 2 || len(snap.IDs()) != 2 || snap.IDs()[1] != "b" {
+		t.Fatalf("snapshot must keep a and b, got %v", snap.IDs())
+	}
+	if v, err := snap.Get("a"); err != nil || v.Data[0] != 1 {
+		t.Errorf("snapshot must keep a's old data, got %v, %v", v, err)
+	}
+	res, err := snap.SearchWithOptions([]float32{1, 0}, 5, &SearchOptions{Tags: map[string]string{"v": "1"}})
+	if err != nil || len(res.Results) != 1 || res.Results[0].ID != "a" || res.Results[0].Score < 0.99 {
+		t.Errorf("indexed search on the snapshot: got %+v, %v", res, err)
+	}
+	var ids []string
+	for v := range snap.Iterate() {
+		ids = append(ids, v.ID)
+	}
+	if len(ids) != 2 || ids[0] != "a" || ids[1] != "b" {
+		t.Errorf("Iterate must yield a, b in order, got %v", ids)
+	}
+	if res, _ := db.Search([]float32{1, 0}, 1); res.Results[0].ID != "c" {
+		t.Errorf("the DB must see its own writes, got %+v", res.Results)
+	}
+}
+
 // --- BatchAdd API ---
 
 func TestAPI_BatchAdd_Empty(t *testing.T) {
@@ -310,6 +650,43 @@ func TestAPI_BatchAdd_WithMetadata(t *testing.T) {
 	}
 }
 
+func TestAPI_BatchAddPartial(t *testing.T) {
+	db := NewVectorDB(0)
+	vecs := map[string]any{
+		"a":   []float32{1, 2},
+		"b":   []float32{3, 4},
+		"c":   []float32{5, 6},
+		"odd": []float32{1, 2, 3},
+		"f64": []float64{1, 2},
+		"":    []float32{1, 2},
+		"nan": []float32{float32(math.NaN()), 1},
+	}
+	meta := map[string]VectorMetadata{"a": {Tags: map[string]string{"t": "1"}}, "c": {Attributes: map[string]any{"x": struct{}{}}}}
+	failed, err := db.BatchAddPartial(vecs, meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"c", "odd", "f64", ""} {
+		if failed[id] == nil {
+			t.Errorf("expected an error for %q", id)
+		}
+	}
+	if db.Size()+len(failed) != len(vecs) || db.Dimension() != 2 {
+		t.Fatalf("valid vectors must be stored with the most common dimension: %d stored, %v", db.Size(), failed)
+	}
+	if va, _ := db.Get("a"); va.Metadata.Tags["t"] != "1" {
+		t.Errorf("metadata must be applied: %+v", va.Metadata)
+	}
+
+	failed, err = db.BatchAddPartial(map[string]any{"d": []float32{7, 8}}, nil)
+	if err != nil || failed != nil {
+		t.Errorf("a fully valid batch must return no failures, got %v, %v", failed, err)
+	}
+	if _, err := db.BatchAddPartial(nil, nil); err == nil {
+		t.Error("an empty batch must return an error")
+	}
+}
+
 // --- Search API ---
 
 func TestAPI_Search_DefaultTopK(t *testing.T) {
@@ -428,6 +805,658 @@ func TestAPI_SearchWithFilter_NilFilter(t *testing.T) {
 	}
 }
 
+// --- SearchWithOptions API ---
+
+func TestAPI_SearchWithOptions_NilOptions(t *testing.T) {
+	db := NewVectorDB(2)
+	_ = db.Add("a", []float32{1, 0})
+	_ = db.Add("b", []float32{0, 1})
+	res, err := db.SearchWithOptions([]float32{1, 0}, 0, nil)
+	if err != nil {
+		t.Fatalf("SearchWithOptions with nil options must succeed: %v", err)
+	}
+	if res.Total != 2 || res.Results[0].ID != "a" {
+		t.Errorf("nil options should behave like Search: %+v", res.Results)
+	}
+}
+
+func TestAPI_SearchWithOptions_ExcludeIDs(t *testing.T) {
+	db := NewVectorDB(2)
+	_ = db.Add("a", []float32{1, 0})
+	_ = db.Add("b", []float32{1, 0.1})
+	_ = db.Add("c", []float32{0, 1})
+	_ = db.Add("d", []float32{-1, 0})
+	res, err := db.SearchWithOptions([]float32{1, 0}, 2, &SearchOptions{
+		ExcludeIDs: []string{"a", "missing"},
+		Filter:     func(v *Vector) bool { return v.ID != "c" },
+	})
+	if err != nil {
+		t.Fatalf("SearchWithOptions failed: %v", err)
+	}
+	if res.Total != 2 || res.Results[0].ID != "b" || res.Results[1].ID != "d" {
+		t.Errorf("expected [b d] after excluding a and filtering c, got %+v", res.Results)
+	}
+}
+
+func TestAPI_SearchWithOptions_AllowIDs(t *testing.T) {
+	db := NewVectorDB(2)
+	_ = db.Add("a", []float32{1, 0})
+	_ = db.Add("b", []float32{1, 0.1})
+	_ = db.Add("c", []float32{0, 1})
+	res, err := db.SearchWithOptions([]float32{1, 0}, 10, &SearchOptions{
+		AllowIDs:   []string{"c", "b", "b", "missing", "a"},
+		ExcludeIDs: []string{"a"},
+	})
+	if err != nil {
+		t.Fatalf("SearchWithOptions failed: %v", err)
+	}
+	if res.Total != 2 || res.Results[0].ID != "b" || res.Results[1].ID != "c" {
+		t.Errorf("expected [b c] from allowlist minus excluded, got %+v", res.Results)
+	}
+
+	res, err = db.SearchWithOptions([]float32{1, 0}, 10, &SearchOptions{AllowIDs: []string{}})
+	if err != nil || res.Total != 0 {
+		t.Errorf("empty allowlist must match nothing, got %v %v", res, err)
+	}
+}
+
+func TestAPI_SearchWithOptions_Where(t *testing.T) {
+	db := NewVectorDB(2)
+	_ = db.Add("a", []float32{1, 0}, VectorMetadata{Attributes: map[string]any{"price": 10, "year": 2021}})
+	_ = db.Add("b", []float32{1, 0.1}, VectorMetadata{Attributes: map[string]any{"price": 150, "year": 2022}})
+	_ = db.Add("c", []float32{0, 1}, VectorMetadata{Attributes: map[string]any{"price": 20, "year": 2018}})
+	res, err := db.SearchWithOptions([]float32{1, 0}, 10, &SearchOptions{
+		Where: []Condition{Lt("price", 100), Gte("year", 2020)},
+	})
+	if err != nil {
+		t.Fatalf("SearchWithOptions failed: %v", err)
+	}
+	if res.Total != 1 || res.Results[0].ID != "a" {
+		t.Errorf("expected only a for price < 100 and year >= 2020, got %+v", res.Results)
+	}
+	if _, err := db.SearchWithOptions([]float32{1, 0}, 10, &SearchOptions{Where: []Condition{In("year")}}); err == nil {
+		t.Error("In with no values must return error")
+	}
+}
+
+func TestAPI_SearchWithOptions_GroupBy(t *testing.T) {
+	db := NewVectorDB(2)
+	chunk := func(id, doc string, y float32) {
+		_ = db.Add(id, []float32{1, y}, VectorMetadata{Tags: map[string]string{"doc_id": doc}})
+	}
+	chunk("d1#0", "d1", 0)
+	chunk("d1#1", "d1", 0.1)
+	chunk("d1#2", "d1", 0.2)
+	chunk("d2#0", "d2", 0.3)
+	chunk("d2#1", "d2", 0.4)
+	chunk("d3#0", "d3", 5)
+	_ = db.Add("loose", []float32{1, 0.05})
+
+	res, err := db.SearchWithOptions([]float32{1, 0}, 2, &SearchOptions{GroupBy: "doc_id"})
+	if err != nil {
+		t.Fatalf("SearchWithOptions failed: %v", err)
+	}
+	if res.Total != 2 || res.Results[0].ID != "d1#0" || res.Results[1].ID != "loose" {
+		t.Errorf("expected best chunk of d1 then the ungrouped vector, got %+v", res.Results)
+	}
+	if res.Results[0].Group != "d1" || res.Results[1].Group != "" {
+		t.Errorf("unexpected groups: %q, %q", res.Results[0].Group, res.Results[1].Group)
+	}
+
+	res, _ = db.SearchWithOptions([]float32{1, 0}, 3, &SearchOptions{GroupBy: "doc_id", GroupSize: 2, ExcludeIDs: []string{"loose"}})
+	var got []string
+	for _, r := range res.Results {
+		got = append(got, r.ID)
+	}
+	want := []string{"d1#0", "d1#1", "d2#0", "d2#1", "d3#0"}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("got %v, want %v", got, want)
+			break
+		}
+	}
+}
+
+func TestAPI_SearchWithOptions_Projection(t *testing.T) {
+	db := NewVectorDB(2)
+	_ = db.Add("a", []float32{1, 0}, VectorMetadata{
+		Tags:       map[string]string{"title": "A", "body": "long text"},
+		Attributes: map[string]any{"price": 5, "blob": "x"},
+	})
+	res, _ := db.SearchWithOptions([]float32{1, 0}, 1, &SearchOptions{OmitMetadata: true})
+	if m := res.Results[0].Metadata; m.Tags != nil || m.Attributes != nil || m.CreatedAt != 0 {
+		t.Errorf("OmitMetadata must return scores only, got %+v", m)
+	}
+	res, _ = db.SearchWithOptions([]float32{1, 0}, 1, &SearchOptions{MetadataKeys: []string{"title", "price"}})
+	m := res.Results[0].Metadata
+	if len(m.Tags) != 1 || m.Tags["title"] != "A" || len(m.Attributes) != 1 || m.Attributes["price"] != float64(5) {
+		t.Errorf("MetadataKeys must keep only title and price, got %+v", m)
+	}
+	if m.CreatedAt == 0 {
+		t.Error("projection must keep timestamps")
+	}
+	full, err := db.GetMetadata("a")
+	if err != nil || len(full.Tags) != 2 {
+		t.Errorf("GetMetadata: %+v %v", full, err)
+	}
+	if _, err := db.GetMetadata("missing"); err == nil {
+		t.Error("GetMetadata of unknown ID must return error")
+	}
+}
+
+func TestAPI_SearchWithOptions_IncludeVectors(t *testing.T) {
+	db := NewVectorDB(2)
+	_ = db.Add("a", []float32{1, 2})
+	res, _ := db.Search([]float32{1, 2}, 1)
+	if res.Results[0].Vector != nil {
+		t.Error("vectors must not be returned by default")
+	}
+	res, err := db.SearchWithOptions([]float32{1, 2}, 1, &SearchOptions{IncludeVectors: true})
+	if err != nil {
+		t.Fatalf("SearchWithOptions failed: %v", err)
+	}
+	v := res.Results[0].Vector
+	if len(v) != 2 || v[0] != 1 || v[1] != 2 {
+		t.Fatalf("IncludeVectors must return the stored vector, got %v", v)
+	}
+	v[0] = 99
+	if stored, _ := db.Get("a"); stored.Data[0] != 1 {
+		t.Error("returned vector must be a copy")
+	}
+}
+
+func TestAPI_SearchWithOptions_Pagination(t *testing.T) {
+	db := NewVectorDB(2, EuclideanDistance)
+	for i := range 7 {
+		_ = db.Add(string(rune('a'+i)), []float32{float32(i), 0})
+	}
+	var got []string
+	opts := &SearchOptions{}
+	for pages := 0; ; pages++ {
+		if pages > 3 {
+			t.Fatal("pagination did not terminate")
+		}
+		res, err := db.SearchWithOptions([]float32{0, 0}, 3, opts)
+		if err != nil {
+			t.Fatalf("SearchWithOptions failed: %v", err)
+		}
+		for _, r := range res.Results {
+			got = append(got, r.ID)
+		}
+		if res.NextCursor == "" {
+			break
+		}
+		opts.Cursor = res.NextCursor
+	}
+	if len(got) != 7 || got[0] != "a" || got[3] != "d" || got[6] != "g" {
+		t.Errorf("paging with cursors must visit every result once in rank order, got %v", got)
+	}
+
+	res, _ := db.SearchWithOptions([]float32{0, 0}, 2, &SearchOptions{Offset: 5})
+	if res.Total != 2 || res.Results[0].ID != "f" || res.NextCursor != "" {
+		t.Errorf("offset 5 must return the last page [f g], got %+v", res)
+	}
+	res, _ = db.SearchWithOptions([]float32{0, 0}, 2, &SearchOptions{Offset: 10})
+	if res.Total != 0 {
+		t.Errorf("offset past the end must return no results, got %d", res.Total)
+	}
+	if _, err := db.SearchWithOptions([]float32{0, 0}, 2, &SearchOptions{Cursor: "bogus"}); err == nil {
+		t.Error("invalid cursor must return error")
+	}
+	if _, err := db.SearchWithOptions([]float32{0, 0}, 2, &SearchOptions{Offset: -1}); err == nil {
+		t.Error("negative offset must return error")
+	}
+}
+
+func TestAPI_SearchWithOptions_HugeOffset(t *testing.T) {
+	db := NewVectorDB(2)
+	_ = db.Add("a", []float32{1, 0})
+	forged := base64.RawURLEncoding.EncodeToString([]byte("o1:20000000"))
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	res, err := db.SearchWithOptions([]float32{1, 0}, 10, &SearchOptions{Cursor: forged})
+	runtime.ReadMemStats(&after)
+	if err != nil || res.Total != 0 || res.NextCursor != "" {
+		t.Errorf("a cursor past the end must return an empty last page, got %+v, %v", res, err)
+	}
+	if grew := after.TotalAlloc - before.TotalAlloc; grew > 1<<20 {
+		t.Errorf("a forged cursor must not size allocations, allocated %d bytes", grew)
+	}
+
+	for _, opts := range []*SearchOptions{{Offset: math.MaxInt}, {Offset: math.MaxInt, Dimensions: 1, RescoreTop: math.MaxInt}} {
+		res, err := db.SearchWithOptions([]float32{1, 0}, 10, opts)
+		if err != nil || res.Total != 0 || res.NextCursor != "" {
+			t.Errorf("Offset=MaxInt must return an empty page, got %+v, %v", res, err)
+		}
+	}
+	res, err = db.SearchWithOptions([]float32{1, 0}, math.MaxInt, &SearchOptions{Dimensions: 1, RescoreTop: math.MaxInt})
+	if err != nil || res.Total != 1 || res.NextCursor != "" {
+		t.Errorf("a huge topK must return every vector, got %+v, %v", res, err)
+	}
+
+	sharded := NewShardedVectorDB(2, &Options{Dimension: 2})
+	_ = sharded.Add("a", []float32{1, 0})
+	if res, err := sharded.SearchWithOptions([]float32{1, 0}, 10, &SearchOptions{Offset: math.MaxInt}); err != nil || res.Total != 0 || res.NextCursor != "" {
+		t.Errorf("sharded Offset=MaxInt must return an empty page, got %+v, %v", res, err)
+	}
+	quota := NewQuotaStore(db, Quota{MaxTopK: 100})
+	var qe *QuotaError
+	if _, err := quota.SearchWithOptions([]float32{1, 0}, 10, &SearchOptions{Offset: math.MaxInt}); !errors.As(err, &qe) {
+		t.Errorf("Offset=MaxInt must exceed MaxTopK, got %v", err)
+	}
+}
+
+func TestAPI_Search_TiesBrokenByID(t *testing.T) {
+	for run := range 5 {
+		db := NewVectorDB(2)
+		for _, id := range []string{"d", "b", "e", "a", "c"} {
+			_ = db.Add(id, []float32{1, 0})
+		}
+		res, _ := db.Search([]float32{1, 0}, 3)
+		if res.Results[0].ID != "a" || res.Results[1].ID != "b" || res.Results[2].ID != "c" {
+			t.Fatalf("run %d: equal scores must be ordered by ID, got %+v", run, res.Results)
+		}
+	}
+}
+
+func TestAPI_SearchWithOptions_Explain(t *testing.T) {
+	db := NewVectorDB(2)
+	_ = db.CreateTagIndex("lang")
+	_ = db.Add("a", []float32{3, 4}, VectorMetadata{Tags: map[string]string{"lang": "en"}, Attributes: map[string]any{"price": 5}})
+	_ = db.Add("b", []float32{1, 0}, VectorMetadata{Tags: map[string]string{"lang": "en"}, Attributes: map[string]any{"price": 500}})
+	_ = db.Add("c", []float32{1, 0}, VectorMetadata{Tags: map[string]string{"lang": "fr"}})
+
+	res, err := db.SearchWithOptions([]float32{1, 0}, 5, &SearchOptions{
+		Explain: true,
+		Tags:    map[string]string{"lang": "en"},
+		Where:   []Condition{Lt("price", 100)},
+		Plan:    PlanIndex,
+	})
+	if err != nil {
+		t.Fatalf("SearchWithOptions failed: %v", err)
+	}
+	if res.Total != 1 || res.Results[0].Explanation == nil {
+		t.Fatalf("expected one explained result, got %+v", res.Results)
+	}
+	e := res.Results[0].Explanation
+	if e.Dot != 3 || e.QueryNorm != 1 || e.VectorNorm != 5 || math.Abs(e.Similarity-0.6) > 1e-9 || e.Distance != CosineSimilarity {
+		t.Errorf("unexpected explanation: %+v", e)
+	}
+	if len(e.Filters) != 2 || e.Filters[0] != "price < 100" || e.Filters[1] != "tag lang = en" {
+		t.Errorf("unexpected filters: %v", e.Filters)
+	}
+	if res.Stats == nil || res.Stats.Candidates != 2 || res.Stats.Matched != 1 || !res.Stats.Indexed {
+		t.Errorf("unexpected stats: %+v", res.Stats)
+	}
+
+	res, _ = db.Search([]float32{1, 0}, 5)
+	if res.Stats != nil || res.Results[0].Explanation != nil {
+		t.Error("explain output must be opt-in")
+	}
+}
+
+func TestAPI_SearchWithOptions_Boosts(t *testing.T) {
+	db := NewVectorDB(2, DotProduct)
+	_ = db.Add("a", []float32{1, 0}, VectorMetadata{Attributes: map[string]any{"weight": 0.5}})
+	_ = db.Add("b", []float32{0.8, 0}, VectorMetadata{Tags: map[string]string{"lang": "en", "weight": "1"}})
+	_ = db.Add("c", []float32{0.7, 0})
+	en := Eq("lang", "en")
+	res, err := db.SearchWithOptions([]float32{1, 0}, 3, &SearchOptions{
+		Boosts: []Boost{
+			{Key: "weight"},                   // a: 1*0.5, b: 0.8*1
+			{If: &en, Weight: 0.1, Add: true}, // b: +0.1
+		},
+		Explain: true,
+	})
+	if err != nil {
+		t.Fatalf("SearchWithOptions failed: %v", err)
+	}
+	if res.Results[0].ID != "b" || res.Results[1].ID != "c" || res.Results[2].ID != "a" {
+		t.Fatalf("expected boosted order [b c a], got %+v", res.Results)
+	}
+	if math.Abs(res.Results[0].Score-0.9) > 1e-6 || len(res.Results[0].Explanation.Boosts) != 2 {
+		t.Errorf("unexpected boosted score or trace: %v %v", res.Results[0].Score, res.Results[0].Explanation.Boosts)
+	}
+	if len(res.Results[1].Explanation.Boosts) != 0 {
+		t.Errorf("c has no weight and no lang; no boost should apply: %v", res.Results[1].Explanation.Boosts)
+	}
+
+	res, _ = db.SearchWithOptions([]float32{1, 0}, 1, &SearchOptions{
+		Rescore: func(v *Vector, score float64) float64 {
+			if v.ID == "c" {
+				return 10
+			}
+			return score
+		},
+	})
+	if res.Results[0].ID != "c" || res.Results[0].Score != 10 {
+		t.Errorf("Rescore must set the final score, got %+v", res.Results)
+	}
+	if _, err := db.SearchWithOptions([]float32{1, 0}, 1, &SearchOptions{Boosts: []Boost{{}}}); err == nil {
+		t.Error("empty boost must return error")
+	}
+}
+
+func TestAPI_SearchWithOptions_Decay(t *testing.T) {
+	db := NewVectorDB(2, DotProduct)
+	_ = db.Add("a", []float32{1, 0})
+	day := 24 * time.Hour
+	for _, tc := range []struct {
+		decay Decay
+		want  float64
+	}{
+		{Decay{HalfLife: day}, 1},
+		{Decay{HalfLife: day, Now: time.Now().Add(day)}, 0.5},
+		{Decay{HalfLife: day, Now: time.Now().Add(2 * day)}, 0.25},
+		{Decay{HalfLife: day, Now: time.Now().Add(day), Linear: true}, 0.5},
+		{Decay{HalfLife: day, Now: time.Now().Add(3 * day), Linear: true}, 0},
+		{Decay{HalfLife: day, Now: time.Now().Add(-day)}, 1}, // created in the future
+	} {
+		res, err := db.SearchWithOptions([]float32{1, 0}, 1, &SearchOptions{Decay: &tc.decay})
+		if err != nil {
+			t.Fatalf("SearchWithOptions failed: %v", err)
+		}
+		if got := res.Results[0].Score; math.Abs(got-tc.want) > 1e-4 {
+			t.Errorf("%+v: score %v, want %v", tc.decay, got, tc.want)
+		}
+	}
+	if _, err := db.SearchWithOptions([]float32{1, 0}, 1, &SearchOptions{Decay: &Decay{}}); err == nil {
+		t.Error("zero half-life must return error")
+	}
+
+	// Distances grow with age instead, so older vectors still rank lower.
+	l2 := NewVectorDB(2, EuclideanDistance)
+	_ = l2.Add("a", []float32{3, 0})
+	res, err := l2.SearchWithOptions([]float32{1, 0}, 1, &SearchOptions{Decay: &Decay{HalfLife: day, Now: time.Now().Add(day)}})
+	if err != nil {
+		t.Fatalf("SearchWithOptions failed: %v", err)
+	}
+	if got := res.Results[0].Score; math.Abs(got-4) > 1e-4 {
+		t.Errorf("a distance of 2 at one half-life must decay to 4, got %v", got)
+	}
+}
+
+func TestAPI_SearchWithOptions_Weights(t *testing.T) {
+	for _, fn := range []DistanceFunction{CosineSimilarity, DotProduct, EuclideanDistance, ManhattanDistance} {
+		db := NewVectorDB(3, fn)
+		_ = db.Add("a", []float32{1, 0, 5})
+		_ = db.Add("b", []float32{-0.2, 1, 0})
+		q := []float32{1, 1, 0}
+
+		plain, _ := db.SearchWithOptions(q, 2, nil)
+		ones, err := db.SearchWithOptions(q, 2, &SearchOptions{Weights: []float32{1, 1, 1}})
+		if err != nil {
+			t.Fatalf("SearchWithOptions failed: %v", err)
+		}
+		for i := range plain.Results {
+			if math.Abs(plain.Results[i].Score-ones.Results[i].Score) > 1e-9 {
+				t.Errorf("metric %v: unit weights must not change scores: %+v vs %+v", fn, plain.Results, ones.Results)
+			}
+		}
+		// Ignoring the third dimension makes a the best match for q under every metric.
+		res, _ := db.SearchWithOptions(q, 1, &SearchOptions{Weights: []float32{1, 0, 0}, Explain: true})
+		if res.Results[0].ID != "a" || res.Results[0].Explanation.Similarity != res.Results[0].Score {
+			t.Errorf("metric %v: expected a with explained score, got %+v", fn, res.Results[0])
+		}
+	}
+
+	db := NewVectorDB(2)
+	_ = db.Add("a", []float32{1, 0})
+	if _, err := db.SearchWithOptions([]float32{1, 0}, 1, &SearchOptions{Weights: []float32{1}}); err == nil {
+		t.Error("weights of the wrong length must return error")
+	}
+	if _, err := db.SearchWithOptions([]float32{1, 0}, 1, &SearchOptions{Weights: []float32{1, -1}}); err == nil {
+		t.Error("negative weight must return error")
+	}
+}
+
+func TestAPI_SearchWithOptions_Dimensions(t *testing.T) {
+	db := NewVectorDB(4)
+	_ = db.Add("a", []float32{1, 0, -1, 0})    // best on the prefix, poor in full
+	_ = db.Add("b", []float32{0.9, 0.1, 1, 0}) // best in full
+	_ = db.Add("c", []float32{0, 1, 0, 1})
+	q := []float32{1, 0, 1, 0}
+
+	res, err := db.SearchWithOptions(q, 1, &SearchOptions{Dimensions: 2})
+	if err != nil {
+		t.Fatalf("SearchWithOptions failed: %v", err)
+	}
+	if res.Results[0].ID != "a" || math.Abs(res.Results[0].Score-1) > 1e-6 {
+		t.Errorf("prefix search: expected a scoring 1, got %+v", res.Results)
+	}
+	res, _ = db.SearchWithOptions(q, 1, &SearchOptions{Dimensions: 2, RescoreTop: 2, Explain: true})
+	if res.Results[0].ID != "b" || res.Stats.Rescored != 2 {
+		t.Errorf("rescored search: expected b after rescoring 2, got %+v %+v", res.Results, res.Stats)
+	}
+	if full, _ := db.Search(q, 1); full.Results[0].Score != res.Results[0].Score {
+		t.Errorf("rescored score %v must equal the full score %v", res.Results[0].Score, full.Results[0].Score)
+	}
+	if _, err := db.SearchWithOptions(q, 1, &SearchOptions{Dimensions: 5}); err == nil {
+		t.Error("Dimensions beyond the query must return error")
+	}
+}
+
+func TestAPI_SearchWithOptions_Binary(t *testing.T) {
+	db := NewVectorDB(4)
+	_ = db.Add("a", []float32{1, 0.2, -1, 0.1})
+	_ = db.Add("b", []float32{-1, 1, 1, -1})
+	q := []float32{1, 0.1, -0.5, 0.3}
+	if _, err := db.SearchWithOptions(q, 1, &SearchOptions{Binary: true}); err == nil {
+		t.Fatal("Binary without CreateBinaryIndex must return error")
+	}
+
+	db.CreateBinaryIndex()
+	_ = db.Add("c", []float32{0.9, 0.3, -0.8, 0.2})
+	_ = db.Update("b", []float32{1, 0.1, -0.5, 0.3}) // now identical to q
+	exact, _ := db.Search(q, 3)
+	res, err := db.SearchWithOptions(q, 3, &SearchOptions{Binary: true, Explain: true, Plan: PlanIndex})
+	if err != nil {
+		t.Fatalf("SearchWithOptions failed: %v", err)
+	}
+	if len(res.Results) != 3 || res.Stats.Rescored != 3 {
+		t.Fatalf("expected 3 rescored results, got %+v %+v", res.Results, res.Stats)
+	}
+	for i := range exact.Results {
+		if res.Results[i].ID != exact.Results[i].ID || res.Results[i].Score != exact.Results[i].Score {
+			t.Errorf("binary search must return exact scores: got %+v, want %+v", res.Results[i], exact.Results[i])
+		}
+	}
+
+	// A one-candidate shortlist keeps only what the sign bits rank first.
+	res, _ = db.SearchWithOptions(q, 1, &SearchOptions{Binary: true, RescoreTop: 1, Dimensions: 2})
+	if len(res.Results) != 1 {
+		t.Errorf("expected 1 result, got %+v", res.Results)
+	}
+	db.DropBinaryIndex()
+	if _, err := db.SearchWithOptions(q, 1, &SearchOptions{Binary: true}); err == nil {
+		t.Error("Binary after DropBinaryIndex must return error")
+	}
+}
+
+func TestAPI_SearchWithOptions_Plan(t *testing.T) {
+	db := NewVectorDB(2)
+	_ = db.CreateTagIndex("lang")
+	db.CreateBinaryIndex()
+	for i := range 10 {
+		lang := "en"
+		if i == 0 {
+			lang = "fr"
+		}
+		_ = db.Add(fmt.Sprint("v", i), []float32{1, float32(i)}, VectorMetadata{Tags: map[string]string{"lang": lang}})
+	}
+	q := []float32{1, 2}
+	stats := func(opts SearchOptions) *SearchStats {
+		t.Helper()
+		opts.Explain = true
+		res, err := db.SearchWithOptions(q, 3, &opts)
+		if err != nil {
+			t.Fatalf("SearchWithOptions(%+v) failed: %v", opts, err)
+		}
+		return res.Stats
+	}
+
+	if s := stats(SearchOptions{Tags: map[string]string{"lang": "fr"}}); !s.Indexed || s.Candidates != 1 {
+		t.Errorf("a selective indexed filter must narrow the scan, got %+v", s)
+	}
+	if s := stats(SearchOptions{Tags: map[string]string{"lang": "en"}}); s.Indexed || s.Candidates != 10 || s.Matched != 9 {
+		t.Errorf("a broad indexed filter must scan, got %+v", s)
+	}
+	if s := stats(SearchOptions{Tags: map[string]string{"lang": "en"}, Plan: PlanIndex}); !s.Indexed || s.Candidates != 9 {
+		t.Errorf("PlanIndex must narrow the scan, got %+v", s)
+	}
+	if s := stats(SearchOptions{Tags: map[string]string{"lang": "fr"}, Plan: PlanScan}); s.Indexed || s.Matched != 1 {
+		t.Errorf("PlanScan must not narrow the scan, got %+v", s)
+	}
+
+	if s := stats(SearchOptions{Binary: true}); s.Rescored != 0 {
+		t.Errorf("a shortlist holding every candidate must be skipped, got %+v", s)
+	}
+	if s := stats(SearchOptions{Binary: true, RescoreTop: 5}); s.Rescored != 5 {
+		t.Errorf("a smaller shortlist must be kept, got %+v", s)
+	}
+	if s := stats(SearchOptions{Binary: true, RescoreTop: 5, Plan: PlanScan}); s.Rescored != 0 {
+		t.Errorf("PlanScan must skip the first pass, got %+v", s)
+	}
+	if _, err := db.SearchWithOptions(q, 3, &SearchOptions{Plan: Plan(7)}); err == nil {
+		t.Error("an unknown plan must return error")
+	}
+}
+
+func TestAPI_SearchWithOptions_Epsilon(t *testing.T) {
+	db := NewVectorDB(2)
+	for i := range 100 {
+		_ = db.Add(fmt.Sprintf("v%03d", i), []float32{1, float32(i) / 100})
+	}
+	q := []float32{1, 0}
+	res, err := db.SearchWithOptions(q, 3, &SearchOptions{Epsilon: 0.01, Explain: true})
+	if err != nil {
+		t.Fatalf("SearchWithOptions failed: %v", err)
+	}
+	if !res.Stats.Stopped || res.Stats.Candidates >= 100 {
+		t.Errorf("the scan must stop early, got %+v", res.Stats)
+	}
+	for _, r := range res.Results {
+		if r.Score < 0.99 {
+			t.Errorf("results must be within epsilon of the best score, got %+v", r)
+		}
+	}
+	if res, _ := db.SearchWithOptions(q, 3, &SearchOptions{Epsilon: 1e-9, Explain: true}); res.Stats.Stopped || res.Results[0].ID != "v000" {
+		t.Errorf("a tight epsilon must scan everything, got %+v %+v", res.Stats, res.Results)
+	}
+
+	for _, opts := range []*SearchOptions{
+		{Epsilon: -1},
+		{Epsilon: 0.1, GroupBy: "k"},
+		{Epsilon: 0.1, Boosts: []Boost{{Key: "w"}}},
+	} {
+		if _, err := db.SearchWithOptions(q, 3, opts); err == nil {
+			t.Errorf("expected an error for %+v", opts)
+		}
+	}
+	dot := NewVectorDB(2, DotProduct)
+	_ = dot.Add("a", q)
+	if _, err := dot.SearchWithOptions(q, 3, &SearchOptions{Epsilon: 0.1}); err == nil {
+		t.Error("Epsilon with DotProduct must return error")
+	}
+}
+
+func TestAPI_SearchWithOptions_MaxDuration(t *testing.T) {
+	db := NewVectorDB(2)
+	slow := func(*Vector) bool { time.Sleep(50 * time.Microsecond); return true }
+	for i := range 2000 {
+		_ = db.Add(fmt.Sprint("v", i), []float32{1, float32(i)})
+	}
+	res, err := db.SearchWithOptions([]float32{1, 0}, 3, &SearchOptions{Filter: slow, MaxDuration: time.Millisecond, Explain: true})
+	if err != nil {
+		t.Fatalf("SearchWithOptions failed: %v", err)
+	}
+	if !res.Truncated || len(res.Results) != 3 || res.Stats.Candidates >= 2000 {
+		t.Errorf("expected 3 results from a truncated scan, got %d, %+v", len(res.Results), res.Stats)
+	}
+	if res, _ := db.SearchWithOptions([]float32{1, 0}, 3, &SearchOptions{MaxDuration: time.Minute}); res.Truncated {
+		t.Error("a search within its budget must not be truncated")
+	}
+	if _, err := db.SearchWithOptions([]float32{1, 0}, 3, &SearchOptions{MaxDuration: -1}); err == nil {
+		t.Error("a negative MaxDuration must return error")
+	}
+
+	sharded := NewShardedVectorDB(2, &Options{Dimension: 2})
+	for i := range 2000 {
+		_ = sharded.Add(fmt.Sprint("v", i), []float32{1, float32(i)})
+	}
+	if res, _ := sharded.SearchWithOptions([]float32{1, 0}, 3, &SearchOptions{Filter: slow, MaxDuration: time.Millisecond}); !res.Truncated {
+		t.Error("the shards must share the budget and report truncation")
+	}
+}
+
+// --- MoreLikeThis API ---
+
+func TestAPI_MoreLikeThis_NotFound(t *testing.T) {
+	db := NewVectorDB(2)
+	if _, err := db.MoreLikeThis("missing", 5, true); err == nil {
+		t.Fatal("MoreLikeThis with unknown ID must return error")
+	}
+}
+
+func TestAPI_MoreLikeThis_ExcludeSelf(t *testing.T) {
+	db := NewVectorDB(2)
+	_ = db.Add("src", []float32{1, 0})
+	_ = db.Add("dup", []float32{2, 0}) // same direction: near-duplicate under cosine
+	_ = db.Add("near", []float32{1, 0.2})
+	_ = db.Add("far", []float32{0, 1})
+
+	res, err := db.MoreLikeThis("src", 10, false)
+	if err != nil {
+		t.Fatalf("MoreLikeThis failed: %v", err)
+	}
+	if res.Total != 4 || res.QueryID != "src" {
+		t.Errorf("without excludeSelf expected 4 results for src, got %d (%q)", res.Total, res.QueryID)
+	}
+
+	res, err = db.MoreLikeThis("src", 10, true)
+	if err != nil {
+		t.Fatalf("MoreLikeThis failed: %v", err)
+	}
+	if res.Total != 2 || res.Results[0].ID != "near" || res.Results[1].ID != "far" {
+		t.Errorf("excludeSelf must drop src and dup, got %+v", res.Results)
+	}
+}
+
+func TestAPI_MoreLikeThis_Hooks(t *testing.T) {
+	db := NewVectorDB(2)
+	_ = db.Add("src", []float32{1, 0})
+	_ = db.Add("near", []float32{1, 0.2})
+	var queries [][]float32
+	var done []string
+	db.SetHooks(&Hooks{
+		OnSearch: func(query []float32, topK int, opts *SearchOptions) error {
+			queries = append(queries, query)
+			return nil
+		},
+		OnSearchDone: func(res *SearchResult, err error, took time.Duration) {
+			if err == nil {
+				done = append(done, res.QueryID)
+			}
+		},
+	})
+	res, err := db.MoreLikeThis("src", 5, true)
+	if err != nil {
+		t.Fatalf("MoreLikeThis failed: %v", err)
+	}
+	if len(queries) != 1 || queries[0][0] != 1 || queries[0][1] != 0 {
+		t.Errorf("OnSearch must see the stored vector as the query, got %v", queries)
+	}
+	if len(done) != 1 || done[0] != "src" {
+		t.Errorf("OnSearchDone must see the QueryID, got %v", done)
+	}
+	if res.Took <= 0 {
+		t.Errorf("MoreLikeThis must set Took, got %v", res.Took)
+	}
+}
+
 // --- BatchSearch API ---
 
 func TestAPI_BatchSearch_DefaultTopK(t *testing.T) {
@@ -472,6 +1501,31 @@ func TestAPI_BatchSearch_QueryError(t *testing.T) {
 	}
 }
 
+func TestAPI_BatchSearchWithOptions(t *testing.T) {
+	db := NewVectorDB(2)
+	_ = db.Add("a", []float32{1, 0}, VectorMetadata{Tags: map[string]string{"k": "x"}})
+	_ = db.Add("b", []float32{0, 1})
+	queries := map[string]any{"bad": []float64{1, 2}, "short": []float32{1}}
+	for i := range 20 {
+		queries[fmt.Sprint(i)] = []float32{float32(i), 1}
+	}
+	for _, concurrency := range []int{0, 4, 100} {
+		results, errs := db.BatchSearchWithOptions(queries, 5, &BatchSearchOptions{
+			Concurrency: concurrency,
+			Search:      &SearchOptions{Tags: map[string]string{"k": "x"}},
+		})
+		if len(results) != 20 || len(errs) != 2 || errs["bad"] == nil || errs["short"] == nil {
+			t.Fatalf("concurrency %d: expected 20 results and 2 errors, got %d and %v", concurrency, len(results), errs)
+		}
+		if r := results["7"]; r.QueryID != "7" || r.Total != 1 || r.Results[0].ID != "a" {
+			t.Errorf("concurrency %d: options must apply to every query, got %+v", concurrency, r)
+		}
+	}
+	if results, errs := db.BatchSearchWithOptions(nil, 0, nil); len(results) != 0 || errs != nil {
+		t.Errorf("an empty batch must return no results and no errors, got %v, %v", results, errs)
+	}
+}
+
 // --- SearchMMR API ---
 
 func TestAPI_SearchMMR_DefaultTopK(t *testing.T) {
@@ -631,6 +1685,83 @@ func TestAPI_GetStats_Shape(t *testing.T) {
 	}
 }
 
+func TestAPI_GetStatsWithOptions(t *testing.T) {
+	db := NewVectorDB(2, EuclideanDistance)
+	for i, tag := range []string{"en", "en", "fr", "de"} {
+		_ = db.Add(fmt.Sprint("v", i), []float32{float32(i), 0}, VectorMetadata{Tags: map[string]string{"lang": tag, "src": "web"}})
+	}
+	if _, ok := db.GetStatsWithOptions(nil)["norm_histogram"]; ok {
+		t.Error("histograms must be opt-in")
+	}
+	stats := db.GetStatsWithOptions(&StatsOptions{TagCardinality: true, NormBuckets: 3, CreatedBucket: time.Hour})
+	if c := stats["tag_cardinality"].(map[string]int); c["lang"] != 3 || c["src"] != 1 {
+		t.Errorf("unexpected tag cardinality %v", c)
+	}
+	if h := stats["norm_histogram"].(Histogram); h.Min != 0 || h.Max != 3 || !slices.Equal(h.Counts, []int{1, 1, 2}) {
+		t.Errorf("unexpected norm histogram %+v", h)
+	}
+	total := 0
+	for _, b := range stats["created_histogram"].([]TimeBucket) {
+		if b.Start.Minute() != 0 || b.Start.Second() != 0 {
+			t.Errorf("buckets must start on the hour, got %v", b.Start)
+		}
+		total += b.Count
+	}
+	if total != 4 || stats["total_vectors"] != 4 {
+		t.Errorf("expected 4 vectors in the created histogram, got %d", total)
+	}
+
+	_ = db.Add("untagged", []float32{1, 1}, VectorMetadata{})
+	_ = db.SoftDelete("v3")
+	groups := db.GetStatsWithOptions(&StatsOptions{GroupBy: "lang"})["groups"].(map[string]GroupStats)
+	if en := groups["en"]; en.Vectors != 2 || en.SoftDeleted != 0 || en.MemoryBytes <= 0 {
+		t.Errorf("unexpected en group %+v", en)
+	}
+	if de := groups["de"]; de.Vectors != 0 || de.SoftDeleted != 1 || de.MemoryBytes <= 0 {
+		t.Errorf("soft-deleted vectors must count in their group, got %+v", de)
+	}
+	if groups[""].Vectors != 1 || len(groups) != 4 {
+		t.Errorf("untagged vectors must group under \"\", got %v", groups)
+	}
+}
+
+func TestAPI_Model(t *testing.T) {
+	const model = "text-embedding-3-small/1536"
+	db := NewVectorDBWithOptions(&Options{Dimension: 2, Model: model})
+	if db.Model() != model {
+		t.Fatalf("Model() = %q", db.Model())
+	}
+	tagged := func(m string) VectorMetadata { return VectorMetadata{Tags: map[string]string{ModelTag: m}} }
+	if err := db.Add("a", []float32{1, 0}, tagged(model)); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Add("untagged", []float32{0, 1}); err != nil {
+		t.Errorf("untagged writes must be accepted: %v", err)
+	}
+	if err := db.Add("b", []float32{1, 1}, tagged("text-embedding-ada-002")); err == nil {
+		t.Error("expected a write from another model to fail")
+	}
+	if err := db.BatchAdd(map[string]any{"c": []float32{1, 1}}, map[string]VectorMetadata{"c": tagged("other")}); err == nil {
+		t.Error("expected a batch write from another model to fail")
+	}
+	if err := db.Update("a", []float32{1, 0}, tagged("other")); err == nil {
+		t.Error("expected an update from another model to fail")
+	}
+	if db.Size() != 2 {
+		t.Errorf("rejected writes must store nothing, size %d", db.Size())
+	}
+
+	if _, err := db.SearchWithOptions([]float32{1, 0}, 1, &SearchOptions{Model: model}); err != nil {
+		t.Error(err)
+	}
+	if _, err := db.SearchWithOptions([]float32{1, 0}, 1, &SearchOptions{Model: "other"}); err == nil {
+		t.Error("expected a query from another model to fail")
+	}
+	if _, err := NewVectorDB(2).SearchWithOptions([]float32{1, 0}, 1, &SearchOptions{Model: "other"}); err != nil {
+		t.Errorf("a DB without a model must accept any query: %v", err)
+	}
+}
+
 // --- Exported type and constant compatibility ---
 
 func TestAPI_ExportedTypesExist(t *testing.T) {
@@ -642,11 +1773,63 @@ func TestAPI_ExportedTypesExist(t *testing.T) {
 	var _ SearchResult
 	var _ SimilarityResult
 	var _ MMROptions
+	var _ BatchSearchOptions
 	var _ MMRCandidate
 	var _ DistanceFunction
 	var _ MMRScoreMode
+	var _ ParquetOptions
+	var _ FAISSIndex
+	var _ *Snapshot
+	var _ Event
+	var _ EventOp = EventAdd
+	var _ Hooks
+	var _ SlowQuery
+	var _ SnapshotStorage = FileStorage("")
+	var _ *AutoSnapshot
+	var _ BulkFormat = BulkJSONL
+	var _ ProgressFunc
+	var _ Record
+	var _ IngestOptions
+	var _ *Ingester
+	var _ IDGenerator = NewUUIDv7
+	var _ *Aliases
+	var _ DiffResult
+	var _ *ShardedVectorDB
+	var _ VectorStore = NewVectorDB(2)
+	var _ VectorStore = NewQuotaStore(NewVectorDB(2), Quota{})
+	var _ error = &QuotaError{}
+	var _ Transform = &PCA{}
+	var _ Transform = NewRandomProjection(8, 4, 1)
+	var _ KeepPolicy = KeepNewest
+	var _ KNNGraph
+	var _ Plan = PlanAuto
+	var _ StreamUpdate
+	var _ NumericSummary
+	var _ StatsOptions
+	var _ Histogram
+	var _ TimeBucket
+	var _ GroupStats
+	var _ Baseline
+	var _ DriftReport
+	var _ HealthStatus
+	var _ AutoSnapshotStatus
+	var _ PurgeReport
+	var _ Embedder = EmbedderFunc(nil)
+	var _ QueryEmbedder
+	var _ SearchOptions
+	var _ Condition = Eq("k", 1)
+	var _ FilterOp = OpPrefix
+	var _ Explanation
+	var _ Boost
+	var _ Decay
+	var _ Options
+	var _ SearchStats
 
 	var _ VectorType = Float32
+	var _ VectorType = Float16
+	var _ VectorType = BFloat16
+	var _ VectorType = Int8
+	var _ VectorType = Uint8
 	var _ DistanceFunction = CosineSimilarity
 	var _ DistanceFunction = DotProduct
 	var _ DistanceFunction = EuclideanDistance
@@ -711,3 +1894,21 @@ func TestAPI_SelectMMRFromCandidates_NaNBaseScoreTreatedAsZero(t *testing.T) {
 		t.Errorf("NaN BaseScore should be treated as 0; b should be first: %v", res.Results)
 	}
 }
+
+func TestAPI_VectorStore(t *testing.T) {
+	for name, store := range map[string]VectorStore{
+		"db":      NewVectorDB(2),
+		"sharded": NewShardedVectorDB(3, &Options{Dimension: 2}),
+	} {
+		for i := range 10 {
+			if err := store.Add(fmt.Sprintf("doc%d", i), []float32{float32(i), 1}); err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+		}
+		_ = store.Delete("doc0")
+		res, err := store.Search([]float32{9, 1}, 2)
+		if err != nil || store.Size() != 9 || len(res.Results) != 2 || res.Results[0].ID != "doc9" {
+			t.Errorf("%s: unexpected %+v, %v", name, res, err)
+		}
+	}
+}
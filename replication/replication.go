@@ -0,0 +1,153 @@
+// Package replication keeps read replicas of a VectorDB consistent with one writer by
+// shipping its change log: the writer turns every committed change into an Entry, the
+// entries travel over any transport (a channel, a queue, JSON lines in object storage) and
+// each replica applies them in order.
+//
+// A replica starts from a snapshot of the writer. Read the writer's Seq before taking the
+// snapshot and pass it to NewReplica: entries committed while the snapshot was taken are
+// applied again, which is harmless because every entry stores the vector's full state.
+package replication
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	serverlessVector "github.com/takara-ai/serverlessVector/v2"
+)
+
+// Entry is one change in the writer's log.
+type Entry struct {
+	Seq      uint64                           `json:"seq"`
+	Op       string                           `json:"op"` // "add", "update", "delete" or "clear"
+	ID       string                           `json:"id,omitempty"`
+	Vector   []float32                        `json:"vector,omitempty"`
+	Metadata *serverlessVector.VectorMetadata `json:"metadata,omitempty"`
+}
+
+// NewEntry converts a change delivered by VectorDB.Subscribe into an Entry.
+func NewEntry(e serverlessVector.Event) Entry {
+	entry := Entry{Seq: e.Seq, Op: e.Op.String(), ID: e.ID}
+	if e.Vector != nil {
+		meta := e.Vector.Metadata
+		entry.Vector, entry.Metadata = e.Vector.Data, &meta
+	}
+	return entry
+}
+
+// Ship calls send with an Entry for every later change to db, in Seq order. send runs on
+// the writer's goroutine, as for Subscribe, so it should hand the entry off (to a buffered
+// channel or a batching uploader) rather than block on the network. The returned cancel
+// stops shipping.
+func Ship(db *serverlessVector.VectorDB, send func(Entry)) (cancel func()) {
+	return db.Subscribe(func(e serverlessVector.Event) { send(NewEntry(e)) })
+}
+
+// Replica applies a writer's entries to a local VectorDB. It is safe for concurrent use.
+// Nothing but the Replica should write to the DB.
+type Replica struct {
+	db *serverlessVector.VectorDB
+
+	mu      sync.Mutex
+	applied uint64
+}
+
+// NewReplica returns a Replica that applies to db the entries after seq: the writer's Seq
+// when db was loaded from its snapshot, or 0 for an empty db following the writer from its
+// start.
+func NewReplica(db *serverlessVector.VectorDB, seq uint64) *Replica {
+	return &Replica{db: db, applied: seq}
+}
+
+// Applied returns the Seq of the last entry applied.
+func (r *Replica) Applied() uint64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.applied
+}
+
+// Apply applies entries in order. Entries already applied are skipped, so redelivered
+// entries are harmless. An entry that leaves a gap after Applied is an error: the replica
+// missed changes and must be reloaded from a snapshot. Apply stops at the first error;
+// the entries before it stay applied.
+//
+// Additions and updates are applied with Add, so the replica's CreatedAt and UpdatedAt are
+// the time of replication, and time attributes shipped as JSON arrive as strings.
+func (r *Replica) Apply(entries ...Entry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, e := range entries {
+		if e.Seq <= r.applied {
+			continue
+		}
+		if e.Seq != r.applied+1 {
+			return fmt.Errorf("replication: entry %d follows %d; changes are missing", e.Seq, r.applied)
+		}
+		if err := r.apply(e); err != nil {
+			return fmt.Errorf("replication: entry %d (%s %q): %w", e.Seq, e.Op, e.ID, err)
+		}
+		r.applied = e.Seq
+	}
+	return nil
+}
+
+func (r *Replica) apply(e Entry) error {
+	switch e.Op {
+	case "add", "update":
+		if e.Metadata == nil {
+			return r.db.Add(e.ID, e.Vector)
+		}
+		return r.db.Add(e.ID, e.Vector, *e.Metadata)
+	case "delete":
+		if _, err := r.db.Get(e.ID); err != nil {
+			return nil // already gone
+		}
+		return r.db.Delete(e.ID)
+	case "clear":
+		r.db.Clear()
+		return nil
+	}
+	return fmt.Errorf("unknown op %q", e.Op)
+}
+
+// WriteEntries writes entries to w as JSON lines.
+func WriteEntries(w io.Writer, entries []Entry) error {
+	enc := json.NewEncoder(w)
+	for _, e := range entries {
+		if err := enc.Encode(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReadEntries reads the JSON lines written by WriteEntries until r ends.
+func ReadEntries(r io.Reader) ([]Entry, error) {
+	dec := json.NewDecoder(bufio.NewReader(r))
+	var entries []Entry
+	for {
+		var e Entry
+		err := dec.Decode(&e)
+		if errors.Is(err, io.EOF) {
+			return entries, nil
+		}
+		if err != nil {
+			return entries, fmt.Errorf("replication: entry %d: %w", len(entries)+1, err)
+		}
+		if e.Metadata != nil {
+			for k, v := range e.Metadata.Attributes {
+				if list, ok := v.([]any); ok { // JSON string arrays are []string attributes
+					strs := make([]string, len(list))
+					for i, x := range list {
+						strs[i], _ = x.(string)
+					}
+					e.Metadata.Attributes[k] = strs
+				}
+			}
+		}
+		entries = append(entries, e)
+	}
+}
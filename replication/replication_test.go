@@ -0,0 +1,96 @@
+package replication
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	serverlessVector "github.com/takara-ai/serverlessVector/v2"
+)
+
+func TestShipAndApply(t *testing.T) {
+	writer := serverlessVector.NewVectorDB(2)
+	var log []Entry
+	cancel := Ship(writer, func(e Entry) { log = append(log, e) })
+	defer cancel()
+
+	_ = writer.Add("a", []float32{1, 0}, serverlessVector.VectorMetadata{
+		Tags:       map[string]string{"lang": "en"},
+		Attributes: map[string]any{"labels": []string{"x", "y"}},
+	})
+	_ = writer.Add("b", []float32{0, 1})
+	_ = writer.Update("b", []float32{0.5, 0.5})
+	_ = writer.Delete("a")
+	_ = writer.Add("c", []float32{1, 1})
+
+	var buf bytes.Buffer
+	if err := WriteEntries(&buf, log); err != nil {
+		t.Fatal(err)
+	}
+	shipped, err := ReadEntries(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(shipped) != 5 || shipped[0].Op != "add" || shipped[3].Op != "delete" {
+		t.Fatalf("unexpected entries %+v", shipped)
+	}
+	if labels := shipped[0].Metadata.Attributes["labels"]; !reflect.DeepEqual(labels, []string{"x", "y"}) {
+		t.Errorf("string arrays must survive JSON, got %#v", labels)
+	}
+
+	replica := NewReplica(serverlessVector.NewVectorDB(2), 0)
+	if err := replica.Apply(shipped...); err != nil {
+		t.Fatal(err)
+	}
+	if replica.Applied() != writer.Seq() {
+		t.Errorf("applied %d, writer is at %d", replica.Applied(), writer.Seq())
+	}
+	if !reflect.DeepEqual(replica.db.IDs(), writer.IDs()) {
+		t.Errorf("replica has %v, writer has %v", replica.db.IDs(), writer.IDs())
+	}
+	if v, _ := replica.db.Get("b"); v == nil || v.Data[0] != 0.5 {
+		t.Errorf("update not applied: %+v", v)
+	}
+
+	if err := replica.Apply(shipped...); err != nil {
+		t.Errorf("redelivered entries must be skipped, got %v", err)
+	}
+	writer.Clear()
+	_ = writer.Add("d", []float32{2, 2})
+	if err := replica.Apply(NewEntry(serverlessVector.Event{Seq: 8, Op: serverlessVector.EventClear})); err == nil ||
+		!strings.Contains(err.Error(), "missing") {
+		t.Errorf("expected a gap error, got %v", err)
+	}
+	if err := replica.Apply(log[5:]...); err != nil {
+		t.Fatal(err)
+	}
+	if replica.db.Size() != 1 || replica.Applied() != 7 {
+		t.Errorf("expected clear and add to apply, got %d vectors at %d", replica.db.Size(), replica.Applied())
+	}
+}
+
+func TestReplica_FromSnapshot(t *testing.T) {
+	writer := serverlessVector.NewVectorDB(2)
+	_ = writer.Add("a", []float32{1, 0})
+	var log []Entry
+	Ship(writer, func(e Entry) { log = append(log, e) })
+
+	seq := writer.Seq()
+	_ = writer.Add("b", []float32{0, 1}) // committed while the snapshot is taken
+	snap := writer.Snapshot()
+	_ = writer.Delete("a")
+
+	db := serverlessVector.NewVectorDB(2)
+	for _, id := range snap.IDs() {
+		v, _ := snap.Get(id)
+		_ = db.Add(id, v.Data)
+	}
+	replica := NewReplica(db, seq)
+	if err := replica.Apply(log...); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(db.IDs(), []string{"b"}) {
+		t.Errorf("expected only b, got %v", db.IDs())
+	}
+}
@@ -26,17 +26,179 @@ type SearchResult = lib.SearchResult
 // SimilarityResult holds individual search result
 type SimilarityResult = lib.SimilarityResult
 
+// SearchOptions configures SearchWithOptions; nil uses defaults
+type SearchOptions = lib.SearchOptions
+
+// Boost adjusts search scores from metadata
+type Boost = lib.Boost
+
+// Options configures NewVectorDBWithOptions
+type Options = lib.Options
+
+// Decay lowers search scores of older vectors
+type Decay = lib.Decay
+
+// Explanation details how a search result was scored
+type Explanation = lib.Explanation
+
+// SearchStats summarises the work a search did
+type SearchStats = lib.SearchStats
+
+// Condition is a declarative metadata predicate for SearchOptions.Where
+type Condition = lib.Condition
+
+// FilterOp is the comparison a Condition applies
+type FilterOp = lib.FilterOp
+
 // MMROptions configures MMR search; nil uses defaults
 type MMROptions = lib.MMROptions
 
+// BatchSearchOptions configures BatchSearchWithOptions
+type BatchSearchOptions = lib.BatchSearchOptions
+
 // MMRScoreMode defines how relevance is computed in MMR
 type MMRScoreMode = lib.MMRScoreMode
 
 // MMRCandidate represents a candidate for MMR selection
 type MMRCandidate = lib.MMRCandidate
 
-// Vector type constant (float32 only, matches embedding APIs)
-const Float32 VectorType = lib.Float32
+// ParquetOptions names the ID and vector columns for Parquet import/export
+type ParquetOptions = lib.ParquetOptions
+
+// Embedder turns texts into vectors for AddText and SearchText
+type Embedder = lib.Embedder
+
+// QueryEmbedder is an Embedder that encodes search queries differently from documents
+type QueryEmbedder = lib.QueryEmbedder
+
+// EmbedderFunc adapts a function to the Embedder interface
+type EmbedderFunc = lib.EmbedderFunc
+
+// FAISSIndex holds the vectors and labels of a flat FAISS index
+type FAISSIndex = lib.FAISSIndex
+
+// Snapshot is a read-only, point-in-time copy of a VectorDB
+type Snapshot = lib.Snapshot
+
+// Event is one committed change delivered to Subscribe callbacks
+type Event = lib.Event
+
+// EventOp is the kind of change an Event records
+type EventOp = lib.EventOp
+
+// Hooks are callbacks run around mutations and searches (see SetHooks)
+type Hooks = lib.Hooks
+
+// SlowQuery is a search that took at least Options.SlowSearch
+type SlowQuery = lib.SlowQuery
+
+// SnapshotStorage holds the latest persisted copy of a VectorDB (see SaveSnapshot)
+type SnapshotStorage = lib.SnapshotStorage
+
+// FileStorage is a SnapshotStorage kept in a local file
+type FileStorage = lib.FileStorage
+
+// AutoSnapshot saves a VectorDB periodically (see StartAutoSnapshot)
+type AutoSnapshot = lib.AutoSnapshot
+
+// BulkFormat is the record format read by BulkLoad
+type BulkFormat = lib.BulkFormat
+
+// Record is one vector sent to an Ingester
+type Record = lib.Record
+
+// IngestOptions configures Ingest
+type IngestOptions = lib.IngestOptions
+
+// Ingester commits records sent on its channel in batches (see Ingest)
+type Ingester = lib.Ingester
+
+// DiffResult lists the IDs that differ between two collections (see Diff)
+type DiffResult = lib.DiffResult
+
+// ShardedVectorDB spreads vectors over several VectorDBs by consistent hashing of their IDs
+type ShardedVectorDB = lib.ShardedVectorDB
+
+// VectorStore is the API shared by VectorDB and ShardedVectorDB
+type VectorStore = lib.VectorStore
+
+// Quota limits the calls one tenant makes through a QuotaStore
+type Quota = lib.Quota
+
+// QuotaError reports the Quota limit a call exceeded
+type QuotaError = lib.QuotaError
+
+// QuotaStore enforces a Quota on a VectorStore
+type QuotaStore = lib.QuotaStore
+
+// Transform maps vectors into a smaller space on every write and query (see Options.Transform)
+type Transform = lib.Transform
+
+// PCA is a Transform onto principal components, fitted with FitPCA
+type PCA = lib.PCA
+
+// RandomProjection is a seeded Johnson-Lindenstrauss Transform that needs no training
+type RandomProjection = lib.RandomProjection
+
+// KeepPolicy chooses the vector Deduplicate keeps from each group of near-duplicates
+type KeepPolicy = lib.KeepPolicy
+
+// KNNGraph is the k-nearest-neighbour graph built with BuildKNNGraph, exportable as DOT or GraphML
+type KNNGraph = lib.KNNGraph
+
+// Plan chooses how a search finds its candidates (SearchOptions.Plan)
+type Plan = lib.Plan
+
+// StreamUpdate is a partial or final answer from SearchStream
+type StreamUpdate = lib.StreamUpdate
+
+// NumericSummary describes a numeric metadata key over the vectors Summarize selects
+type NumericSummary = lib.NumericSummary
+
+// StatsOptions selects the histograms GetStatsWithOptions adds to GetStats
+type StatsOptions = lib.StatsOptions
+
+// Histogram counts values in equal-width buckets
+type Histogram = lib.Histogram
+
+// TimeBucket counts the vectors created in one time bucket
+type TimeBucket = lib.TimeBucket
+
+// GroupStats totals the vectors sharing one value of StatsOptions.GroupBy
+type GroupStats = lib.GroupStats
+
+// Baseline summarizes a corpus's embeddings to detect drift from a model change
+type Baseline = lib.Baseline
+
+// DriftReport compares a batch of vectors with a Baseline
+type DriftReport = lib.DriftReport
+
+// HealthStatus is what Health reports for readiness probes (see the health package for HTTP handlers)
+type HealthStatus = lib.HealthStatus
+
+// AutoSnapshotStatus describes one running StartAutoSnapshot loop in a HealthStatus
+type AutoSnapshotStatus = lib.AutoSnapshotStatus
+
+// PurgeReport is the signed record of what PurgeWhere erased
+type PurgeReport = lib.PurgeReport
+
+// Aliases maps stable names to VectorDBs for blue/green index swaps
+type Aliases = lib.Aliases
+
+// IDGenerator returns a new vector ID for AddAuto
+type IDGenerator = lib.IDGenerator
+
+// ProgressFunc receives progress of a bulk operation (total is -1 while unknown)
+type ProgressFunc = lib.ProgressFunc
+
+// Vector storage types (vectors are returned as float32; []int8 and []uint8 are also accepted)
+const (
+	Float32  VectorType = lib.Float32
+	Float16  VectorType = lib.Float16
+	BFloat16 VectorType = lib.BFloat16
+	Int8     VectorType = lib.Int8
+	Uint8    VectorType = lib.Uint8
+)
 
 // Constants for distance functions
 const (
@@ -44,6 +206,25 @@ const (
 	DotProduct        DistanceFunction = lib.DotProduct
 	EuclideanDistance DistanceFunction = lib.EuclideanDistance
 	ManhattanDistance DistanceFunction = lib.ManhattanDistance
+
+	JaccardSimilarity  DistanceFunction = lib.JaccardSimilarity
+	TanimotoSimilarity DistanceFunction = lib.TanimotoSimilarity
+	PearsonCorrelation DistanceFunction = lib.PearsonCorrelation
+
+	KLDivergence          DistanceFunction = lib.KLDivergence
+	JensenShannonDistance DistanceFunction = lib.JensenShannonDistance
+	MahalanobisDistance   DistanceFunction = lib.MahalanobisDistance
+)
+
+// Constants for filter operators
+const (
+	OpEq     FilterOp = lib.OpEq
+	OpGt     FilterOp = lib.OpGt
+	OpGte    FilterOp = lib.OpGte
+	OpLt     FilterOp = lib.OpLt
+	OpLte    FilterOp = lib.OpLte
+	OpIn     FilterOp = lib.OpIn
+	OpPrefix FilterOp = lib.OpPrefix
 )
 
 // Constants for MMR score modes
@@ -53,8 +234,101 @@ const (
 	MMRScoreBlend     MMRScoreMode = lib.MMRScoreBlend
 )
 
+// Constants for change event ops
+const (
+	EventAdd    EventOp = lib.EventAdd
+	EventUpdate EventOp = lib.EventUpdate
+	EventDelete EventOp = lib.EventDelete
+	EventClear  EventOp = lib.EventClear
+)
+
+// Deduplicate keep policies
+const (
+	KeepOldest  KeepPolicy = lib.KeepOldest
+	KeepNewest  KeepPolicy = lib.KeepNewest
+	KeepFirstID KeepPolicy = lib.KeepFirstID
+)
+
+// Search plans
+const (
+	PlanAuto  Plan = lib.PlanAuto
+	PlanScan  Plan = lib.PlanScan
+	PlanIndex Plan = lib.PlanIndex
+)
+
+// Bulk load formats
+const (
+	BulkJSONL BulkFormat = lib.BulkJSONL
+	BulkCSV   BulkFormat = lib.BulkCSV
+)
+
+// IdempotencyTokens is how many of the latest AddIdempotent tokens a VectorDB remembers
+const IdempotencyTokens = lib.IdempotencyTokens
+
+// SlowQueryLogSize is how many of the latest slow searches SlowQueries keeps
+const SlowQueryLogSize = lib.SlowQueryLogSize
+
+// DefaultBinaryRescore is the Binary first-pass shortlist size per requested result
+const DefaultBinaryRescore = lib.DefaultBinaryRescore
+
+// ModelTag is the tag key naming the embedding model of a vector, checked against Options.Model
+const ModelTag = lib.ModelTag
+
+// Diff reports the IDs added, removed and changed (by data, tags and attributes) from one DB to another
+func Diff(from, to *VectorDB) *DiffResult { return lib.Diff(from, to) }
+
+// DiffSnapshots is Diff between two snapshots
+func DiffSnapshots(from, to *Snapshot) *DiffResult { return lib.DiffSnapshots(from, to) }
+
+// NewShardedVectorDB creates n shards, each configured by opts
+func NewShardedVectorDB(n int, opts *Options) *ShardedVectorDB { return lib.NewShardedVectorDB(n, opts) }
+
+// NewQuotaStore returns a QuotaStore enforcing quota on store
+func NewQuotaStore(store VectorStore, quota Quota) *QuotaStore { return lib.NewQuotaStore(store, quota) }
+
+// NewRandomProjection returns the projection from in to out dimensions drawn from seed
+func NewRandomProjection(in, out int, seed uint64) *RandomProjection {
+	return lib.NewRandomProjection(in, out, seed)
+}
+
+// JLDimension returns the projection dimension that keeps n vectors' distances within 1±eps
+func JLDimension(n int, eps float64) int { return lib.JLDimension(n, eps) }
+
+// NewUUIDv7 returns a random, time-ordered UUID (the default IDGenerator)
+func NewUUIDv7() string { return lib.NewUUIDv7() }
+
+// Eq matches metadata key equal to value
+func Eq(key string, value any) Condition { return lib.Eq(key, value) }
+
+// Gt matches metadata key greater than value
+func Gt(key string, value any) Condition { return lib.Gt(key, value) }
+
+// Gte matches metadata key greater than or equal to value
+func Gte(key string, value any) Condition { return lib.Gte(key, value) }
+
+// Lt matches metadata key less than value
+func Lt(key string, value any) Condition { return lib.Lt(key, value) }
+
+// Lte matches metadata key less than or equal to value
+func Lte(key string, value any) Condition { return lib.Lte(key, value) }
+
+// In matches metadata key equal to any of values
+func In(key string, values ...any) Condition { return lib.In(key, values...) }
+
+// Prefix matches string metadata key starting with prefix
+func Prefix(key, prefix string) Condition { return lib.Prefix(key, prefix) }
+
+// Float32ToBFloat16 converts float32 values to bfloat16 bit patterns
+func Float32ToBFloat16(v []float32) []uint16 { return lib.Float32ToBFloat16(v) }
+
+// BFloat16ToFloat32 converts bfloat16 bit patterns (e.g. raw model output) to float32
+func BFloat16ToFloat32(v []uint16) []float32 { return lib.BFloat16ToFloat32(v) }
+
+// NewVectorDBWithOptions creates a new vector database configured by opts (nil for defaults)
+func NewVectorDBWithOptions(opts *Options) *VectorDB { return lib.NewVectorDBWithOptions(opts) }
+
 // NewVectorDB creates a new vector database
-// dimension: vector dimension (e.g., 384 for OpenAI, 1536 for text-embedding-ada-002)
+// dimension: vector dimension (e.g., 384 for OpenAI, 1536 for text-embedding-ada-002), 0 to take it from the first vector
 // distanceFunc: optional distance function (defaults to CosineSimilarity if not provided)
 func NewVectorDB(dimension int, distanceFunc ...DistanceFunction) *VectorDB {
 	return lib.NewVectorDB(dimension, distanceFunc...)